@@ -12,13 +12,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rydelll/conway/internal/api"
+	"github.com/rydelll/conway/internal/postgres"
 	"github.com/rydelll/conway/pkg/database"
+	"github.com/rydelll/conway/pkg/life"
 	"github.com/rydelll/conway/pkg/logging"
 	"github.com/rydelll/conway/pkg/middleware"
 	"github.com/rydelll/conway/pkg/server"
 	"golang.org/x/sys/unix"
 )
 
+// requestTimeout is the deadline of every API request. It is shorter than
+// the write timeout of the server, so handlers that stop their work at the
+// deadline still have time to respond.
+const requestTimeout = 4 * time.Second
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), unix.SIGINT, unix.SIGTERM, unix.SIGQUIT)
 	defer cancel()
@@ -68,6 +76,7 @@ func run(ctx context.Context, args []string, getenv func(string) string, stderr
 	pgConfig.PoolMaxConnLife, _ = time.ParseDuration(getenv("DB_POOL_MAX_CONN_LIFE"))
 	pgConfig.PoolMaxConnIdle, _ = time.ParseDuration(getenv("DB_POOL_MAX_CONN_IDLE"))
 	pgConfig.PoolHealthcheck, _ = time.ParseDuration(getenv("DB_POOL_HEALTHCHECK"))
+	hashLifeMemoryMB, _ := strconv.Atoi(getenv("HASHLIFE_MAX_MEMORY_MB"))
 
 	// Logging
 	logger := logging.NewLogger(stderr, logLevel, logJSON)
@@ -87,6 +96,7 @@ func run(ctx context.Context, args []string, getenv func(string) string, stderr
 	subMux := http.NewServeMux()
 	wrapMux := middleware.Use(
 		subMux,
+		middleware.Timeout(requestTimeout),
 		middleware.Recover,
 		middleware.LogRequest,
 		middleware.Logger(logger),
@@ -94,12 +104,12 @@ func run(ctx context.Context, args []string, getenv func(string) string, stderr
 	)
 	rootMux.Handle("/api/", http.StripPrefix("/api", wrapMux))
 
-	// Hello
-	subMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		logger := logging.FromContext(r.Context())
-		logger.Info("???")
-		fmt.Fprintf(w, "Hello, World!")
-	})
+	// Games
+	games := postgres.NewGameStore(db)
+	engines := life.NewDefaultRegistry(life.WithMaxMemory(hashLifeMemoryMB << 20))
+	rules := postgres.NewRuleStore(db)
+	objects := postgres.NewObjectStore(db)
+	api.New(games, rules, objects, api.WithEngines(engines)).Register(subMux)
 
 	// Server
 	server := server.New(logger, rootMux, port)
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+	"github.com/rydelll/conway/pkg/render"
+	"golang.org/x/sys/unix"
+)
+
+// maxPatternSize is the maximum width or height of a pattern in cells.
+const maxPatternSize = 1 << 16
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), unix.SIGINT, unix.SIGTERM, unix.SIGQUIT)
+	defer cancel()
+
+	if err := run(ctx, os.Args, os.Getenv, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses arguments and environment variables, and runs the requested
+// command on patterns read from files or stdin.
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) error {
+	// Logging, which only reports warnings unless asked for more
+	logLevel := slog.LevelWarn
+	if level := getenv("LOG_LEVEL"); level != "" {
+		logLevel = logging.SlogLevel(level)
+	}
+	ctx = logging.WithLogger(ctx, logging.NewLogger(stderr, logLevel, false))
+
+	usage := func() {
+		fmt.Fprintf(stderr, "Work with Game of Life patterns\n\n")
+		fmt.Fprintf(stderr, "Usage:\n\n")
+		fmt.Fprintf(stderr, "\t%s <command> [options] [file]\n\n", args[0])
+		fmt.Fprintf(stderr, "Commands:\n\n")
+		fmt.Fprintf(stderr, "\tstep\tadvance a pattern and write the result\n")
+		fmt.Fprintf(stderr, "\tconvert\twrite a pattern in another format\n")
+		fmt.Fprintf(stderr, "\tgif\twrite generations of a pattern as an animated GIF image\n\n")
+		fmt.Fprintf(stderr, "Patterns are read in any of the formats: %s\n\n", strings.Join(life.PatternFormats(), ", "))
+	}
+	if len(args) < 2 {
+		usage()
+		return errors.New("missing command")
+	}
+
+	switch args[1] {
+	case "step":
+		return step(ctx, args[1:], stdin, stdout, stderr)
+	case "convert":
+		return convert(args[1:], stdin, stdout, stderr)
+	case "gif":
+		return animate(ctx, args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		usage()
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", args[1])
+	}
+}
+
+// step advances a pattern by a number of generations with the fastest
+// engine that supports its rule, and writes the result in the format it was
+// read in unless another is requested.
+func step(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Advance a pattern read from the file, or stdin when omitted.\n\n")
+		fmt.Fprintf(stderr, "Usage:\n\n")
+		fmt.Fprintf(stderr, "\t%s [options] [file]\n\n", args[0])
+		fmt.Fprintf(stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr)
+	}
+	var n int64
+	var rs, format string
+	fs.Int64Var(&n, "n", 1, "number of generations to advance")
+	fs.StringVar(&rs, "rule", "", "rule to run the pattern under, instead of the rule of the pattern")
+	fs.StringVar(&format, "format", "", "format to write the result in, instead of the format of the pattern")
+	fs.Parse(args[1:])
+	if n < 0 {
+		return fmt.Errorf("generations must not be negative, got %d", n)
+	}
+
+	p, read, err := readPattern(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = read
+	}
+	if rs == "" {
+		rs = p.Rule
+	}
+	rule := life.Conway
+	if rs != "" {
+		if rule, err = life.ParseRule(rs); err != nil {
+			return err
+		}
+	}
+
+	board, err := fit(p.Board, rule.Topology())
+	if err != nil {
+		return err
+	}
+	engine, err := pickEngine(rule)
+	if err != nil {
+		return err
+	}
+	b, _, err := engine.Advance(ctx, board, rule, n, maxPatternSize)
+	if err != nil {
+		return err
+	}
+	p.Board, p.Rule = b, rule.String()
+	p.Generation += n
+	return life.WritePattern(stdout, p, format)
+}
+
+// convert writes a pattern in another format.
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Convert a pattern read from the file, or stdin when omitted.\n\n")
+		fmt.Fprintf(stderr, "Usage:\n\n")
+		fmt.Fprintf(stderr, "\t%s [options] [file]\n\n", args[0])
+		fmt.Fprintf(stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr)
+	}
+	var format string
+	fs.StringVar(&format, "format", life.FormatRLE, "format to write the pattern in: "+strings.Join(life.PatternFormats(), ", "))
+	fs.Parse(args[1:])
+
+	p, _, err := readPattern(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	return life.WritePattern(stdout, p, format)
+}
+
+// animate writes generations of a pattern as the frames of an animated GIF
+// image. Without a viewport every generation is computed twice, first to
+// find the rectangle that holds them all.
+func animate(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Animate a pattern read from the file, or stdin when omitted.\n\n")
+		fmt.Fprintf(stderr, "Usage:\n\n")
+		fmt.Fprintf(stderr, "\t%s [options] [file] > pattern.gif\n\n", args[0])
+		fmt.Fprintf(stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr)
+	}
+	var from, to int64
+	var delay, loopCount, cell int
+	var rs, alive, dead, grid, age, viewport string
+	fs.Int64Var(&from, "from", 0, "first generation to draw")
+	fs.Int64Var(&to, "to", 99, "last generation to draw")
+	fs.IntVar(&delay, "delay", 10, "time each frame is shown for in hundredths of a second")
+	fs.IntVar(&loopCount, "loop", 0, "number of times to repeat the animation, 0 forever and -1 never")
+	fs.IntVar(&cell, "cell", 8, "width and height of a cell in pixels")
+	fs.StringVar(&rs, "rule", "", "rule to run the pattern under, instead of the rule of the pattern")
+	fs.StringVar(&alive, "alive", "", "color of live cells, such as #000000")
+	fs.StringVar(&dead, "dead", "", "color of dead cells, such as #ffffff")
+	fs.StringVar(&grid, "grid", "", "color of grid lines between cells, none when omitted")
+	fs.StringVar(&age, "age", "", "colors of the first and last states of a multistate rule, separated by a comma")
+	fs.StringVar(&viewport, "viewport", "", "rectangle of the plane to draw as x,y,width,height, all generations when omitted")
+	fs.Parse(args[1:])
+	if from < 0 || to < from {
+		return fmt.Errorf("generations must run forward from 0 or later, got %d to %d", from, to)
+	}
+	if delay < 0 || delay > 0xffff {
+		return fmt.Errorf("delay must be between 0 and %d, got %d", 0xffff, delay)
+	}
+	if loopCount < -1 || loopCount > 0xffff {
+		return fmt.Errorf("loop must be between -1 and %d, got %d", 0xffff, loopCount)
+	}
+	if cell < 1 || cell > render.MaxCellSize {
+		return fmt.Errorf("cell must be between 1 and %d pixels, got %d", render.MaxCellSize, cell)
+	}
+	if to-from >= render.MaxFrames {
+		return fmt.Errorf("animation must have at most %d frames, got %d", render.MaxFrames, to-from+1)
+	}
+	var view image.Rectangle
+	if viewport != "" {
+		var err error
+		if view, err = parseViewport(viewport); err != nil {
+			return err
+		}
+	}
+
+	p, _, err := readPattern(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	if rs == "" {
+		rs = p.Rule
+	}
+	rule := life.Conway
+	if rs != "" {
+		if rule, err = life.ParseRule(rs); err != nil {
+			return err
+		}
+	}
+	opts, err := renderOptions(cell, alive, dead, grid, age)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, render.WithNeighborhood(rule.Neighborhood()))
+
+	board, err := fit(p.Board, rule.Topology())
+	if err != nil {
+		return err
+	}
+	engine, err := pickEngine(rule)
+	if err != nil {
+		return err
+	}
+	if board, _, err = engine.Advance(ctx, board, rule, from, maxPatternSize); err != nil {
+		return err
+	}
+	next := stepper(board, rule)
+
+	// without a viewport the boards are kept until every generation is
+	// known, which the limits on the animation keep small
+	var boards []*life.Board
+	if viewport == "" {
+		for gen := from; ; gen++ {
+			x, y := board.Origin()
+			view = view.Union(image.Rect(x, y, x+board.Width(), y+board.Height()))
+			boards = append(boards, board)
+			bounds := render.New(append(opts, render.WithViewport(view.Min.X, view.Min.Y, view.Dx(), view.Dy()))...).Bounds(board)
+			if err := render.CheckAnimation(len(boards), bounds); err != nil {
+				return err
+			}
+			if gen == to {
+				break
+			}
+			if board, err = next(ctx); err != nil {
+				return err
+			}
+		}
+		if view.Empty() {
+			view = image.Rect(0, 0, 1, 1)
+		}
+	}
+	renderer := render.New(append(opts, render.WithViewport(view.Min.X, view.Min.Y, view.Dx(), view.Dy()))...)
+	if err := render.CheckAnimation(int(to-from+1), renderer.Bounds(board)); err != nil {
+		return err
+	}
+
+	g := renderer.NewGIF(stdout, delay, loopCount)
+	for _, b := range boards {
+		if err := g.Frame(b); err != nil {
+			return err
+		}
+	}
+	if boards == nil {
+		for gen := from; ; gen++ {
+			if err := g.Frame(board); err != nil {
+				return err
+			}
+			if gen == to {
+				break
+			}
+			if board, err = next(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return g.Close()
+}
+
+// stepper returns a function that steps the board one generation at a time,
+// keeping it bit-packed between generations when the rule allows, and
+// returns each generation. Generations that no longer fit within the largest
+// pattern fail with [life.ErrTooLarge].
+func stepper(b *life.Board, rule life.Rule) func(ctx context.Context) (*life.Board, error) {
+	p, _ := life.NewPacked(b, rule)
+	return func(ctx context.Context) (*life.Board, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if p != nil {
+			p.Step()
+			b = p.Board()
+		} else {
+			b = life.Step(b, rule)
+		}
+		if b.Width() > maxPatternSize || b.Height() > maxPatternSize {
+			return nil, fmt.Errorf("%w: %dx%d exceeds %dx%d", life.ErrTooLarge, b.Width(), b.Height(), maxPatternSize, maxPatternSize)
+		}
+		return b, nil
+	}
+}
+
+// parseViewport parses a rectangle of the plane given as x,y,width,height.
+func parseViewport(s string) (image.Rectangle, error) {
+	var v [4]int
+	fields := strings.Split(s, ",")
+	if len(fields) != len(v) {
+		return image.Rectangle{}, fmt.Errorf("viewport %q must be x,y,width,height", s)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("viewport %q must be x,y,width,height", s)
+		}
+		v[i] = n
+	}
+	if v[2] < 1 || v[3] < 1 {
+		return image.Rectangle{}, fmt.Errorf("viewport %q must be at least 1x1", s)
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
+
+// renderOptions returns the options of a renderer from the cell size and
+// colors given as flags, where empty colors are left as the defaults.
+func renderOptions(cell int, alive, dead, grid, age string) ([]render.Option, error) {
+	opts := []render.Option{render.WithCellSize(cell)}
+	if alive != "" || dead != "" {
+		colors := []color.Color{color.White, color.Black}
+		for i, s := range []string{dead, alive} {
+			if s == "" {
+				continue
+			}
+			c, err := render.ParseColor(s)
+			if err != nil {
+				return nil, err
+			}
+			colors[i] = c
+		}
+		opts = append(opts, render.WithColors(colors[0], colors[1]))
+	}
+	if grid != "" {
+		c, err := render.ParseColor(grid)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, render.WithGrid(c))
+	}
+	if age != "" {
+		first, last, ok := strings.Cut(age, ",")
+		if !ok {
+			return nil, fmt.Errorf("age %q must be two colors separated by a comma", age)
+		}
+		c0, err := render.ParseColor(first)
+		if err != nil {
+			return nil, err
+		}
+		c1, err := render.ParseColor(last)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, render.WithAgeColors(c0, c1))
+	}
+	return opts, nil
+}
+
+// readPattern reads a pattern in any format from the named file, or from
+// stdin when the name is empty or "-", returning the pattern and its format.
+func readPattern(name string, stdin io.Reader) (*life.PatternFile, string, error) {
+	if name == "" || name == "-" {
+		return life.ReadPattern(stdin, maxPatternSize)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	return life.ReadPattern(f, maxPatternSize)
+}
+
+// fit places the board at the top left of the bounded grid of the
+// topology, which must hold it. Boards on an unbounded plane are returned as
+// they are.
+func fit(b *life.Board, topo life.Topology) (*life.Board, error) {
+	if !topo.Bounded() {
+		return b, nil
+	}
+	if b.Width() > topo.Width || b.Height() > topo.Height {
+		return nil, fmt.Errorf("%dx%d pattern does not fit within the %dx%d bounded grid", b.Width(), b.Height(), topo.Width, topo.Height)
+	}
+	grid := life.NewBoard(topo.Width, topo.Height)
+	grid.SetOrigin(b.Origin())
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			grid.SetState(x, y, b.State(x, y))
+		}
+	}
+	return grid, nil
+}
+
+// pickEngine returns the fastest engine of the default registry that
+// supports the rule.
+func pickEngine(rule life.Rule) (life.Engine, error) {
+	engines := life.NewDefaultRegistry()
+	var err error
+	for _, name := range []string{life.EngineHashLife, life.EnginePacked, life.EngineNaive} {
+		e, _ := engines.Engine(name)
+		if err = e.Check(rule); err == nil {
+			return e, nil
+		}
+	}
+	return nil, err
+}
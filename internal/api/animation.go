@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+	"github.com/rydelll/conway/pkg/render"
+)
+
+const (
+	// defaultFrames is the number of frames of an animation without an end.
+	defaultFrames = 100
+	// defaultDelay is the time each frame of an animation is shown for, in
+	// hundredths of a second.
+	defaultDelay = 10
+)
+
+// animation holds the generations and timing of an animation request.
+type animation struct {
+	from, to  int64
+	delay     int
+	loopCount int
+}
+
+// getAnimation responds with generations from through to of a game drawn as
+// the frames of an animated GIF image. The generations are computed one at
+// a time and each frame is written as soon as it is drawn, so no more than
+// one frame is held in memory. Without a viewport every generation is
+// computed twice, first to find the rectangle that holds them all. Once the
+// first frame is written the status can no longer change, so a request that
+// runs out of time ends with a truncated image.
+func (h *Handler) getAnimation(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query := r.URL.Query()
+	anim, err := parseAnimation(query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	game, _, err := h.gameAt(ctx, id, anim.from)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	opts, err := h.imageOptions(ctx, game, query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if !query.Has("viewport") {
+		v, err := h.animationViewport(ctx, *game, anim.to)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		opts = append(opts, render.WithViewport(v.Min.X, v.Min.Y, v.Dx(), v.Dy()))
+	}
+	renderer := render.New(opts...)
+	bounds := renderer.Bounds(life.NewBoard(0, 0))
+	if err := render.CheckAnimation(int(anim.to-anim.from+1), bounds); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrInvalidData, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	g := renderer.NewGIF(w, anim.delay, anim.loopCount)
+	rc := http.NewResponseController(w)
+	err = h.frames(ctx, *game, anim.to, func(b *life.Board) error {
+		if err := g.Frame(b); err != nil {
+			return err
+		}
+		// writers that cannot flush still send the frame when they are full
+		rc.Flush()
+		return nil
+	})
+	if err == nil {
+		err = g.Close()
+	}
+	if err != nil {
+		logger := logging.FromContext(ctx)
+		logger.Error("failed to write animation", slog.Any("error", err))
+	}
+}
+
+// parseAnimation parses the query parameters of an animation request:
+//
+//   - from: the first generation, 0 by default
+//   - to: the last generation, 99 after the first by default
+//   - delay: the time each frame is shown for in hundredths of a second, 10
+//     by default
+//   - loop: the number of times the animation repeats after it is first
+//     shown, where 0 repeats forever and -1 shows it once, 0 by default
+func parseAnimation(query url.Values) (animation, error) {
+	anim := animation{delay: defaultDelay}
+	var err error
+	if s := query.Get("from"); s != "" {
+		if anim.from, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return animation{}, fmt.Errorf("%w: from %q must be an integer", domain.ErrInvalidData, s)
+		}
+	}
+	anim.to = anim.from + defaultFrames - 1
+	if s := query.Get("to"); s != "" {
+		if anim.to, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return animation{}, fmt.Errorf("%w: to %q must be an integer", domain.ErrInvalidData, s)
+		}
+	}
+	if anim.to < anim.from || anim.to-anim.from >= render.MaxFrames {
+		return animation{}, fmt.Errorf("%w: animation must have between 1 and %d frames", domain.ErrInvalidData, render.MaxFrames)
+	}
+	if s := query.Get("delay"); s != "" {
+		if anim.delay, err = strconv.Atoi(s); err != nil || anim.delay < 0 || anim.delay > 0xffff {
+			return animation{}, fmt.Errorf("%w: delay must be between 0 and %d hundredths of a second", domain.ErrInvalidData, 0xffff)
+		}
+	}
+	if s := query.Get("loop"); s != "" {
+		if anim.loopCount, err = strconv.Atoi(s); err != nil || anim.loopCount < -1 || anim.loopCount > 0xffff {
+			return animation{}, fmt.Errorf("%w: loop must be between -1 and %d", domain.ErrInvalidData, 0xffff)
+		}
+	}
+	return anim, nil
+}
+
+// animationViewport returns the smallest rectangle of the plane that holds
+// every generation of the game from its current generation through to.
+func (h *Handler) animationViewport(ctx context.Context, game domain.Game, to int64) (image.Rectangle, error) {
+	var viewport image.Rectangle
+	err := h.frames(ctx, game, to, func(b *life.Board) error {
+		x, y := b.Origin()
+		viewport = viewport.Union(image.Rect(x, y, x+b.Width(), y+b.Height()))
+		return nil
+	})
+	if viewport.Empty() {
+		viewport = image.Rect(0, 0, 1, 1)
+	}
+	return viewport, err
+}
+
+// frames calls fn with the board of every generation of the game from its
+// current generation through to, stepping it one generation at a time. The
+// game is a copy, so the caller's game is left at its current generation.
+func (h *Handler) frames(ctx context.Context, game domain.Game, to int64, fn func(b *life.Board) error) error {
+	for {
+		b, err := gameBoard(&game)
+		if err != nil {
+			return err
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+		if game.Generation >= to {
+			return nil
+		}
+		if _, err := h.step(ctx, &game, 1, maxStepGenerations); err != nil {
+			return err
+		}
+		game.Generation++
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"image"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetAnimation(t *testing.T) {
+	h := newTestHandler(t)
+	var glider gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+
+	cases := []struct {
+		name      string
+		query     string
+		frames    int
+		delay     int
+		loopCount int
+		bounds    image.Rectangle
+	}{
+		{
+			name:   "default",
+			query:  "",
+			frames: 100,
+			delay:  10,
+			bounds: image.Rect(0, 0, 28*8, 28*8),
+		},
+		{
+			name:      "range",
+			query:     "?from=4&to=7&delay=5&loop=2&cell=2",
+			frames:    4,
+			delay:     5,
+			loopCount: 2,
+			bounds:    image.Rect(0, 0, 8, 8),
+		},
+		{
+			name:      "once",
+			query:     "?to=0&loop=-1",
+			frames:    1,
+			delay:     10,
+			loopCount: -1,
+			bounds:    image.Rect(0, 0, 24, 24),
+		},
+		{
+			name:   "viewport",
+			query:  "?to=8&cell=1&viewport=0,0,5,5",
+			frames: 9,
+			delay:  10,
+			bounds: image.Rect(0, 0, 5, 5),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/games/"+glider.ID.String()+"/animation.gif"+tc.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "image/gif" {
+				t.Errorf("expected content type image/gif, got %s", got)
+			}
+			g, err := gif.DecodeAll(w.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(g.Image) != tc.frames {
+				t.Fatalf("expected %d frames, got %d", tc.frames, len(g.Image))
+			}
+			if g.LoopCount != tc.loopCount {
+				t.Errorf("expected loop count %d, got %d", tc.loopCount, g.LoopCount)
+			}
+			for i, img := range g.Image {
+				if g.Delay[i] != tc.delay {
+					t.Errorf("frame %d: expected delay %d, got %d", i, tc.delay, g.Delay[i])
+				}
+				if diff := cmp.Diff(tc.bounds, img.Bounds()); diff != "" {
+					t.Errorf("frame %d mismatch (-want, +got):\n%s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAnimationInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	var glider, cube gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+	do(t, h, http.MethodPost, "/games", `{"kind":"life3d","volume":{"layers":[["O"]]}}`, &cube)
+
+	cases := []struct {
+		name  string
+		query string
+		id    string
+	}{
+		{name: "from", query: "?from=x", id: glider.ID.String()},
+		{name: "backwards", query: "?from=4&to=3", id: glider.ID.String()},
+		{name: "too many frames", query: "?to=1000", id: glider.ID.String()},
+		{name: "delay", query: "?delay=-1", id: glider.ID.String()},
+		{name: "loop", query: "?loop=65536", id: glider.ID.String()},
+		{name: "cell size", query: "?cell=0", id: glider.ID.String()},
+		{name: "large frames", query: "?cell=64&viewport=0,0,64,64", id: glider.ID.String()},
+		{name: "too many pixels", query: "?to=999&cell=32&viewport=0,0,32,32", id: glider.ID.String()},
+		{name: "volume", query: "", id: cube.ID.String()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodGet, "/games/"+tc.id+"/animation.gif"+tc.query, "", nil); code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package api implements the HTTP handlers for the Game of Life service.
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// GameStore represents a persistent store of games.
+type GameStore interface {
+	// CreateGame stores a new game along with its current generation.
+	CreateGame(ctx context.Context, game *domain.Game) error
+	// GetGame retrieves a game along with its most recent generation.
+	GetGame(ctx context.Context, id uuid.UUID) (*domain.Game, error)
+	// GetGeneration retrieves a game along with its most recent generation
+	// at or before the given generation.
+	GetGeneration(ctx context.Context, id uuid.UUID, generation int64) (*domain.Game, error)
+	// ListGames retrieves every game along with its most recent generation.
+	ListGames(ctx context.Context) ([]*domain.Game, error)
+	// SaveGeneration stores a new generation for an existing game.
+	SaveGeneration(ctx context.Context, game *domain.Game) error
+	// RewindGeneration stores an earlier generation for an existing game,
+	// discarding every generation from it onward.
+	RewindGeneration(ctx context.Context, game *domain.Game) error
+	// DeleteGame removes a game and all of its generations.
+	DeleteGame(ctx context.Context, id uuid.UUID) error
+}
+
+// RuleStore represents a persistent store of custom rule files.
+type RuleStore interface {
+	// CreateRule stores a new rule file.
+	CreateRule(ctx context.Context, rule *domain.RuleFile) error
+	// GetRule retrieves a rule file by its name.
+	GetRule(ctx context.Context, name string) (*domain.RuleFile, error)
+	// ListRules retrieves every rule file.
+	ListRules(ctx context.Context) ([]*domain.RuleFile, error)
+}
+
+// ObjectStore represents a persistent store of the objects found in games.
+type ObjectStore interface {
+	// RecordObject stores an object, or counts it again when an object with
+	// the same rule and apgcode is already stored.
+	RecordObject(ctx context.Context, object *domain.Object) error
+	// GetObject retrieves an object by its rule and apgcode.
+	GetObject(ctx context.Context, rule, apgcode string) (*domain.Object, error)
+	// ListObjects retrieves the objects selected by the filter.
+	ListObjects(ctx context.Context, filter domain.ObjectFilter) ([]*domain.Object, error)
+}
+
+// Handler serves the HTTP API.
+type Handler struct {
+	games   GameStore
+	rules   RuleStore
+	objects ObjectStore
+	engines *life.Registry
+	jobs    *jobs
+}
+
+// New creates a [Handler] backed by the given stores with optional
+// configuration.
+func New(games GameStore, rules RuleStore, objects ObjectStore, opts ...Option) *Handler {
+	h := &Handler{games: games, rules: rules, objects: objects, jobs: newJobs()}
+
+	// apply optional configuration
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.engines == nil {
+		h.engines = life.NewDefaultRegistry()
+	}
+	return h
+}
+
+// Register the API routes on the given mux.
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /games", h.listGames)
+	mux.HandleFunc("POST /games", h.createGame)
+	mux.HandleFunc("GET /games/{id}", h.getGame)
+	mux.HandleFunc("DELETE /games/{id}", h.deleteGame)
+	mux.HandleFunc("POST /games/{id}/step", h.stepGame)
+	mux.HandleFunc("GET /games/{id}/generations/{n}", h.getGeneration)
+	mux.HandleFunc("GET /games/{id}/animation.gif", h.getAnimation)
+	mux.HandleFunc("GET /games/{id}/layers/{z}", h.getLayer)
+	mux.HandleFunc("GET /games/{id}/voxels", h.getVoxels)
+	mux.HandleFunc("POST /games/{id}/predecessor", h.findPredecessor)
+	mux.HandleFunc("POST /games/{id}/objects", h.recordObject)
+	mux.HandleFunc("GET /jobs", h.listJobs)
+	mux.HandleFunc("GET /jobs/{id}", h.getJob)
+	mux.HandleFunc("DELETE /jobs/{id}", h.deleteJob)
+	mux.HandleFunc("POST /soups", h.generateSoup)
+	mux.HandleFunc("GET /rules", h.listRules)
+	mux.HandleFunc("POST /rules", h.createRule)
+	mux.HandleFunc("GET /rules/{name}", h.getRule)
+	mux.HandleFunc("GET /objects", h.listObjects)
+	mux.HandleFunc("GET /objects/{apgcode}", h.getObject)
+}
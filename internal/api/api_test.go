@@ -0,0 +1,283 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+)
+
+// memGameStore is an in memory [GameStore] used for testing.
+type memGameStore struct {
+	mu    sync.Mutex
+	games map[uuid.UUID][]*domain.Game
+}
+
+func newMemGameStore() *memGameStore {
+	return &memGameStore{games: make(map[uuid.UUID][]*domain.Game)}
+}
+
+func (s *memGameStore) CreateGame(ctx context.Context, game *domain.Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.games[game.ID]; ok {
+		return domain.ErrConflict
+	}
+	game.CreatedAt = time.Now()
+	game.UpdatedAt = game.CreatedAt
+	c := *game
+	s.games[game.ID] = []*domain.Game{&c}
+	return nil
+}
+
+func (s *memGameStore) GetGame(ctx context.Context, id uuid.UUID) (*domain.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gens, ok := s.games[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *gens[len(gens)-1]
+	return &c, nil
+}
+
+func (s *memGameStore) GetGeneration(ctx context.Context, id uuid.UUID, generation int64) (*domain.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found *domain.Game
+	for _, g := range s.games[id] {
+		if g.Generation <= generation && (found == nil || g.Generation > found.Generation) {
+			found = g
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrNotFound
+	}
+	c := *found
+	return &c, nil
+}
+
+func (s *memGameStore) ListGames(ctx context.Context) ([]*domain.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	games := []*domain.Game{}
+	for _, gens := range s.games {
+		c := *gens[len(gens)-1]
+		games = append(games, &c)
+	}
+	slices.SortFunc(games, func(a, b *domain.Game) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return games, nil
+}
+
+func (s *memGameStore) SaveGeneration(ctx context.Context, game *domain.Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gens, ok := s.games[game.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	for _, g := range gens {
+		if g.Generation == game.Generation {
+			return domain.ErrConflict
+		}
+	}
+	game.UpdatedAt = time.Now()
+	c := *game
+	s.games[game.ID] = append(gens, &c)
+	return nil
+}
+
+func (s *memGameStore) RewindGeneration(ctx context.Context, game *domain.Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gens, ok := s.games[game.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	gens = slices.DeleteFunc(gens, func(g *domain.Game) bool {
+		return g.Generation >= game.Generation
+	})
+	game.UpdatedAt = time.Now()
+	c := *game
+	s.games[game.ID] = append(gens, &c)
+	return nil
+}
+
+func (s *memGameStore) DeleteGame(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.games[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.games, id)
+	return nil
+}
+
+// memRuleStore is an in memory [RuleStore] used for testing.
+type memRuleStore struct {
+	mu    sync.Mutex
+	rules map[string]*domain.RuleFile
+}
+
+func newMemRuleStore() *memRuleStore {
+	return &memRuleStore{rules: make(map[string]*domain.RuleFile)}
+}
+
+func (s *memRuleStore) CreateRule(ctx context.Context, rule *domain.RuleFile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[rule.Name]; ok {
+		return domain.ErrConflict
+	}
+	rule.CreatedAt = time.Now()
+	c := *rule
+	s.rules[rule.Name] = &c
+	return nil
+}
+
+func (s *memRuleStore) GetRule(ctx context.Context, name string) (*domain.RuleFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, ok := s.rules[name]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *rule
+	return &c, nil
+}
+
+func (s *memRuleStore) ListRules(ctx context.Context) ([]*domain.RuleFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := []*domain.RuleFile{}
+	for _, rule := range s.rules {
+		c := *rule
+		rules = append(rules, &c)
+	}
+	slices.SortFunc(rules, func(a, b *domain.RuleFile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return rules, nil
+}
+
+// memObjectStore is an in memory [ObjectStore] used for testing.
+type memObjectStore struct {
+	mu      sync.Mutex
+	objects map[[2]string]*domain.Object
+}
+
+func newMemObjectStore() *memObjectStore {
+	return &memObjectStore{objects: make(map[[2]string]*domain.Object)}
+}
+
+func (s *memObjectStore) RecordObject(ctx context.Context, object *domain.Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]string{object.Rule, object.Apgcode}
+	stored, ok := s.objects[key]
+	if !ok {
+		c := *object
+		c.Count, c.CreatedAt = 0, time.Now()
+		stored = &c
+		s.objects[key] = stored
+	}
+	stored.Count++
+	stored.UpdatedAt = time.Now()
+	*object = *stored
+	return nil
+}
+
+func (s *memObjectStore) GetObject(ctx context.Context, rule, apgcode string) (*domain.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, ok := s.objects[[2]string{rule, apgcode}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *object
+	return &c, nil
+}
+
+func (s *memObjectStore) ListObjects(ctx context.Context, filter domain.ObjectFilter) ([]*domain.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := []*domain.Object{}
+	for _, object := range s.objects {
+		if (filter.Rule == "" || object.Rule == filter.Rule) && strings.HasPrefix(object.Apgcode, filter.Prefix) {
+			c := *object
+			objects = append(objects, &c)
+		}
+	}
+	slices.SortFunc(objects, func(a, b *domain.Object) int {
+		return cmp.Or(strings.Compare(a.Rule, b.Rule), strings.Compare(a.Apgcode, b.Apgcode))
+	})
+	return objects, nil
+}
+
+// newTestHandler creates a mux with the API registered against in memory
+// stores.
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	mux := http.NewServeMux()
+	New(newMemGameStore(), newMemRuleStore(), newMemObjectStore()).Register(mux)
+	return mux
+}
+
+// do sends a request to the handler and decodes the JSON response into v
+// when v is not nil. It returns the response status code.
+func do(t *testing.T, h http.Handler, method, target, body string, v any) int {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if v != nil {
+		if err := json.UnmarshalRead(w.Result().Body, v); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return w.Code
+}
+
+func TestRegister(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		method string
+		target string
+		code   int
+	}{
+		{method: http.MethodGet, target: "/games", code: http.StatusOK},
+		{method: http.MethodPut, target: "/games", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, target: "/games/bad", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/games/" + uuid.NewString(), code: http.StatusNotFound},
+		{method: http.MethodDelete, target: "/games/" + uuid.NewString(), code: http.StatusNotFound},
+		{method: http.MethodPost, target: "/games/" + uuid.NewString() + "/step", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/rules", code: http.StatusOK},
+		{method: http.MethodGet, target: "/rules/Missing", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/objects", code: http.StatusOK},
+		{method: http.MethodGet, target: "/objects/xs4_33", code: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			if code := do(t, h, tc.method, tc.target, "", nil); code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// defaultElementaryRule is the rule of an elementary game when omitted.
+const defaultElementaryRule = "W30"
+
+// newElementaryGame creates a game of an elementary cellular automaton from
+// a request. The board is the first generation, a single row of two-state
+// cells, which becomes the first row of the spacetime diagram.
+func newElementaryGame(req createGameRequest) (*domain.Game, error) {
+	if req.Rule == "" {
+		req.Rule = defaultElementaryRule
+	}
+	rule, err := parseElementaryRule(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if req.Engine != engineAuto {
+		return nil, fmt.Errorf("%w: %s games only support engine %q", domain.ErrInvalidData, domain.KindElementary, engineAuto)
+	}
+	board, err := req.Board.row(rule)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Game{Rule: rule.String(), Engine: req.Engine, Board: board}, nil
+}
+
+// stepElementary advances the spacetime diagram of an elementary game by n
+// generations, adding a row for each. The whole diagram must fit within the
+// maximum board size.
+func stepElementary(game *domain.Game, n int64) (*life.Board, error) {
+	rule, err := parseElementaryRule(game.Rule)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(maxBoardSize - game.Board.Height())
+	if n < 1 || n > limit {
+		return nil, fmt.Errorf("%w: generations must be between 1 and %d", domain.ErrInvalidData, max(limit, 1))
+	}
+
+	b := life.StepElementary(game.Board, rule, int(n))
+	if b.Width() > maxBoardSize {
+		return nil, fmt.Errorf("%w: resulting board exceeds %dx%d", domain.ErrInvalidData, maxBoardSize, maxBoardSize)
+	}
+	return b, nil
+}
+
+// parseElementaryRule parses an elementary rulestring, reporting failures as
+// [domain.ErrInvalidRule] while keeping the detail from
+// [life.ParseElementaryRule].
+func parseElementaryRule(s string) (life.ElementaryRule, error) {
+	rule, err := life.ParseElementaryRule(s)
+	if err != nil {
+		return life.ElementaryRule{}, ruleError(err)
+	}
+	return rule, nil
+}
+
+// row converts the JSON representation into the first row of a spacetime
+// diagram for the elementary rule. The width is optional and grows the row
+// beyond the given cells. A bounded grid sets the width of the row, so the
+// cells must fit within it. The y coordinate is ignored, since each row of
+// the diagram is a generation.
+func (bj boardJSON) row(rule life.ElementaryRule) (*life.Board, error) {
+	topo := rule.Topology()
+	cells, err := life.NewBoardFromRows(bj.Cells)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	if cells.Height() > 1 || bj.Height > 1 {
+		return nil, fmt.Errorf("%w: board of an %s game must be a single row", domain.ErrInvalidData, domain.KindElementary)
+	}
+	if cells.MaxState() > 1 {
+		return nil, fmt.Errorf("%w: cell state %d is not a state of the rule", domain.ErrInvalidData, cells.MaxState())
+	}
+	width := max(bj.Width, cells.Width())
+	if topo.Bounded() {
+		if width > topo.Width {
+			return nil, fmt.Errorf("%w: board must fit within the %d cell bounded grid", domain.ErrInvalidData, topo.Width)
+		}
+		width = topo.Width
+	}
+	if width < 1 || width > maxBoardSize {
+		return nil, fmt.Errorf("%w: board must be between 1 and %d cells wide", domain.ErrInvalidData, maxBoardSize)
+	}
+
+	board := life.NewBoard(width, 1)
+	board.SetOrigin(bj.X, 0)
+	for x := 0; x < cells.Width(); x++ {
+		board.SetState(x, 0, cells.State(x, 0))
+	}
+	return board, nil
+}
@@ -0,0 +1,494 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+const (
+	// maxBoardSize is the maximum width or height of a board in cells.
+	maxBoardSize = 4096
+	// maxStepGenerations is the maximum number of generations that can be
+	// advanced in a single step request on a bounded grid.
+	maxStepGenerations = 10_000
+	// maxHashLifeGenerations is the maximum number of generations that can
+	// be advanced in a single step request on an unbounded plane, where
+	// HashLife is used.
+	maxHashLifeGenerations = 1 << 50
+	// maxJumpGenerations is the maximum number of generations a game can be
+	// advanced by to compute a generation without storing it. Jumps are
+	// bounded in practice by the request deadline.
+	maxJumpGenerations = 1 << 50
+	// maxPackedGenerations is the maximum number of generations advanced by
+	// the bit-packed engine on an unbounded plane before HashLife is used.
+	maxPackedGenerations = 1024
+	// engineAuto picks the fastest registered engine for each step of a
+	// game.
+	engineAuto = "auto"
+	// sparseCellsPerLive is the number of cells per live cell of a board
+	// below which the sparse engine is picked, as it is then faster to visit
+	// only the live cells.
+	sparseCellsPerLive = 1024
+)
+
+// boardJSON is the JSON representation of a [life.Board]. Cells are rows of
+// text where '.' is dead, 'O' is alive, and the letters from 'B' are the
+// dying states of Generations rules. The x and y coordinates place the
+// top left cell on the plane.
+type boardJSON struct {
+	X      int      `json:"x"`
+	Y      int      `json:"y"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Cells  []string `json:"cells"`
+}
+
+// gameResponse is the JSON representation of a [domain.Game].
+type gameResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Kind       string     `json:"kind"`
+	Rule       string     `json:"rule"`
+	Engine     string     `json:"engine"`
+	Generation int64      `json:"generation"`
+	Population int        `json:"population"`
+	Board      boardJSON  `json:"board,omitzero"`
+	Volume     volumeJSON `json:"volume,omitzero"`
+	Tree       treeJSON   `json:"tree,omitzero"`
+	Cycle      cycleJSON  `json:"cycle,omitzero"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// cycleJSON is the JSON representation of a [life.Cycle] found while
+// stepping a game. The generation is the first generation of the cycle, or
+// the generation the game died.
+type cycleJSON struct {
+	Fate       string `json:"fate"`
+	Generation int64  `json:"generation"`
+	Period     int64  `json:"period"`
+}
+
+// createGameRequest is the body of a request to create a game. The kind
+// defaults to life, the rule to [life.Conway], and the engine to automatic
+// when omitted. Three dimensional games are created from a volume instead of
+// a board, and games of Life-like rules may be created from a random soup
+// placed at the origin of the board. Games with a board or quadtree may
+// instead be created from a pattern file, and the kind defaults to quadtree
+// for macrocell patterns, whose tree is kept rather than the board.
+type createGameRequest struct {
+	Kind    string     `json:"kind"`
+	Rule    string     `json:"rule"`
+	Engine  string     `json:"engine"`
+	Board   boardJSON  `json:"board"`
+	Volume  volumeJSON `json:"volume"`
+	Soup    soupJSON   `json:"soup"`
+	Pattern string     `json:"pattern"`
+
+	// tree holds the cells of a macrocell pattern
+	tree *life.Quadtree
+}
+
+// stepGameRequest is the body of a request to advance a game.
+type stepGameRequest struct {
+	Generations int64 `json:"generations"`
+}
+
+// listGames responds with every game.
+func (h *Handler) listGames(w http.ResponseWriter, r *http.Request) {
+	games, err := h.games.ListGames(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := make([]gameResponse, len(games))
+	for i, game := range games {
+		resp[i] = newGameResponse(game)
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// createGame creates a game from the initial board in the request body.
+func (h *Handler) createGame(w http.ResponseWriter, r *http.Request) {
+	var req createGameRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if req.Pattern != "" {
+		if err := req.readPattern(); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	if req.Kind == "" {
+		req.Kind = domain.KindLife
+	}
+	if req.Engine == "" {
+		req.Engine = engineAuto
+	}
+
+	var game *domain.Game
+	var err error
+	switch req.Kind {
+	case domain.KindLife:
+		game, err = h.newLifeGame(r.Context(), req)
+	case domain.KindElementary:
+		game, err = newElementaryGame(req)
+	case domain.KindLife3D:
+		game, err = newLife3DGame(req)
+	case domain.KindMargolus:
+		game, err = newMargolusGame(req)
+	case domain.KindQuadtree:
+		game, err = h.newQuadtreeGame(r.Context(), req)
+	default:
+		err = fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidData, req.Kind)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	game.ID = uuid.New()
+	game.Kind = req.Kind
+	if err := h.games.CreateGame(r.Context(), game); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, newGameResponse(game))
+}
+
+// newLifeGame creates a game of a Life-like rule from a request.
+func (h *Handler) newLifeGame(ctx context.Context, req createGameRequest) (*domain.Game, error) {
+	if req.Rule == "" {
+		req.Rule = life.Conway.String()
+	}
+	rule, err := h.lifeRule(ctx, req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.validateEngine(req.Engine, rule); err != nil {
+		return nil, err
+	}
+	if req.Soup != (soupJSON{}) {
+		if len(req.Board.Cells) > 0 {
+			return nil, fmt.Errorf("%w: game must be created from either cells or a soup", domain.ErrInvalidData)
+		}
+		soup, err := req.Soup.board()
+		if err != nil {
+			return nil, err
+		}
+		req.Board.Cells = soup.Rows()
+	}
+	board, err := req.Board.board(rule)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Game{Rule: rule.String(), Engine: req.Engine, Board: board}, nil
+}
+
+// getGame responds with a single game.
+func (h *Handler) getGame(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	game, err := h.games.GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, newGameResponse(game))
+}
+
+// deleteGame deletes a single game.
+func (h *Handler) deleteGame(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.games.DeleteGame(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stepGame advances a game by the requested number of generations and
+// stores the result as its newest generation. An empty body advances the
+// game by a single generation. Games that die or repeat themselves stop
+// being stepped early, skipping ahead through the cycle, and the response
+// reports the cycle. Reversible games may be stepped backward by a negative
+// number of generations, which rewinds their history to the result.
+func (h *Handler) stepGame(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	req := stepGameRequest{Generations: 1}
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	game, err := h.games.GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	cycle, err := h.step(r.Context(), game, req.Generations, maxStepGenerations)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	start := game.Generation
+	game.Generation += req.Generations
+	if req.Generations < 0 {
+		err = h.games.RewindGeneration(r.Context(), game)
+	} else {
+		err = h.games.SaveGeneration(r.Context(), game)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := newGameResponse(game)
+	resp.Cycle = newCycleJSON(start, cycle)
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// stepLife advances the board of a game of a Life-like rule by up to limit
+// generations with the engine of the game, and reports the cycle that ended
+// the run early if there was one. Games advanced by HashLife may always be
+// advanced by up to maxHashLifeGenerations.
+func (h *Handler) stepLife(ctx context.Context, game *domain.Game, n, limit int64) (*life.Board, life.Cycle, error) {
+	rule, err := h.lifeRule(ctx, game.Rule)
+	if err != nil {
+		return nil, life.Cycle{}, err
+	}
+	if h.hashLifeable(rule) && (game.Engine == engineAuto || game.Engine == life.EngineHashLife) {
+		limit = max(limit, maxHashLifeGenerations)
+	}
+	if n < 1 || n > limit {
+		return nil, life.Cycle{}, fmt.Errorf("%w: generations must be between 1 and %d", domain.ErrInvalidData, limit)
+	}
+
+	var b *life.Board
+	var cycle life.Cycle
+	if game.Engine == engineAuto {
+		b, cycle, err = h.advance(ctx, game.Board, rule, n)
+	} else {
+		engine, _ := h.engines.Engine(game.Engine)
+		if engine == nil {
+			return nil, life.Cycle{}, fmt.Errorf("%w: unknown engine %q", domain.ErrInvalidData, game.Engine)
+		}
+		b, cycle, err = engine.Advance(ctx, game.Board, rule, n, maxBoardSize)
+	}
+	if errors.Is(err, life.ErrTooLarge) {
+		return nil, life.Cycle{}, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	return b, cycle, err
+}
+
+// advance a board by n generations with the engine picked for the board,
+// which watches every generation for extinction and cycles so the run can
+// end early. Runs on an unbounded plane that are still changing after
+// maxPackedGenerations are finished with HashLife, so they can be advanced
+// by far more generations.
+func (h *Handler) advance(ctx context.Context, b *life.Board, rule life.Rule, n int64) (*life.Board, life.Cycle, error) {
+	engine, err := h.pickEngine(b, rule)
+	if err != nil {
+		return nil, life.Cycle{}, err
+	}
+	watched := n
+	if h.hashLifeable(rule) {
+		watched = min(n, maxPackedGenerations)
+	}
+	b, cycle, err := engine.Advance(ctx, b, rule, watched, maxBoardSize)
+	if err != nil {
+		return nil, life.Cycle{}, err
+	}
+
+	switch {
+	case cycle.Found():
+		// the board is at the point of the cycle of the last watched
+		// generation, so step the rest of the way to that of the nth
+		b, _, err = engine.Advance(ctx, b, rule, (n-watched)%cycle.Period, maxBoardSize)
+	case watched < n:
+		hashLife, _ := h.engines.Engine(life.EngineHashLife)
+		b, _, err = hashLife.Advance(ctx, b, rule, n-watched, maxBoardSize)
+	}
+	if err != nil {
+		return nil, life.Cycle{}, err
+	}
+	return b, cycle, nil
+}
+
+// pickEngine picks the registered engine expected to step the board the
+// fastest. Sparse patterns on an unbounded plane store only their live
+// cells, totalistic rules are bit-packed, and anything else is stepped cell
+// by cell.
+func (h *Handler) pickEngine(b *life.Board, rule life.Rule) (life.Engine, error) {
+	names := []string{life.EnginePacked, life.EngineNaive}
+	if b.Population()*sparseCellsPerLive < b.Width()*b.Height() {
+		names = append([]string{life.EngineSparse}, names...)
+	}
+	for _, name := range names {
+		if engine, ok := h.engines.Engine(name); ok && engine.Check(rule) == nil {
+			return engine, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no engine supports rule %s", domain.ErrInvalidData, rule)
+}
+
+// hashLifeable reports whether a rule can be advanced with the registered
+// HashLife engine, which typically requires a two-state range 1 rule on an
+// unbounded plane.
+func (h *Handler) hashLifeable(rule life.Rule) bool {
+	engine, ok := h.engines.Engine(life.EngineHashLife)
+	return ok && engine.Check(rule) == nil
+}
+
+// pathID parses the game ID from the request path.
+func pathID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidID
+	}
+	return id, nil
+}
+
+// parseRule parses a rulestring, reporting failures as
+// [domain.ErrInvalidRule] while keeping the detail from [life.ParseRule].
+func parseRule(s string) (life.Rule, error) {
+	rule, err := life.ParseRule(s)
+	if err != nil {
+		return life.Rule{}, ruleError(err)
+	}
+	return rule, nil
+}
+
+// validateEngine checks that the engine is registered and can simulate the
+// rule.
+func (h *Handler) validateEngine(engine string, rule life.Rule) error {
+	if engine == engineAuto {
+		return nil
+	}
+	e, ok := h.engines.Engine(engine)
+	if !ok {
+		return fmt.Errorf("%w: unknown engine %q", domain.ErrInvalidData, engine)
+	}
+	if err := e.Check(rule); err != nil {
+		detail := strings.TrimPrefix(err.Error(), life.ErrUnsupportedRule.Error()+": ")
+		return fmt.Errorf("%w: engine %q does not support the rule: %s", domain.ErrInvalidData, engine, detail)
+	}
+	return nil
+}
+
+// newGameResponse converts a game into its JSON representation. Only one
+// of the board, volume, and tree is set, depending on the kind of game.
+func newGameResponse(game *domain.Game) gameResponse {
+	resp := gameResponse{
+		ID:         game.ID,
+		Kind:       game.Kind,
+		Rule:       game.Rule,
+		Engine:     game.Engine,
+		Generation: game.Generation,
+		CreatedAt:  game.CreatedAt,
+		UpdatedAt:  game.UpdatedAt,
+	}
+	switch {
+	case game.Volume != nil:
+		resp.Population = game.Volume.Population()
+		resp.Volume = newVolumeJSON(game.Volume)
+	case game.Tree != nil:
+		resp.Population = int(min(game.Tree.Population(), math.MaxInt))
+		resp.Tree = newTreeJSON(game.Tree)
+	default:
+		resp.Population = game.Board.Population()
+		resp.Board = newBoardJSON(game.Board)
+	}
+	return resp
+}
+
+// newCycleJSON converts a cycle found by a run that started at the given
+// generation into its JSON representation, which is empty when no cycle was
+// found.
+func newCycleJSON(start int64, cycle life.Cycle) cycleJSON {
+	if !cycle.Found() {
+		return cycleJSON{}
+	}
+	return cycleJSON{
+		Fate:       cycle.Fate.String(),
+		Generation: start + cycle.Start,
+		Period:     cycle.Period,
+	}
+}
+
+// newBoardJSON converts a board into its JSON representation.
+func newBoardJSON(b *life.Board) boardJSON {
+	x, y := b.Origin()
+	return boardJSON{
+		X:      x,
+		Y:      y,
+		Width:  b.Width(),
+		Height: b.Height(),
+		Cells:  b.Rows(),
+	}
+}
+
+// board converts the JSON representation into a [life.Board] for the rule.
+// The width and height are optional and grow the board beyond the given
+// cells. A bounded topology sets the size of the board, so the cells must fit
+// within it. Every cell must be a state of the rule.
+func (bj boardJSON) board(rule life.Rule) (*life.Board, error) {
+	return bj.grid(rule.Topology(), rule.States())
+}
+
+// grid converts the JSON representation into a board on the topology whose
+// cells have fewer than the given number of states.
+func (bj boardJSON) grid(topo life.Topology, states int) (*life.Board, error) {
+	cells, err := life.NewBoardFromRows(bj.Cells)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	if int(cells.MaxState()) >= states {
+		return nil, fmt.Errorf("%w: cell state %d is not a state of the rule", domain.ErrInvalidData, cells.MaxState())
+	}
+	width, height := max(bj.Width, cells.Width()), max(bj.Height, cells.Height())
+	if topo.Bounded() {
+		if width > topo.Width || height > topo.Height {
+			return nil, fmt.Errorf("%w: board must fit within the %dx%d bounded grid", domain.ErrInvalidData, topo.Width, topo.Height)
+		}
+		width, height = topo.Width, topo.Height
+	}
+	if width < 1 || height < 1 || width > maxBoardSize || height > maxBoardSize {
+		return nil, fmt.Errorf("%w: board must be between 1x1 and %dx%d", domain.ErrInvalidData, maxBoardSize, maxBoardSize)
+	}
+
+	board := life.NewBoard(width, height)
+	board.SetOrigin(bj.X, bj.Y)
+	for y := 0; y < cells.Height(); y++ {
+		for x := 0; x < cells.Width(); x++ {
+			board.SetState(x, y, cells.State(x, y))
+		}
+	}
+	return board, nil
+}
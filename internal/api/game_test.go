@@ -0,0 +1,703 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+func TestCreateGame(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		code int
+		rule string
+		want boardJSON
+	}{
+		{
+			name: "cells",
+			body: `{"board":{"cells":[".O.","..O","OOO"]}}`,
+			code: http.StatusCreated,
+			rule: "B3/S23",
+			want: boardJSON{Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name: "padded",
+			body: `{"board":{"width":4,"height":2,"cells":["OO"]}}`,
+			code: http.StatusCreated,
+			rule: "B3/S23",
+			want: boardJSON{Width: 4, Height: 2, Cells: []string{"OO..", "...."}},
+		},
+		{
+			name: "rule",
+			body: `{"rule":"23/36","board":{"cells":["O"]}}`,
+			code: http.StatusCreated,
+			rule: "B36/S23",
+			want: boardJSON{Width: 1, Height: 1, Cells: []string{"O"}},
+		},
+		{
+			name: "origin",
+			body: `{"board":{"x":-5,"y":7,"cells":["O"]}}`,
+			code: http.StatusCreated,
+			rule: "B3/S23",
+			want: boardJSON{X: -5, Y: 7, Width: 1, Height: 1, Cells: []string{"O"}},
+		},
+		{
+			name: "bounded",
+			body: `{"rule":"B3/S23:T3,2","board":{"cells":["O"]}}`,
+			code: http.StatusCreated,
+			rule: "B3/S23:T3,2",
+			want: boardJSON{Width: 3, Height: 2, Cells: []string{"O..", "..."}},
+		},
+		{
+			name: "generations",
+			body: `{"rule":"/2/3","board":{"cells":["OB"]}}`,
+			code: http.StatusCreated,
+			rule: "B2/S/C3",
+			want: boardJSON{Width: 2, Height: 1, Cells: []string{"OB"}},
+		},
+		{
+			name: "larger than life",
+			body: `{"rule":"r5,c0,m1,s34..58,b34..45,nm","board":{"cells":["O"]}}`,
+			code: http.StatusCreated,
+			rule: "R5,C0,M1,S34..58,B34..45,NM",
+			want: boardJSON{Width: 1, Height: 1, Cells: []string{"O"}},
+		},
+		{
+			name: "elementary",
+			body: `{"kind":"elementary","rule":"w110","board":{"x":-2,"width":3,"cells":["..O"]}}`,
+			code: http.StatusCreated,
+			rule: "W110",
+			want: boardJSON{X: -2, Width: 3, Height: 1, Cells: []string{"..O"}},
+		},
+		{
+			name: "elementary ring",
+			body: `{"kind":"elementary","rule":"W90:T5,0","board":{"cells":["O"]}}`,
+			code: http.StatusCreated,
+			rule: "W90:T5,0",
+			want: boardJSON{Width: 5, Height: 1, Cells: []string{"O...."}},
+		},
+		{name: "life3d rule", body: `{"kind":"life3d","rule":"B3/S23/C3","volume":{"layers":[["O"]]}}`, code: http.StatusBadRequest},
+		{name: "life3d empty", body: `{"kind":"life3d","volume":{}}`, code: http.StatusBadRequest},
+		{name: "life3d too large", body: `{"kind":"life3d","volume":{"width":257,"layers":[["O"]]}}`, code: http.StatusBadRequest},
+		{name: "life3d state", body: `{"kind":"life3d","volume":{"layers":[["OB"]]}}`, code: http.StatusBadRequest},
+		{name: "unknown kind", body: `{"kind":"quantum","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "elementary rows", body: `{"kind":"elementary","board":{"cells":["O","O"]}}`, code: http.StatusBadRequest},
+		{name: "elementary life rule", body: `{"kind":"elementary","rule":"B3/S23","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "elementary engine", body: `{"kind":"elementary","engine":"sparse","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "elementary outside bounded", body: `{"kind":"elementary","rule":"W90:T2,0","board":{"cells":["OOO"]}}`, code: http.StatusBadRequest},
+		{name: "invalid state", body: `{"rule":"B2/S/C3","board":{"cells":["OC"]}}`, code: http.StatusBadRequest},
+		{name: "sparse generations", body: `{"rule":"B2/S/C3","engine":"sparse","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "outside bounded", body: `{"rule":"B3/S23:T3,2","board":{"cells":["OOOO"]}}`, code: http.StatusBadRequest},
+		{name: "bounded too large", body: `{"rule":"B3/S23:T100000,2","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "unknown engine", body: `{"engine":"abacus","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "packed isotropic", body: `{"rule":"B2-a/S12","engine":"packed","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "hashlife bounded", body: `{"rule":"B3/S23:T3,2","engine":"hashlife","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "sparse bounded", body: `{"rule":"B3/S23:T3,2","engine":"sparse","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "invalid rule", body: `{"rule":"B9/S23","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "empty", body: `{"board":{}}`, code: http.StatusBadRequest},
+		{name: "invalid cells", body: `{"board":{"cells":["x"]}}`, code: http.StatusBadRequest},
+		{name: "too large", body: `{"board":{"width":100000,"height":1}}`, code: http.StatusBadRequest},
+		{name: "malformed", body: `{"board":`, code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			var got gameResponse
+			code := do(t, h, http.MethodPost, "/games", tc.body, &got)
+			if code != tc.code {
+				t.Fatalf("expected status %d, got %d", tc.code, code)
+			}
+			if code != http.StatusCreated {
+				return
+			}
+			if diff := cmp.Diff(tc.rule, got.Rule); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetListDeleteGame(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":["OO","OO"]}}`, &created)
+
+	var got gameResponse
+	if code := do(t, h, http.MethodGet, "/games/"+created.ID.String(), "", &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if diff := cmp.Diff(created, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var list []gameResponse
+	do(t, h, http.MethodGet, "/games", "", &list)
+	if diff := cmp.Diff([]gameResponse{created}, list); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if code := do(t, h, http.MethodDelete, "/games/"+created.ID.String(), "", nil); code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, code)
+	}
+	if code := do(t, h, http.MethodGet, "/games/"+created.ID.String(), "", nil); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+}
+
+func TestStepGame(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		code       int
+		generation int64
+		want       []string
+	}{
+		{name: "default", body: "", code: http.StatusOK, generation: 1, want: []string{"O", "O", "O"}},
+		{name: "even", body: `{"generations":2}`, code: http.StatusOK, generation: 2, want: []string{"OOO"}},
+		{name: "zero", body: `{"generations":0}`, code: http.StatusBadRequest},
+		{name: "hashlife", body: `{"generations":1000001}`, code: http.StatusOK, generation: 1000001, want: []string{"O", "O", "O"}},
+		{name: "too many", body: `{"generations":1125899906842625}`, code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			var created gameResponse
+			do(t, h, http.MethodPost, "/games", `{"board":{"cells":["...","OOO","..."]}}`, &created)
+
+			var got gameResponse
+			code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", tc.body, &got)
+			if code != tc.code {
+				t.Fatalf("expected status %d, got %d", tc.code, code)
+			}
+			if code != http.StatusOK {
+				return
+			}
+			if got.Generation != tc.generation {
+				t.Errorf("expected generation %d, got %d", tc.generation, got.Generation)
+			}
+			if diff := cmp.Diff(tc.want, got.Board.Cells); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepGameBounded(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"rule":"B3/S23:T3,3","board":{"cells":["...","OOO","..."]}}`, &created)
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":3}`, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if diff := cmp.Diff([]string{"...", "...", "..."}, got.Board.Cells); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1000000}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestStepGameTooLarge(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":["OOOOOOOO.OOOOO...OOO......OOOOOOO.OOOOO"]}}`, &created)
+
+	// this line grows without bound and soon outgrows the largest board
+	code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":100000}`, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestStepGameTiled(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"rule":"B3/S23:T256,256","board":{"cells":["...","OOO","..."]}}`, &created)
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1}`, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	rows := []string{got.Board.Cells[0][:3], got.Board.Cells[1][:3], got.Board.Cells[2][:3]}
+	if diff := cmp.Diff([]string{".O.", ".O.", ".O."}, rows); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestStepGameSparse(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"engine":"sparse","board":{"cells":[".O.","..O","OOO"]}}`, &created)
+	if diff := cmp.Diff("sparse", created.Engine); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":400}`, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := boardJSON{X: 100, Y: 100, Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1000000}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestStepGameEngines(t *testing.T) {
+	h := newTestHandler(t)
+	want := boardJSON{X: 10, Y: 10, Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}}
+	for _, engine := range []string{"auto", "naive", "packed", "sparse", "hashlife"} {
+		t.Run(engine, func(t *testing.T) {
+			var created gameResponse
+			body := `{"engine":"` + engine + `","board":{"cells":[".O.","..O","OOO"]}}`
+			if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+			}
+			var got gameResponse
+			if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":40}`, &got); code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+			}
+			if diff := cmp.Diff(want, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// reverseEngine is an [life.Engine] that mirrors a board instead of stepping
+// it, used to test that handlers step games with the registered engines.
+type reverseEngine struct{}
+
+func (reverseEngine) Check(r life.Rule) error {
+	return nil
+}
+
+func (reverseEngine) Advance(ctx context.Context, b *life.Board, r life.Rule, n int64, maxSize int) (*life.Board, life.Cycle, error) {
+	next := life.NewBoard(b.Width(), b.Height())
+	for y := 0; y < b.Height(); y++ {
+		for x := 0; x < b.Width(); x++ {
+			next.SetState(b.Width()-1-x, y, b.State(x, y))
+		}
+	}
+	return next, life.Cycle{}, nil
+}
+
+func TestWithEngines(t *testing.T) {
+	engines := life.NewRegistry()
+	engines.Register("reverse", reverseEngine{})
+	mux := http.NewServeMux()
+	New(newMemGameStore(), newMemRuleStore(), newMemObjectStore(), WithEngines(engines)).Register(mux)
+
+	if code := do(t, mux, http.MethodPost, "/games", `{"engine":"packed","board":{"cells":["O"]}}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+	var created gameResponse
+	do(t, mux, http.MethodPost, "/games", `{"engine":"reverse","board":{"cells":["OO."]}}`, &created)
+	var got gameResponse
+	if code := do(t, mux, http.MethodPost, "/games/"+created.ID.String()+"/step", "", &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := boardJSON{Width: 3, Height: 1, Cells: []string{".OO"}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestStepGameGenerations(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"rule":"B2/S/C3","board":{"cells":["OO"]}}`, &created)
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1}`, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := boardJSON{Y: -1, Width: 2, Height: 3, Cells: []string{"OO", "BB", "OO"}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got.Population != 6 {
+		t.Errorf("expected population 6, got %d", got.Population)
+	}
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1000000}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestStepGameLargerThanLife(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	// a range 2 rule where only the center of a full 5x5 square survives, and
+	// only the cells next to the middle of each side see ten live neighbours
+	do(t, h, http.MethodPost, "/games", `{"rule":"R2,C0,M1,S25..25,B10..10,NM","board":{"cells":["OOOOO","OOOOO","OOOOO","OOOOO","OOOOO"]}}`, &created)
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", "", &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := boardJSON{X: -1, Y: -1, Width: 7, Height: 7, Cells: []string{
+		"...O...",
+		".......",
+		".......",
+		"O..O..O",
+		".......",
+		".......",
+		"...O...",
+	}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestStepGameCycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		generations int64
+		wantBoard   boardJSON
+		wantCycle   cycleJSON
+	}{
+		{
+			name:        "blinker",
+			body:        `{"board":{"cells":["OOO"]}}`,
+			generations: 1_000_000_001,
+			wantBoard:   boardJSON{X: 1, Y: -1, Width: 1, Height: 3, Cells: []string{"O", "O", "O"}},
+			wantCycle:   cycleJSON{Fate: "oscillating", Generation: 0, Period: 2},
+		},
+		{
+			name:        "block",
+			body:        `{"rule":"B2-a/S12:T4,4","board":{"cells":["OO","OO"]}}`,
+			generations: 5,
+			wantBoard:   boardJSON{Width: 4, Height: 4, Cells: []string{"....", "....", "....", "...."}},
+			wantCycle:   cycleJSON{Fate: "extinct", Generation: 1, Period: 1},
+		},
+		{
+			name:        "still life",
+			body:        `{"engine":"sparse","board":{"cells":["OO","OO"]}}`,
+			generations: 10,
+			wantBoard:   boardJSON{Width: 2, Height: 2, Cells: []string{"OO", "OO"}},
+			wantCycle:   cycleJSON{Fate: "still", Generation: 0, Period: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			var created gameResponse
+			do(t, h, http.MethodPost, "/games", tc.body, &created)
+
+			var got gameResponse
+			body := fmt.Sprintf(`{"generations":%d}`, tc.generations)
+			if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", body, &got); code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+			}
+			if diff := cmp.Diff(tc.wantBoard, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCycle, got.Cycle); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepGameElementary(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"kind":"elementary","board":{"cells":["O"]}}`, &created)
+	if diff := cmp.Diff("elementary", created.Kind); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":3}`, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	// rule 30 grows a row of the spacetime diagram for each generation
+	want := boardJSON{X: -3, Width: 7, Height: 4, Cells: []string{"...O...", "..OOO..", ".OO..O.", "OO.OOOO"}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got.Generation != 3 {
+		t.Errorf("expected generation 3, got %d", got.Generation)
+	}
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":4093}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestGameLife3D(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	body := `{"kind":"life3d","rule":"B5/S4,5","volume":{"z":3,"depth":3,"layers":[["OO","OO"],["OO","OO"]]}}`
+	if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	want := volumeJSON{Z: 3, Width: 2, Height: 2, Depth: 3, Layers: [][]string{{"OO", "OO"}, {"OO", "OO"}, {"..", ".."}}}
+	if diff := cmp.Diff(want, created.Volume); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("4555", created.Rule); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	id := created.ID.String()
+
+	var layer boardJSON
+	if code := do(t, h, http.MethodGet, "/games/"+id+"/layers/4", "", &layer); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if diff := cmp.Diff(boardJSON{Width: 2, Height: 2, Cells: []string{"OO", "OO"}}, layer); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if code := do(t, h, http.MethodGet, "/games/"+id+"/layers/x", "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+
+	// every cell of the cube has seven neighbours, so it dies in 4555
+	var got gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+id+"/step", "", &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if got.Population != 0 {
+		t.Errorf("expected population 0, got %d", got.Population)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/games/"+id+"/voxels", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !strings.HasPrefix(w.Body.String(), "VOX ") {
+		t.Errorf("expected a .vox model, got %q", w.Body.String())
+	}
+
+	var life gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":["O"]}}`, &life)
+	if code := do(t, h, http.MethodGet, "/games/"+life.ID.String()+"/voxels", "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestGenerateSoup(t *testing.T) {
+	h := newTestHandler(t)
+	body := `{"seed":"abc","width":5,"height":5,"symmetry":"D8_1"}`
+	var got boardJSON
+	if code := do(t, h, http.MethodPost, "/soups", body, &got); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := boardJSON{Width: 5, Height: 5, Cells: []string{"OOOOO", "OO.OO", "O.O.O", "OO.OO", "OOOOO"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// an explicit density of zero is used rather than the default
+	var empty boardJSON
+	if code := do(t, h, http.MethodPost, "/soups", `{"seed":"abc","width":3,"height":2,"density":0}`, &empty); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if diff := cmp.Diff(boardJSON{Width: 3, Height: 2, Cells: []string{"...", "..."}}, empty); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var created gameResponse
+	body = `{"soup":{"seed":"abc","width":5,"height":5,"symmetry":"D8_1"},"board":{"x":-2,"y":-2}}`
+	if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	want.X, want.Y = -2, -2
+	if diff := cmp.Diff(want, created.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	for _, body := range []string{
+		`{"width":5}`,
+		`{"seed":"abc","symmetry":"C3"}`,
+		`{"seed":"abc","density":2}`,
+		`{"seed":"abc","density":-0.5}`,
+		`{"density":0}`,
+		`{"seed":"abc","width":4096,"height":4096}`,
+	} {
+		if code := do(t, h, http.MethodPost, "/soups", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, code)
+		}
+	}
+	body = `{"soup":{"seed":"abc"},"board":{"cells":["O"]}}`
+	if code := do(t, h, http.MethodPost, "/games", body, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestRuleFile(t *testing.T) {
+	const wireWorld = `@RULE WireWorld
+@TABLE
+n_states:4
+neighborhood:Moore
+symmetries:permute
+var a={0,1,2,3}
+var b={0,1,2,3}
+var c={0,1,2,3}
+var d={0,1,2,3}
+var e={0,1,2,3}
+var f={0,1,2,3}
+var g={0,1,2,3}
+var h={0,1,2,3}
+var i={0,2,3}
+var j={0,2,3}
+var k={0,2,3}
+var l={0,2,3}
+var m={0,2,3}
+var n={0,2,3}
+var o={0,2,3}
+1,a,b,c,d,e,f,g,h,2
+2,a,b,c,d,e,f,g,h,3
+3,1,i,j,k,l,m,n,o,1
+3,1,1,j,k,l,m,n,o,1
+`
+	h := newTestHandler(t)
+	body, err := json.Marshal(createRuleRequest{Source: wireWorld})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var rule ruleResponse
+	if code := do(t, h, http.MethodPost, "/rules", string(body), &rule); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	if rule.Name != "WireWorld" || rule.States != 4 {
+		t.Errorf("expected WireWorld with 4 states, got %s with %d states", rule.Name, rule.States)
+	}
+	if code := do(t, h, http.MethodPost, "/rules", string(body), nil); code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, code)
+	}
+	if code := do(t, h, http.MethodPost, "/rules", `{"source":"@RULE Broken\n@TABLE\n"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+	var rules []ruleResponse
+	do(t, h, http.MethodGet, "/rules", "", &rules)
+	if len(rules) != 1 || rules[0].Name != "WireWorld" {
+		t.Errorf("expected only WireWorld, got %v", rules)
+	}
+
+	cases := []struct {
+		name  string
+		rule  string
+		cells string
+		want  boardJSON
+	}{
+		{
+			name:  "plane",
+			rule:  "WireWorld",
+			cells: `["OCCC"]`,
+			want:  boardJSON{Width: 4, Height: 1, Cells: []string{"BOCC"}},
+		},
+		{
+			name:  "torus",
+			rule:  "WireWorld:T4,3",
+			cells: `["OCCC","....","...."]`,
+			want:  boardJSON{Width: 4, Height: 3, Cells: []string{"BOCO", "....", "...."}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created gameResponse
+			body := `{"rule":"` + tc.rule + `","board":{"cells":` + tc.cells + `}}`
+			if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+			}
+			if created.Rule != tc.rule {
+				t.Errorf("expected rule %s, got %s", tc.rule, created.Rule)
+			}
+			var got gameResponse
+			if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", "", &got); code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+			}
+			if diff := cmp.Diff(tc.want, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, rule := range []string{"Missing", "WireWorld:X"} {
+		body := `{"rule":"` + rule + `","board":{"cells":["O"]}}`
+		if code := do(t, h, http.MethodPost, "/games", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", rule, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestStepGameMargolus(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	body := `{"kind":"margolus","rule":"critters:T4,4","board":{"cells":["OO..","O...","....","...."]}}`
+	if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	if created.Rule != "Critters:T4,4" {
+		t.Errorf("expected rule Critters:T4,4, got %s", created.Rule)
+	}
+
+	var forward gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":7}`, &forward); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	var back gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":-9}`, &back); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if back.Generation != -2 {
+		t.Errorf("expected generation -2, got %d", back.Generation)
+	}
+	var again gameResponse
+	do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":2}`, &again)
+	if diff := cmp.Diff(created.Board, again.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	var got gameResponse
+	do(t, h, http.MethodGet, "/games/"+created.ID.String(), "", &got)
+	if got.Generation != 0 {
+		t.Errorf("expected generation 0, got %d", got.Generation)
+	}
+
+	var ball gameResponse
+	do(t, h, http.MethodPost, "/games", `{"kind":"margolus","board":{"cells":["O"]}}`, &ball)
+	do(t, h, http.MethodPost, "/games/"+ball.ID.String()+"/step", `{"generations":3}`, &got)
+	want := boardJSON{X: 3, Y: 3, Width: 1, Height: 1, Cells: []string{"O"}}
+	if diff := cmp.Diff(want, got.Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var sand gameResponse
+	do(t, h, http.MethodPost, "/games", `{"kind":"margolus","rule":"MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;14","board":{"cells":["O"]}}`, &sand)
+	for _, body := range []string{`{"generations":0}`, `{"generations":-1}`, `{"generations":100000}`} {
+		if code := do(t, h, http.MethodPost, "/games/"+sand.ID.String()+"/step", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, code)
+		}
+	}
+	for _, body := range []string{
+		`{"kind":"margolus","rule":"B3/S23","board":{"cells":["O"]}}`,
+		`{"kind":"margolus","rule":"Critters:T3,4","board":{"cells":["O"]}}`,
+		`{"kind":"margolus","board":{"cells":["B"]}}`,
+		`{"kind":"margolus","engine":"packed","board":{"cells":["O"]}}`,
+	} {
+		if code := do(t, h, http.MethodPost, "/games", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, code)
+		}
+	}
+}
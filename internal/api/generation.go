@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// getGeneration responds with generation n of a game, which is computed
+// from the most recent stored generation at or before it. Generations in
+// between are neither stored nor sent, and the result is written as it is
+// encoded. Games advanced by HashLife jump ahead by doubling the step size,
+// other games are stepped one generation after another until the request
+// deadline. A generation ending in the name of a pattern file format, such
+// as 100.rle or 100.cells, is written in that format rather than JSON, and
+// one ending in .png is drawn as an image.
+func (h *Handler) getGeneration(w http.ResponseWriter, r *http.Request) {
+	_, format, _ := strings.Cut(r.PathValue("n"), ".")
+	if format != "" && format != formatPNG && !slices.Contains(life.PatternFormats(), format) {
+		writeError(w, r, fmt.Errorf("%w: unknown format %q", domain.ErrNotFound, format))
+		return
+	}
+	game, cycle, err := h.generation(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if format == formatPNG {
+		h.writePNG(w, r, game)
+		return
+	}
+	if format != "" {
+		writePattern(w, r, game, format)
+		return
+	}
+	resp := newGameResponse(game)
+	resp.Cycle = cycle
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// generation computes the generation of a game requested by the path, along
+// with the cycle that let the computation skip ahead, if there was one.
+func (h *Handler) generation(r *http.Request) (*domain.Game, cycleJSON, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return nil, cycleJSON{}, err
+	}
+	gen, _, _ := strings.Cut(r.PathValue("n"), ".")
+	n, err := strconv.ParseInt(gen, 10, 64)
+	if err != nil {
+		return nil, cycleJSON{}, fmt.Errorf("%w: generation %q must be an integer", domain.ErrInvalidData, gen)
+	}
+	return h.gameAt(r.Context(), id, n)
+}
+
+// gameAt computes generation n of a game from the most recent stored
+// generation at or before it, along with the cycle that let the computation
+// skip ahead, if there was one.
+func (h *Handler) gameAt(ctx context.Context, id uuid.UUID, n int64) (*domain.Game, cycleJSON, error) {
+	game, err := h.games.GetGeneration(ctx, id, n)
+	if errors.Is(err, domain.ErrNotFound) {
+		// generations before the first stored generation can only be
+		// reached by stepping a reversible game backward
+		game, err = h.games.GetGame(ctx, id)
+		if err == nil && game.Kind != domain.KindMargolus {
+			err = fmt.Errorf("%w: generation %d precedes the first generation of game %s", domain.ErrNotFound, n, id)
+		}
+	}
+	if err != nil {
+		return nil, cycleJSON{}, err
+	}
+
+	start, delta := game.Generation, n-game.Generation
+	if delta == 0 {
+		return game, cycleJSON{}, nil
+	}
+	cycle, err := h.step(ctx, game, delta, maxJumpGenerations)
+	if err != nil {
+		return nil, cycleJSON{}, err
+	}
+	game.Generation = n
+	return game, newCycleJSON(start, cycle), nil
+}
+
+// step advances the cells of a game by n generations with the stepper of
+// its kind, and reports the cycle that ended the run early if there was
+// one. Games of kinds that are not advanced by HashLife may be advanced by
+// up to limit generations. The generation of the game is left to the
+// caller.
+func (h *Handler) step(ctx context.Context, game *domain.Game, n, limit int64) (life.Cycle, error) {
+	var cycle life.Cycle
+	var err error
+	switch game.Kind {
+	case domain.KindElementary:
+		game.Board, err = stepElementary(game, n)
+	case domain.KindLife3D:
+		game.Volume, cycle, err = stepLife3D(ctx, game, n, limit)
+	case domain.KindMargolus:
+		game.Board, err = stepMargolus(ctx, game, n, limit)
+	case domain.KindQuadtree:
+		game.Tree, err = h.stepQuadtree(ctx, game, n)
+	default:
+		game.Board, cycle, err = h.stepLife(ctx, game, n, limit)
+	}
+	return cycle, err
+}
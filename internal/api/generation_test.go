@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetGeneration(t *testing.T) {
+	h := newTestHandler(t)
+	var glider, blinker, bbm gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+	do(t, h, http.MethodPost, "/games", `{"rule":"B3/S23:T5,5","board":{"cells":[".....","OOO.."]}}`, &blinker)
+	do(t, h, http.MethodPost, "/games", `{"kind":"margolus","rule":"BBM","board":{"cells":["O"]}}`, &bbm)
+	do(t, h, http.MethodPost, "/games/"+glider.ID.String()+"/step", `{"generations":8}`, nil)
+
+	cases := []struct {
+		name       string
+		target     string
+		generation int64
+		board      boardJSON
+		cycle      cycleJSON
+	}{
+		{
+			name:       "hashlife",
+			target:     "/games/" + glider.ID.String() + "/generations/1000000",
+			generation: 1_000_000,
+			board:      boardJSON{X: 250_000, Y: 250_000, Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name:       "before the latest",
+			target:     "/games/" + glider.ID.String() + "/generations/4",
+			generation: 4,
+			board:      boardJSON{X: 1, Y: 1, Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name:       "stored",
+			target:     "/games/" + glider.ID.String() + "/generations/8",
+			generation: 8,
+			board:      boardJSON{X: 2, Y: 2, Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name:       "cycle",
+			target:     "/games/" + blinker.ID.String() + "/generations/100001",
+			generation: 100_001,
+			board:      boardJSON{Width: 5, Height: 5, Cells: []string{".O...", ".O...", ".O...", ".....", "....."}},
+			cycle:      cycleJSON{Fate: "oscillating", Generation: 0, Period: 2},
+		},
+		{
+			name:       "backward",
+			target:     "/games/" + bbm.ID.String() + "/generations/-2",
+			generation: -2,
+			board:      boardJSON{X: -2, Y: -2, Width: 1, Height: 1, Cells: []string{"O"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got gameResponse
+			if code := do(t, h, http.MethodGet, tc.target, "", &got); code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+			}
+			if got.Generation != tc.generation {
+				t.Errorf("expected generation %d, got %d", tc.generation, got.Generation)
+			}
+			if diff := cmp.Diff(tc.board, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.cycle, got.Cycle); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	// computed generations are not stored
+	var got gameResponse
+	do(t, h, http.MethodGet, "/games/"+glider.ID.String(), "", &got)
+	if got.Generation != 8 {
+		t.Errorf("expected generation 8, got %d", got.Generation)
+	}
+
+	for target, code := range map[string]int{
+		"/games/" + glider.ID.String() + "/generations/-1":  http.StatusNotFound,
+		"/games/" + glider.ID.String() + "/generations/one": http.StatusBadRequest,
+		"/games/bad/generations/1":                          http.StatusBadRequest,
+	} {
+		if got := do(t, h, http.MethodGet, target, "", nil); got != code {
+			t.Errorf("%s: expected status %d, got %d", target, code, got)
+		}
+	}
+}
+
+func TestGetGenerationDeadline(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	do(t, h, http.MethodPost, "/games", `{"rule":"B36/S23:T64,64","soup":{"seed":"deadline","width":64,"height":64}}`, &created)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest(http.MethodGet, "/games/"+created.ID.String()+"/generations/1000000000", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r.WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+	"github.com/rydelll/conway/pkg/render"
+)
+
+const (
+	// formatPNG is the extension of a generation drawn as a PNG image.
+	formatPNG = "png"
+	// maxCellPixels is the largest width and height of a cell in an image.
+	maxCellPixels = render.MaxCellSize
+	// maxImagePixels is the largest number of pixels in an image.
+	maxImagePixels = 1 << 24
+)
+
+// writePNG writes the board or tree of a game as the response in a PNG
+// image, drawn with the options in the query of the request. The image is
+// encoded to memory first, so an image too large to draw is reported as an
+// error rather than a partial response.
+func (h *Handler) writePNG(w http.ResponseWriter, r *http.Request, game *domain.Game) {
+	b, err := gameBoard(game)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	opts, err := h.imageOptions(r.Context(), game, r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	renderer := render.New(opts...)
+	if bounds := renderer.Bounds(b); bounds.Dx()*bounds.Dy() > maxImagePixels {
+		writeError(w, r, fmt.Errorf("%w: image of %dx%d pixels must have at most %d pixels", domain.ErrInvalidData, bounds.Dx(), bounds.Dy(), maxImagePixels))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.PNG(&buf, b); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if _, err := buf.WriteTo(w); err != nil {
+		logger := logging.FromContext(r.Context())
+		logger.Error("failed to write response", slog.Any("error", err))
+	}
+}
+
+// gameBoard returns the board of a game to draw, expanding its tree if it
+// has one.
+func gameBoard(game *domain.Game) (*life.Board, error) {
+	if game.Tree != nil {
+		b, err := game.Tree.Board(maxBoardSize)
+		if errors.Is(err, life.ErrTooLarge) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+		}
+		return b, err
+	}
+	if game.Board == nil {
+		return nil, fmt.Errorf("%w: %s games cannot be drawn as an image", domain.ErrInvalidData, game.Kind)
+	}
+	return game.Board, nil
+}
+
+// imageOptions returns the options of a renderer for the game configured by
+// the query parameters of an image request:
+//
+//   - cell: the width and height of each cell in pixels, 8 by default
+//   - grid: the color of lines drawn between cells, with none by default
+//   - alive and dead: the colors of live and dead cells
+//   - age: the first and last colors dying cells fade between, separated by
+//     a comma
+//   - viewport: the rectangle of the plane drawn as x,y,width,height, which
+//     is the whole board by default
+//
+// Colors are hexadecimal, such as ff8000 or #ff8000 with the '#' escaped.
+// Boards of hexagonal rules are drawn as a hexagonal grid.
+func (h *Handler) imageOptions(ctx context.Context, game *domain.Game, query url.Values) ([]render.Option, error) {
+	opts, err := parseImageOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	if game.Kind == domain.KindLife {
+		rule, err := h.lifeRule(ctx, game.Rule)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, render.WithNeighborhood(rule.Neighborhood()))
+	}
+	return opts, nil
+}
+
+// parseImageOptions parses the query parameters of an image request
+// described by [Handler.imageOptions] into options of a renderer.
+func parseImageOptions(query url.Values) ([]render.Option, error) {
+	var opts []render.Option
+	if s := query.Get("cell"); s != "" {
+		pixels, err := strconv.Atoi(s)
+		if err != nil || pixels < 1 || pixels > maxCellPixels {
+			return nil, fmt.Errorf("%w: cell must be between 1 and %d pixels", domain.ErrInvalidData, maxCellPixels)
+		}
+		opts = append(opts, render.WithCellSize(pixels))
+	}
+	if s := query.Get("grid"); s != "" {
+		c, err := parseColor(s)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, render.WithGrid(c))
+	}
+	if query.Has("alive") || query.Has("dead") {
+		dead, alive := color.Color(color.White), color.Color(color.Black)
+		var err error
+		if s := query.Get("dead"); s != "" {
+			if dead, err = parseColor(s); err != nil {
+				return nil, err
+			}
+		}
+		if s := query.Get("alive"); s != "" {
+			if alive, err = parseColor(s); err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, render.WithColors(dead, alive))
+	}
+	if s := query.Get("age"); s != "" {
+		first, last, ok := strings.Cut(s, ",")
+		if !ok {
+			return nil, fmt.Errorf("%w: age must be two colors separated by a comma", domain.ErrInvalidData)
+		}
+		c0, err := parseColor(first)
+		if err != nil {
+			return nil, err
+		}
+		c1, err := parseColor(last)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, render.WithAgeColors(c0, c1))
+	}
+	if s := query.Get("viewport"); s != "" {
+		var v [4]int
+		fields := strings.Split(s, ",")
+		if len(fields) != len(v) {
+			return nil, fmt.Errorf("%w: viewport must be x,y,width,height", domain.ErrInvalidData)
+		}
+		for i, field := range fields {
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: viewport must be x,y,width,height", domain.ErrInvalidData)
+			}
+			v[i] = n
+		}
+		if v[2] < 1 || v[3] < 1 || v[2] > maxBoardSize || v[3] > maxBoardSize {
+			return nil, fmt.Errorf("%w: viewport must be between 1x1 and %dx%d", domain.ErrInvalidData, maxBoardSize, maxBoardSize)
+		}
+		opts = append(opts, render.WithViewport(v[0], v[1], v[2], v[3]))
+	}
+	return opts, nil
+}
+
+// parseColor parses a color with [render.ParseColor], reporting failures as
+// [domain.ErrInvalidData].
+func parseColor(s string) (color.Color, error) {
+	c, err := render.ParseColor(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	return c, nil
+}
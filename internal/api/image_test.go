@@ -0,0 +1,120 @@
+package api
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetGenerationPNG(t *testing.T) {
+	h := newTestHandler(t)
+	var glider, hex gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+	do(t, h, http.MethodPost, "/games", `{"rule":"B2/S34H","board":{"cells":["O","O"]}}`, &hex)
+
+	cases := []struct {
+		name   string
+		target string
+		bounds image.Rectangle
+		pixels map[image.Point]color.Color
+	}{
+		{
+			name:   "default",
+			target: "/games/" + glider.ID.String() + "/generations/4.png",
+			bounds: image.Rect(0, 0, 24, 24),
+			pixels: map[image.Point]color.Color{{0, 0}: color.White, {8, 0}: color.Black, {23, 23}: color.Black},
+		},
+		{
+			name:   "cell size",
+			target: "/games/" + glider.ID.String() + "/generations/0.png?cell=2",
+			bounds: image.Rect(0, 0, 6, 6),
+			pixels: map[image.Point]color.Color{{2, 0}: color.Black, {0, 0}: color.White},
+		},
+		{
+			name:   "grid and colors",
+			target: "/games/" + glider.ID.String() + "/generations/0.png?cell=4&grid=808080&alive=%23ff0000&dead=000",
+			bounds: image.Rect(0, 0, 13, 13),
+			pixels: map[image.Point]color.Color{
+				{0, 0}:  color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+				{1, 1}:  color.RGBA{A: 0xff},
+				{5, 1}:  color.RGBA{R: 0xff, A: 0xff},
+				{12, 5}: color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+			},
+		},
+		{
+			name:   "viewport",
+			target: "/games/" + glider.ID.String() + "/generations/0.png?cell=1&viewport=-1,-1,3,2",
+			bounds: image.Rect(0, 0, 3, 2),
+			pixels: map[image.Point]color.Color{{0, 0}: color.White, {2, 1}: color.Black},
+		},
+		{
+			name:   "hexagonal",
+			target: "/games/" + hex.ID.String() + "/generations/0.png?cell=2",
+			bounds: image.Rect(0, 0, 3, 4),
+			pixels: map[image.Point]color.Color{{0, 0}: color.White, {1, 0}: color.Black, {0, 2}: color.Black},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "image/png" {
+				t.Errorf("expected content type image/png, got %s", got)
+			}
+			img, err := png.Decode(w.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.bounds, img.Bounds()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			for p, want := range tc.pixels {
+				wr, wg, wb, _ := want.RGBA()
+				gr, gg, gb, _ := img.At(p.X, p.Y).RGBA()
+				if wr != gr || wg != gg || wb != gb {
+					t.Errorf("pixel %d, %d mismatch: expected %v, got %v", p.X, p.Y, want, img.At(p.X, p.Y))
+				}
+			}
+		})
+	}
+}
+
+func TestGetGenerationPNGInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	var glider, cube gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+	do(t, h, http.MethodPost, "/games", `{"kind":"life3d","volume":{"layers":[["O"]]}}`, &cube)
+
+	cases := []struct {
+		name  string
+		query string
+		id    string
+	}{
+		{name: "cell size", query: "?cell=0", id: glider.ID.String()},
+		{name: "large cells", query: "?cell=65", id: glider.ID.String()},
+		{name: "color", query: "?alive=red", id: glider.ID.String()},
+		{name: "age", query: "?age=fff", id: glider.ID.String()},
+		{name: "viewport", query: "?viewport=0,0,4", id: glider.ID.String()},
+		{name: "empty viewport", query: "?viewport=0,0,0,4", id: glider.ID.String()},
+		{name: "too many pixels", query: "?cell=64&viewport=0,0,4096,4096", id: glider.ID.String()},
+		{name: "volume", query: "", id: cube.ID.String()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodGet, "/games/"+tc.id+"/generations/0.png"+tc.query, "", nil); code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+// Statuses of a background job.
+const (
+	jobRunning      = "running"
+	jobFound        = "found"
+	jobGardenOfEden = "garden-of-eden"
+	jobCancelled    = "cancelled"
+	jobFailed       = "failed"
+)
+
+const (
+	// maxPredecessorRegion is the maximum number of cells in the region
+	// searched for a predecessor through the API, far fewer than
+	// [life.Predecessor] allows.
+	maxPredecessorRegion = 64 * 64
+	// maxJobDuration is the maximum time a background job runs for before
+	// it is cancelled.
+	maxJobDuration = 10 * time.Minute
+	// jobLogInterval is the minimum time between progress logs of a
+	// background job.
+	jobLogInterval = 5 * time.Second
+	// maxRunningJobs is the maximum number of background jobs running at
+	// once.
+	maxRunningJobs = 4
+	// jobTTL is how long a finished background job is kept for.
+	jobTTL = time.Hour
+)
+
+// job is a predecessor search running in the background. It is safe for
+// concurrent use.
+type job struct {
+	id        uuid.UUID
+	gameID    uuid.UUID
+	cancel    context.CancelFunc
+	createdAt time.Time
+
+	mu       sync.Mutex
+	status   string
+	progress life.PredecessorProgress
+	parent   *life.Board
+	err      string
+	doneAt   time.Time
+}
+
+// jobs holds the background jobs of a handler in memory, limiting how many
+// run at once and forgetting those that finished long ago. It is safe for
+// concurrent use.
+type jobs struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*job
+	// running counts the jobs whose searches have not returned, including
+	// those removed while they were cancelled
+	running int
+}
+
+// predecessorRequest is the JSON body used to start a predecessor search.
+// The region is the width by height rectangle with its top left corner at
+// x, y. It defaults to the live cells of the board grown by the margin, which
+// defaults to 1, when its size is omitted.
+type predecessorRequest struct {
+	Margin *int `json:"margin"`
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Width  int  `json:"width"`
+	Height int  `json:"height"`
+}
+
+// jobResponse is the JSON representation of a background job. The parent is
+// only set once a predecessor is found.
+type jobResponse struct {
+	ID        uuid.UUID    `json:"id"`
+	GameID    uuid.UUID    `json:"gameId"`
+	Status    string       `json:"status"`
+	Progress  progressJSON `json:"progress"`
+	Parent    boardJSON    `json:"parent,omitzero"`
+	Error     string       `json:"error,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+	DoneAt    time.Time    `json:"doneAt,omitzero"`
+}
+
+// progressJSON is the JSON representation of the progress of a predecessor
+// search.
+type progressJSON struct {
+	Nodes    int64   `json:"nodes"`
+	Depth    int     `json:"depth"`
+	Cells    int     `json:"cells"`
+	Explored float64 `json:"explored"`
+}
+
+// findPredecessor starts a background job that searches for a parent of the
+// most recent generation of a game, and responds with the job. The job is
+// polled with getJob and cancelled with deleteJob.
+func (h *Handler) findPredecessor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var req predecessorRequest
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &req); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	game, err := h.games.GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rule, opts, err := h.predecessorSearch(r.Context(), game, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// the job outlives the request, but keeps its logger
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), maxJobDuration)
+	j := &job{
+		id:        uuid.New(),
+		gameID:    game.ID,
+		cancel:    cancel,
+		createdAt: time.Now(),
+		status:    jobRunning,
+	}
+	if err := h.jobs.add(j); err != nil {
+		cancel()
+		writeError(w, r, err)
+		return
+	}
+	go func() {
+		defer h.jobs.done()
+		j.run(ctx, game.Board, rule, opts)
+	}()
+	writeJSON(w, r, http.StatusAccepted, j.response())
+}
+
+// predecessorSearch validates a predecessor search of a game, returning its
+// rule and the options of the search.
+func (h *Handler) predecessorSearch(ctx context.Context, game *domain.Game, req predecessorRequest) (life.Rule, []life.PredecessorOption, error) {
+	if game.Kind != domain.KindLife {
+		return life.Rule{}, nil, fmt.Errorf("%w: predecessors can only be searched for %s games", domain.ErrInvalidData, domain.KindLife)
+	}
+	rule, err := h.lifeRule(ctx, game.Rule)
+	if err != nil {
+		return life.Rule{}, nil, err
+	}
+	if err := life.SparseEngine().Check(rule); err != nil {
+		return life.Rule{}, nil, fmt.Errorf("%w: predecessors can only be searched for two-state range 1 rules on an unbounded plane", domain.ErrInvalidData)
+	}
+
+	width, height := req.Width, req.Height
+	if width > maxPredecessorRegion || height > maxPredecessorRegion {
+		return life.Rule{}, nil, fmt.Errorf("%w: region must have between 1 and %d cells", domain.ErrInvalidData, maxPredecessorRegion)
+	}
+	opts := []life.PredecessorOption{life.WithRegion(req.X, req.Y, width, height)}
+	if width == 0 && height == 0 {
+		margin := 1
+		if req.Margin != nil {
+			margin = *req.Margin
+		}
+		if margin < 0 || margin > maxPredecessorRegion {
+			return life.Rule{}, nil, fmt.Errorf("%w: margin must be between 0 and %d", domain.ErrInvalidData, maxPredecessorRegion)
+		}
+		width, height = game.Board.Width()+2*margin, game.Board.Height()+2*margin
+		opts = []life.PredecessorOption{life.WithMargin(margin)}
+	}
+	if width < 1 || height < 1 || width*height > maxPredecessorRegion {
+		return life.Rule{}, nil, fmt.Errorf("%w: region must have between 1 and %d cells", domain.ErrInvalidData, maxPredecessorRegion)
+	}
+	return rule, opts, nil
+}
+
+// run the predecessor search of the job, logging its progress.
+func (j *job) run(ctx context.Context, b *life.Board, rule life.Rule, opts []life.PredecessorOption) {
+	defer j.cancel()
+	logger := logging.FromContext(ctx).With(slog.String("job", j.id.String()), slog.String("game", j.gameID.String()))
+	logger.Info("predecessor search started")
+
+	// the job runs outside of any handler, so a panic would end the server
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("predecessor search panic", slog.Any("panic", p))
+			j.mu.Lock()
+			defer j.mu.Unlock()
+			j.status, j.err, j.doneAt = jobFailed, domain.ErrInternal.Error(), time.Now()
+		}
+	}()
+
+	logged := time.Now()
+	opts = append(opts, life.WithProgress(func(p life.PredecessorProgress) {
+		j.mu.Lock()
+		j.progress = p
+		j.mu.Unlock()
+		if time.Since(logged) >= jobLogInterval {
+			logged = time.Now()
+			logger.Info("predecessor search progress",
+				slog.Int64("nodes", p.Nodes), slog.Int("depth", p.Depth), slog.Float64("explored", p.Explored))
+		}
+	}))
+	parent, err := life.Predecessor(ctx, b, rule, opts...)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.doneAt = time.Now()
+	switch {
+	case err == nil:
+		j.status, j.parent = jobFound, parent
+	case errors.Is(err, life.ErrGardenOfEden):
+		j.status = jobGardenOfEden
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		j.status = jobCancelled
+	default:
+		j.status, j.err = jobFailed, domain.ErrInternal.Error()
+		logger.Error("predecessor search failed", slog.Any("error", err))
+		return
+	}
+	logger.Info("predecessor search finished", slog.String("status", j.status), slog.Int64("nodes", j.progress.Nodes))
+}
+
+// response returns the JSON representation of the job.
+func (j *job) response() jobResponse {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	resp := jobResponse{
+		ID:     j.id,
+		GameID: j.gameID,
+		Status: j.status,
+		Progress: progressJSON{
+			Nodes:    j.progress.Nodes,
+			Depth:    j.progress.Depth,
+			Cells:    j.progress.Cells,
+			Explored: j.progress.Explored,
+		},
+		Error:     j.err,
+		CreatedAt: j.createdAt,
+		DoneAt:    j.doneAt,
+	}
+	if j.parent != nil {
+		resp.Parent = newBoardJSON(j.parent)
+	}
+	return resp
+}
+
+// listJobs responds with every background job, from newest to oldest.
+func (h *Handler) listJobs(w http.ResponseWriter, r *http.Request) {
+	all := h.jobs.list()
+	resp := make([]jobResponse, len(all))
+	for i, j := range all {
+		resp[i] = j.response()
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// getJob responds with a single background job.
+func (h *Handler) getJob(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	j, err := h.jobs.get(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, j.response())
+}
+
+// deleteJob cancels a background job if it is still running and removes
+// it.
+func (h *Handler) deleteJob(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	j, err := h.jobs.remove(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	j.cancel()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newJobs creates an empty set of jobs.
+func newJobs() *jobs {
+	return &jobs{jobs: make(map[uuid.UUID]*job)}
+}
+
+// add a running job, failing with [domain.ErrUnavailable] when too many
+// jobs are already running. Every job added must call done once its search
+// returns.
+func (js *jobs) add(j *job) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.expire()
+	if js.running >= maxRunningJobs {
+		return fmt.Errorf("%w: %d jobs are already running", domain.ErrUnavailable, js.running)
+	}
+	js.running++
+	js.jobs[j.id] = j
+	return nil
+}
+
+// done marks the search of a job as returned, so another can run.
+func (js *jobs) done() {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.running--
+}
+
+// expire removes the jobs that finished more than the time to live ago. The
+// caller must hold the lock.
+func (js *jobs) expire() {
+	for id, j := range js.jobs {
+		j.mu.Lock()
+		expired := j.status != jobRunning && time.Since(j.doneAt) > jobTTL
+		j.mu.Unlock()
+		if expired {
+			delete(js.jobs, id)
+		}
+	}
+}
+
+// get a job by its ID.
+func (js *jobs) get(id uuid.UUID) (*job, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.expire()
+	j, ok := js.jobs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return j, nil
+}
+
+// remove a job by its ID and return it.
+func (js *jobs) remove(id uuid.UUID) (*job, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	j, ok := js.jobs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delete(js.jobs, id)
+	return j, nil
+}
+
+// list every job from newest to oldest.
+func (js *jobs) list() []*job {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.expire()
+	all := make([]*job, 0, len(js.jobs))
+	for _, j := range js.jobs {
+		all = append(all, j)
+	}
+	slices.SortFunc(all, func(a, b *job) int {
+		return b.createdAt.Compare(a.createdAt)
+	})
+	return all
+}
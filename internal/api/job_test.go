@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// waitJob polls a job until it is no longer running.
+func waitJob(t *testing.T, h http.Handler, id uuid.UUID) jobResponse {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var j jobResponse
+		if code := do(t, h, http.MethodGet, "/jobs/"+id.String(), "", &j); code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+		}
+		if j.Status != jobRunning {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is still running", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFindPredecessor(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		name   string
+		cells  string
+		body   string
+		status string
+	}{
+		{name: "blinker", cells: `["O","O","O"]`, status: jobFound},
+		{name: "margin", cells: `[".O.","..O","OOO"]`, body: `{"margin":2}`, status: jobFound},
+		{name: "garden of eden", cells: `["OO","OO"]`, body: `{"x":0,"y":0,"width":1,"height":1}`, status: jobGardenOfEden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created gameResponse
+			do(t, h, http.MethodPost, "/games", `{"board":{"cells":`+tc.cells+`}}`, &created)
+			var started jobResponse
+			if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/predecessor", tc.body, &started); code != http.StatusAccepted {
+				t.Fatalf("expected status %d, got %d", http.StatusAccepted, code)
+			}
+			if started.GameID != created.ID {
+				t.Errorf("expected game %s, got %s", created.ID, started.GameID)
+			}
+
+			j := waitJob(t, h, started.ID)
+			if j.Status != tc.status {
+				t.Fatalf("expected status %s, got %s", tc.status, j.Status)
+			}
+			if j.Status != jobFound {
+				return
+			}
+			parent, err := life.NewBoardFromRows(j.Parent.Cells)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			parent.SetOrigin(j.Parent.X, j.Parent.Y)
+			want, _ := created.Board.board(life.Conway)
+			if got := life.Step(parent, life.Conway); !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+
+	var jobs []jobResponse
+	do(t, h, http.MethodGet, "/jobs", "", &jobs)
+	if len(jobs) != len(cases) {
+		t.Fatalf("expected %d jobs, got %d", len(cases), len(jobs))
+	}
+	if code := do(t, h, http.MethodDelete, "/jobs/"+jobs[0].ID.String(), "", nil); code != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, code)
+	}
+	if code := do(t, h, http.MethodGet, "/jobs/"+jobs[0].ID.String(), "", nil); code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, code)
+	}
+}
+
+func TestFindPredecessorInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	var game, blinker, torus, elementary gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":["O"]}}`, &game)
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":["O","O","O"]}}`, &blinker)
+	do(t, h, http.MethodPost, "/games", `{"rule":"B3/S23:T4,4","board":{"cells":["O"]}}`, &torus)
+	do(t, h, http.MethodPost, "/games", `{"kind":"elementary","board":{"cells":["O"]}}`, &elementary)
+
+	cases := []struct {
+		name string
+		id   string
+		body string
+		code int
+	}{
+		{name: "elementary", id: elementary.ID.String(), code: http.StatusBadRequest},
+		{name: "torus", id: torus.ID.String(), code: http.StatusBadRequest},
+		{name: "negative margin", id: game.ID.String(), body: `{"margin":-1}`, code: http.StatusBadRequest},
+		{name: "large region", id: game.ID.String(), body: `{"width":100,"height":100}`, code: http.StatusBadRequest},
+		{name: "large margin", id: game.ID.String(), body: `{"margin":40}`, code: http.StatusBadRequest},
+		{name: "overflowing margin", id: blinker.ID.String(), body: `{"margin":2305843009213693952}`, code: http.StatusBadRequest},
+		{name: "overflowing region", id: game.ID.String(), body: `{"width":4294967296,"height":4294967296}`, code: http.StatusBadRequest},
+		{name: "missing", id: uuid.NewString(), code: http.StatusNotFound},
+		{name: "bad id", id: "bad", code: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodPost, "/games/"+tc.id+"/predecessor", tc.body, nil); code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
+
+func TestJobsLimit(t *testing.T) {
+	js := newJobs()
+	var started []*job
+	for range maxRunningJobs {
+		j := &job{id: uuid.New(), cancel: func() {}, status: jobRunning}
+		if err := js.add(j); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		started = append(started, j)
+	}
+	if err := js.add(&job{id: uuid.New(), status: jobRunning}); !errors.Is(err, domain.ErrUnavailable) {
+		t.Fatalf("expected %v, got %v", domain.ErrUnavailable, err)
+	}
+
+	// a removed job is counted until its search returns
+	if _, err := js.remove(started[2].id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := js.add(&job{id: uuid.New(), status: jobRunning}); !errors.Is(err, domain.ErrUnavailable) {
+		t.Fatalf("expected %v, got %v", domain.ErrUnavailable, err)
+	}
+	js.done()
+
+	// a finished job makes room for another, and is kept until it expires
+	started[0].status, started[0].doneAt = jobFound, time.Now()
+	started[1].status, started[1].doneAt = jobFound, time.Now().Add(-jobTTL-time.Second)
+	js.done()
+	js.done()
+	for range 2 {
+		if err := js.add(&job{id: uuid.New(), status: jobRunning}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := js.get(started[0].id); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := js.get(started[1].id); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrNotFound, err)
+	}
+	if got := len(js.list()); got != maxRunningJobs {
+		t.Errorf("expected %d jobs, got %d", maxRunningJobs, got)
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+const (
+	// maxVolumeSize is the maximum width, height, or depth of a volume in
+	// cells.
+	maxVolumeSize = 256
+	// defaultRule3D is the rule of a three dimensional game when omitted.
+	defaultRule3D = "4555"
+)
+
+// volumeJSON is the JSON representation of a [life.Volume]. Each layer is
+// rows of text like the cells of a [boardJSON], where '.' is dead and 'O' is
+// alive. The x, y, and z coordinates place the top left cell of the first
+// layer in space.
+type volumeJSON struct {
+	X      int        `json:"x"`
+	Y      int        `json:"y"`
+	Z      int        `json:"z"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Depth  int        `json:"depth"`
+	Layers [][]string `json:"layers"`
+}
+
+// newLife3DGame creates a three dimensional game from a request.
+func newLife3DGame(req createGameRequest) (*domain.Game, error) {
+	if req.Rule == "" {
+		req.Rule = defaultRule3D
+	}
+	rule, err := parseRule3D(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if req.Engine != engineAuto {
+		return nil, fmt.Errorf("%w: %s games only support engine %q", domain.ErrInvalidData, domain.KindLife3D, engineAuto)
+	}
+	volume, err := req.Volume.volume()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Game{Rule: rule.String(), Engine: req.Engine, Volume: volume}, nil
+}
+
+// stepLife3D advances the volume of a three dimensional game by up to limit
+// generations, watching every generation for extinction and cycles. It stops
+// early when the context is cancelled.
+func stepLife3D(ctx context.Context, game *domain.Game, n, limit int64) (*life.Volume, life.Cycle, error) {
+	rule, err := parseRule3D(game.Rule)
+	if err != nil {
+		return nil, life.Cycle{}, err
+	}
+	if n < 1 || n > limit {
+		return nil, life.Cycle{}, fmt.Errorf("%w: generations must be between 1 and %d", domain.ErrInvalidData, limit)
+	}
+
+	// a volume grows by at most a cell on each side per generation, so stop
+	// as soon as it no longer fits rather than stepping every generation
+	return life.Run(ctx, game.Volume, n, func(v *life.Volume) (*life.Volume, error) {
+		v = life.Step3D(v, rule)
+		if v.Width() > maxVolumeSize || v.Height() > maxVolumeSize || v.Depth() > maxVolumeSize {
+			return nil, fmt.Errorf("%w: resulting volume exceeds %dx%dx%d", domain.ErrInvalidData,
+				maxVolumeSize, maxVolumeSize, maxVolumeSize)
+		}
+		return v, nil
+	})
+}
+
+// getLayer responds with a single layer of a three dimensional game as a
+// board. The layer is chosen by its z coordinate in space, and layers beyond
+// the volume are empty.
+func (h *Handler) getLayer(w http.ResponseWriter, r *http.Request) {
+	z, err := strconv.Atoi(r.PathValue("z"))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: layer %q must be an integer", domain.ErrInvalidData, r.PathValue("z")))
+		return
+	}
+
+	game, err := h.life3DGame(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	_, _, z0 := game.Volume.Origin()
+	writeJSON(w, r, http.StatusOK, newBoardJSON(game.Volume.Layer(z-z0)))
+}
+
+// getVoxels responds with the volume of a three dimensional game as a
+// MagicaVoxel .vox model.
+func (h *Handler) getVoxels(w http.ResponseWriter, r *http.Request) {
+	game, err := h.life3DGame(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.vox"`, game.ID))
+	if err := game.Volume.WriteVox(w); err != nil {
+		logger := logging.FromContext(r.Context())
+		logger.Error("failed to write response", slog.Any("error", err))
+	}
+}
+
+// life3DGame retrieves the game in the request path, which must be three
+// dimensional.
+func (h *Handler) life3DGame(r *http.Request) (*domain.Game, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return nil, err
+	}
+	game, err := h.games.GetGame(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if game.Kind != domain.KindLife3D {
+		return nil, fmt.Errorf("%w: game %s is not a %s game", domain.ErrInvalidData, id, domain.KindLife3D)
+	}
+	return game, nil
+}
+
+// parseRule3D parses a three dimensional rulestring, reporting failures as
+// [domain.ErrInvalidRule] while keeping the detail from [life.ParseRule3D].
+func parseRule3D(s string) (life.Rule3D, error) {
+	rule, err := life.ParseRule3D(s)
+	if err != nil {
+		return life.Rule3D{}, ruleError(err)
+	}
+	return rule, nil
+}
+
+// newVolumeJSON converts a volume into its JSON representation.
+func newVolumeJSON(v *life.Volume) volumeJSON {
+	x, y, z := v.Origin()
+	return volumeJSON{
+		X:      x,
+		Y:      y,
+		Z:      z,
+		Width:  v.Width(),
+		Height: v.Height(),
+		Depth:  v.Depth(),
+		Layers: v.Layers(),
+	}
+}
+
+// volume converts the JSON representation into a [life.Volume]. The width,
+// height, and depth are optional and grow the volume beyond the given
+// layers.
+func (vj volumeJSON) volume() (*life.Volume, error) {
+	layers, err := life.NewVolumeFromLayers(vj.Layers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	width := max(vj.Width, layers.Width())
+	height := max(vj.Height, layers.Height())
+	depth := max(vj.Depth, layers.Depth())
+	if min(width, height, depth) < 1 || max(width, height, depth) > maxVolumeSize {
+		return nil, fmt.Errorf("%w: volume must be between 1x1x1 and %dx%dx%d", domain.ErrInvalidData,
+			maxVolumeSize, maxVolumeSize, maxVolumeSize)
+	}
+
+	volume := life.NewVolume(width, height, depth)
+	volume.SetOrigin(vj.X, vj.Y, vj.Z)
+	for z := 0; z < layers.Depth(); z++ {
+		for y := 0; y < layers.Height(); y++ {
+			for x := 0; x < layers.Width(); x++ {
+				volume.Set(x, y, z, layers.Alive(x, y, z))
+			}
+		}
+	}
+	return volume, nil
+}
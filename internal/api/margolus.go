@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// defaultMargolusRule is the rule of a Margolus game when omitted.
+const defaultMargolusRule = "BBM"
+
+// newMargolusGame creates a game of a Margolus block cellular automaton
+// from a request.
+func newMargolusGame(req createGameRequest) (*domain.Game, error) {
+	if req.Rule == "" {
+		req.Rule = defaultMargolusRule
+	}
+	rule, err := parseMargolusRule(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if req.Engine != engineAuto {
+		return nil, fmt.Errorf("%w: %s games only support engine %q", domain.ErrInvalidData, domain.KindMargolus, engineAuto)
+	}
+	board, err := req.Board.grid(rule.Topology(), 2)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Game{Rule: rule.String(), Engine: req.Engine, Board: board}, nil
+}
+
+// stepMargolus advances the board of a Margolus game by up to limit
+// generations, or steps it back when n is negative and the rule is
+// reversible. It stops early when the context is cancelled.
+func stepMargolus(ctx context.Context, game *domain.Game, n, limit int64) (*life.Board, error) {
+	rule, err := parseMargolusRule(game.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 || n < -limit || n > limit {
+		return nil, fmt.Errorf("%w: generations must be between %d and %d, excluding 0", domain.ErrInvalidData, -limit, limit)
+	}
+	if n < 0 && !rule.Reversible() {
+		return nil, fmt.Errorf("%w: rule %s is not reversible, so it cannot be stepped backward", domain.ErrInvalidData, rule)
+	}
+
+	// a board on an unbounded plane grows by at most a block on each side
+	// per generation, so stop as soon as it no longer fits rather than
+	// stepping every generation
+	b := game.Board
+	for i := int64(0); i < max(n, -n); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			b = life.StepMargolus(b, rule, game.Generation+i, 1)
+		} else if b, err = life.StepMargolusBack(b, rule, game.Generation-i, 1); err != nil {
+			return nil, err
+		}
+		if b.Width() > maxBoardSize || b.Height() > maxBoardSize {
+			return nil, fmt.Errorf("%w: resulting board exceeds %dx%d", domain.ErrInvalidData, maxBoardSize, maxBoardSize)
+		}
+	}
+	return b, nil
+}
+
+// parseMargolusRule parses a Margolus rule, reporting failures as
+// [domain.ErrInvalidRule] while keeping the detail from
+// [life.ParseMargolusRule].
+func parseMargolusRule(s string) (life.MargolusRule, error) {
+	rule, err := life.ParseMargolusRule(s)
+	if err != nil {
+		return life.MargolusRule{}, ruleError(err)
+	}
+	return rule, nil
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// objectResponse is the JSON representation of a [domain.Object], along with
+// the cells decoded from its apgcode.
+type objectResponse struct {
+	Apgcode    string    `json:"apgcode"`
+	Rule       string    `json:"rule"`
+	Population int       `json:"population"`
+	Count      int64     `json:"count"`
+	GameID     uuid.UUID `json:"gameId"`
+	Generation int64     `json:"generation"`
+	Board      boardJSON `json:"board"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// recordObject separates the most recent generation of a game, such as the
+// ash of a soup, into still lifes, oscillators, and spaceships, identifies
+// each by its apgcode, and records them. The first time an object is found
+// under a rule it is created, and every time after it is counted again. It
+// responds with every object of the generation, and is created when any of
+// them was found for the first time.
+func (h *Handler) recordObject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	game, err := h.games.GetGame(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if game.Kind != domain.KindLife {
+		writeError(w, r, fmt.Errorf("%w: objects can only be found in %s games", domain.ErrInvalidData, domain.KindLife))
+		return
+	}
+	rule, err := h.lifeRule(r.Context(), game.Rule)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// identify every object before recording any, so a generation holding
+	// one that is not stable records nothing
+	boards := life.SeparateObjects(game.Board)
+	if len(boards) == 0 {
+		writeError(w, r, fmt.Errorf("%w: generation %d has no live cells", domain.ErrInvalidData, game.Generation))
+		return
+	}
+	codes := make([]string, len(boards))
+	for i, b := range boards {
+		codes[i], err = life.Apgcode(r.Context(), b, rule)
+		if errors.Is(err, life.ErrInvalidObject) {
+			err = fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	status := http.StatusOK
+	resp := make([]objectResponse, 0, len(boards))
+	for i, b := range boards {
+		object := &domain.Object{
+			Rule:       game.Rule,
+			Apgcode:    codes[i],
+			Population: b.Population(),
+			GameID:     game.ID,
+			Generation: game.Generation,
+		}
+		if err := h.objects.RecordObject(r.Context(), object); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if object.Count == 1 {
+			status = http.StatusCreated
+		}
+		o, err := newObjectResponse(object)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resp = append(resp, o)
+	}
+	writeJSON(w, r, status, resp)
+}
+
+// listObjects responds with the recorded objects, optionally only those of
+// the rule and those whose apgcode starts with the prefix given as query
+// parameters, such as ?rule=B3/S23&prefix=xp2_.
+func (h *Handler) listObjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.ObjectFilter{Prefix: query.Get("prefix")}
+	if s := query.Get("rule"); s != "" {
+		rule, err := h.lifeRule(r.Context(), s)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		filter.Rule = rule.String()
+	}
+
+	objects, err := h.objects.ListObjects(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resp := make([]objectResponse, 0, len(objects))
+	for _, object := range objects {
+		o, err := newObjectResponse(object)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resp = append(resp, o)
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// getObject responds with a single recorded object by its apgcode, under the
+// rule given as a query parameter or [life.Conway] when omitted.
+func (h *Handler) getObject(w http.ResponseWriter, r *http.Request) {
+	s := r.URL.Query().Get("rule")
+	if s == "" {
+		s = life.Conway.String()
+	}
+	rule, err := h.lifeRule(r.Context(), s)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	object, err := h.objects.GetObject(r.Context(), rule.String(), r.PathValue("apgcode"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	resp, err := newObjectResponse(object)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// newObjectResponse converts an object into its JSON representation, with
+// its cells decoded from its apgcode.
+func newObjectResponse(object *domain.Object) (objectResponse, error) {
+	board, err := life.DecodeApgcode(object.Apgcode, maxBoardSize)
+	if err != nil {
+		return objectResponse{}, err
+	}
+	return objectResponse{
+		Apgcode:    object.Apgcode,
+		Rule:       object.Rule,
+		Population: object.Population,
+		Count:      object.Count,
+		GameID:     object.GameID,
+		Generation: object.Generation,
+		Board:      newBoardJSON(board),
+		CreatedAt:  object.CreatedAt,
+		UpdatedAt:  object.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordObject(t *testing.T) {
+	h := newTestHandler(t)
+	games := []string{
+		`{"board":{"x":5,"y":5,"cells":["OO","OO"]}}`,
+		`{"board":{"cells":["OOO"]}}`,
+		`{"board":{"cells":[".O.","..O","OOO"]}}`,
+		`{"board":{"cells":["O","O","O"]}}`,
+		`{"rule":"B36/S23","board":{"cells":["OO","OO"]}}`,
+		`{"board":{"cells":["OO...OOO","OO......","........","........","OO......","OO......"]}}`,
+	}
+	var responses [][]objectResponse
+	for i, body := range games {
+		var game gameResponse
+		do(t, h, http.MethodPost, "/games", body, &game)
+		var got []objectResponse
+		code := do(t, h, http.MethodPost, "/games/"+game.ID.String()+"/objects", "", &got)
+		if want := []int{201, 201, 201, 200, 201, 200}[i]; code != want {
+			t.Fatalf("expected status %d, got %d", want, code)
+		}
+		responses = append(responses, got)
+	}
+
+	// the ash of the last game is separated into each of its objects
+	want := [][]string{{"xs4_33"}, {"xp2_7"}, {"xq4_153"}, {"xp2_7"}, {"xs4_33"}, {"xs4_33", "xp2_7", "xs4_33"}}
+	for i, resp := range responses {
+		var got []string
+		for _, o := range resp {
+			got = append(got, o.Apgcode)
+		}
+		if diff := cmp.Diff(want[i], got); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+	}
+	if blinker := responses[3][0]; blinker.Count != 2 || blinker.GameID != responses[1][0].GameID {
+		t.Errorf("expected the blinker to be counted twice and first found in game %s, got %d in game %s", responses[1][0].GameID, blinker.Count, blinker.GameID)
+	}
+	if block := responses[5][2]; block.Count != 3 || block.Population != 4 {
+		t.Errorf("expected the block of 4 cells to be counted three times, got %d cells counted %d times", block.Population, block.Count)
+	}
+	if diff := cmp.Diff(boardJSON{Width: 3, Height: 3, Cells: []string{"OOO", "..O", ".O."}}, responses[2][0].Board); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	searches := []struct {
+		target string
+		want   []string
+	}{
+		{target: "/objects", want: []string{"B3/S23 xp2_7", "B3/S23 xq4_153", "B3/S23 xs4_33", "B36/S23 xs4_33"}},
+		{target: "/objects?rule=b3/s23&prefix=xs", want: []string{"B3/S23 xs4_33"}},
+		{target: "/objects?prefix=xs4_", want: []string{"B3/S23 xs4_33", "B36/S23 xs4_33"}},
+		{target: "/objects?prefix=xp3", want: []string{}},
+	}
+	for _, tc := range searches {
+		t.Run(tc.target, func(t *testing.T) {
+			var objects []objectResponse
+			if code := do(t, h, http.MethodGet, tc.target, "", &objects); code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+			}
+			got := []string{}
+			for _, o := range objects {
+				got = append(got, o.Rule+" "+o.Apgcode)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	var block objectResponse
+	if code := do(t, h, http.MethodGet, "/objects/xs4_33?rule=B36/S23", "", &block); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if block.Rule != "B36/S23" || block.Population != 4 {
+		t.Errorf("expected a block of 4 cells in B36/S23, got %d cells in %s", block.Population, block.Rule)
+	}
+}
+
+func TestRecordObjectInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "dies", body: `{"board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+		{name: "one dies", body: `{"board":{"cells":["OO...O","OO...."]}}`, code: http.StatusBadRequest},
+		{name: "empty", body: `{"board":{"width":3,"height":3}}`, code: http.StatusBadRequest},
+		{name: "torus", body: `{"rule":"B3/S23:T8,8","board":{"cells":["OO","OO"]}}`, code: http.StatusBadRequest},
+		{name: "elementary", body: `{"kind":"elementary","rule":"W30","board":{"cells":["O"]}}`, code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var game gameResponse
+			do(t, h, http.MethodPost, "/games", tc.body, &game)
+			if code := do(t, h, http.MethodPost, "/games/"+game.ID.String()+"/objects", "", nil); code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, code)
+			}
+		})
+	}
+
+	// the block beside a dying cell is not recorded either
+	var objects []objectResponse
+	do(t, h, http.MethodGet, "/objects", "", &objects)
+	if len(objects) != 0 {
+		t.Errorf("expected no objects, got %d", len(objects))
+	}
+}
@@ -0,0 +1,15 @@
+package api
+
+import "github.com/rydelll/conway/pkg/life"
+
+// Option configures a handler by overriding a default setting.
+type Option func(*Handler)
+
+// WithEngines sets the registry of engines games may be stepped with, which
+// are chosen by name when a game is created. A nil registry means the
+// default engines of [life.NewDefaultRegistry] are used.
+func WithEngines(engines *life.Registry) Option {
+	return func(h *Handler) {
+		h.engines = engines
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+// readPattern replaces the cells of the request with those of its pattern,
+// which may be in any format read by [life.ReadPattern]. The pattern is
+// placed at the x and y of the board offset by its own position, and its
+// rule is used when the request omits one. Macrocell patterns of quadtree
+// games, the default kind for them, are kept as a tree instead, and cannot
+// be moved.
+func (req *createGameRequest) readPattern() error {
+	if req.Kind == domain.KindLife3D {
+		return fmt.Errorf("%w: %s games cannot be created from a pattern", domain.ErrInvalidData, domain.KindLife3D)
+	}
+	if len(req.Board.Cells) > 0 || req.Soup != (soupJSON{}) {
+		return fmt.Errorf("%w: game must be created from either cells, a soup, or a pattern", domain.ErrInvalidData)
+	}
+	tree := life.DetectPatternFormat([]byte(req.Pattern)) == life.FormatMacrocell
+	if tree && req.Kind == "" {
+		req.Kind = domain.KindQuadtree
+	}
+	tree = tree && req.Kind == domain.KindQuadtree
+
+	var p *life.PatternFile
+	var err error
+	if tree {
+		p, err = life.ReadMacrocell(strings.NewReader(req.Pattern))
+	} else {
+		p, _, err = life.ReadPattern(strings.NewReader(req.Pattern), maxBoardSize)
+	}
+	if errors.Is(err, life.ErrInvalidPattern) || errors.Is(err, life.ErrTooLarge) {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	} else if err != nil {
+		return err
+	}
+	if req.Rule == "" {
+		req.Rule = p.Rule
+	}
+	if tree {
+		if req.Board.X != 0 || req.Board.Y != 0 || req.Board.Width != 0 || req.Board.Height != 0 {
+			return fmt.Errorf("%w: a macrocell pattern cannot be moved or resized", domain.ErrInvalidData)
+		}
+		req.tree = p.Tree
+		return nil
+	}
+
+	x, y := p.Board.Origin()
+	req.Board.X += x
+	req.Board.Y += y
+	req.Board.Width = max(req.Board.Width, p.Board.Width())
+	req.Board.Height = max(req.Board.Height, p.Board.Height())
+	req.Board.Cells = p.Board.Rows()
+	return nil
+}
+
+// writePattern writes the board or tree of a game as the response in a
+// pattern file format, along with its rule, position, and generation where
+// the format holds them. Trees are only expanded into a board for formats
+// other than macrocell. The pattern is written to memory first, so cells the
+// format cannot hold are reported as an error rather than a partial
+// response.
+func writePattern(w http.ResponseWriter, r *http.Request, game *domain.Game, format string) {
+	if game.Board == nil && game.Tree == nil {
+		writeError(w, r, fmt.Errorf("%w: %s games cannot be written as a pattern", domain.ErrInvalidData, game.Kind))
+		return
+	}
+	p := &life.PatternFile{
+		Rule:       game.Rule,
+		Generation: game.Generation,
+		Board:      game.Board,
+		Tree:       game.Tree,
+	}
+	var err error
+	if p.Tree != nil && format != life.FormatMacrocell {
+		p.Board, err = p.Tree.Board(maxBoardSize)
+	}
+	var buf bytes.Buffer
+	if err == nil {
+		err = life.WritePattern(&buf, p, format)
+	}
+	if err != nil {
+		if errors.Is(err, life.ErrInvalidPattern) || errors.Is(err, life.ErrTooLarge) {
+			err = fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+		}
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d.%s"`, game.ID, game.Generation, format))
+	if _, err := buf.WriteTo(w); err != nil {
+		logger := logging.FromContext(r.Context())
+		logger.Error("failed to write response", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateGamePattern(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		name  string
+		body  string
+		rule  string
+		board boardJSON
+	}{
+		{
+			name:  "glider",
+			body:  `{"pattern":"#N Glider\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!"}`,
+			rule:  "B3/S23",
+			board: boardJSON{Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name:  "position",
+			body:  `{"board":{"x":10},"pattern":"#CXRLE Pos=-1,2\nx = 2, y = 1\n2o!"}`,
+			rule:  "B3/S23",
+			board: boardJSON{X: 9, Y: 2, Width: 2, Height: 1, Cells: []string{"OO"}},
+		},
+		{
+			name:  "request rule",
+			body:  `{"rule":"B36/S23","pattern":"x = 1, y = 1, rule = B3/S23\no!"}`,
+			rule:  "B36/S23",
+			board: boardJSON{Width: 1, Height: 1, Cells: []string{"O"}},
+		},
+		{
+			name:  "cells",
+			body:  `{"pattern":"!Name: Glider\n.O.\n..O\nOOO"}`,
+			rule:  "B3/S23",
+			board: boardJSON{Width: 3, Height: 3, Cells: []string{".O.", "..O", "OOO"}},
+		},
+		{
+			name:  "life 1.06",
+			body:  `{"pattern":"#Life 1.06\n0 -1\n1 -1"}`,
+			rule:  "B3/S23",
+			board: boardJSON{Y: -1, Width: 2, Height: 1, Cells: []string{"OO"}},
+		},
+		{
+			name:  "generations",
+			body:  `{"pattern":"x = 3, y = 1, rule = 23/3/3\nAB.!"}`,
+			rule:  "B3/S23/C3",
+			board: boardJSON{Width: 3, Height: 1, Cells: []string{"OB."}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got gameResponse
+			if code := do(t, h, http.MethodPost, "/games", tc.body, &got); code != http.StatusCreated {
+				t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+			}
+			if got.Rule != tc.rule {
+				t.Errorf("expected rule %s, got %s", tc.rule, got.Rule)
+			}
+			if diff := cmp.Diff(tc.board, got.Board); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateGamePatternInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid", body: `{"pattern":"bo$ob!"}`},
+		{name: "too large", body: `{"pattern":"x = 5000, y = 1\no!"}`},
+		{name: "cells", body: `{"board":{"cells":["O"]},"pattern":"x = 1, y = 1\no!"}`},
+		{name: "soup", body: `{"soup":{"width":4,"height":4},"pattern":"x = 1, y = 1\no!"}`},
+		{name: "life3d", body: `{"kind":"life3d","pattern":"x = 1, y = 1\no!"}`},
+		{name: "state of rule", body: `{"pattern":"x = 1, y = 1\nC!"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodPost, "/games", tc.body, nil); code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+			}
+		})
+	}
+}
+
+func TestGetGenerationPattern(t *testing.T) {
+	h := newTestHandler(t)
+	var glider, wire, cube gameResponse
+	do(t, h, http.MethodPost, "/games", `{"board":{"cells":[".O.","..O","OOO"]}}`, &glider)
+	do(t, h, http.MethodPost, "/games", `{"rule":"B3/S23/C3","board":{"cells":["OB"]}}`, &wire)
+	do(t, h, http.MethodPost, "/games", `{"kind":"life3d","volume":{"layers":[["O"]]}}`, &cube)
+
+	formats := []struct {
+		format string
+		want   string
+	}{
+		{format: "rle", want: "#CXRLE Pos=1,1 Gen=4\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"},
+		{format: "cells", want: ".O.\n..O\nOOO\n"},
+		{format: "life105", want: "#Life 1.05\n#N\n#P 1 1\n.*\n..*\n***\n"},
+		{format: "life106", want: "#Life 1.06\n2 1\n3 2\n1 3\n2 3\n3 3\n"},
+	}
+	for _, tc := range formats {
+		t.Run(tc.format, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/games/"+glider.ID.String()+"/generations/4."+tc.format, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if diff := cmp.Diff(tc.want, w.Body.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	cases := []struct {
+		name   string
+		target string
+		code   int
+	}{
+		{name: "unknown format", target: "/games/" + glider.ID.String() + "/generations/4.txt", code: http.StatusNotFound},
+		{name: "bad generation", target: "/games/" + glider.ID.String() + "/generations/x.rle", code: http.StatusBadRequest},
+		{name: "volume", target: "/games/" + cube.ID.String() + "/generations/0.rle", code: http.StatusBadRequest},
+		{name: "states", target: "/games/" + wire.ID.String() + "/generations/0.cells", code: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodGet, tc.target, "", nil); code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
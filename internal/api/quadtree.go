@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// treeJSON is the JSON representation of a [life.Quadtree]. Its cells may be
+// far too many to list, so it holds the width by height rectangle around its
+// live cells, with the top left cell at x, y, and the level of the root.
+// The cells are fetched as a macrocell file.
+type treeJSON struct {
+	X      int64 `json:"x"`
+	Y      int64 `json:"y"`
+	Width  int64 `json:"width"`
+	Height int64 `json:"height"`
+	Level  int   `json:"level"`
+}
+
+// newQuadtreeGame creates a game of a two-state Life-like rule held by a
+// quadtree from a request, either from its macrocell pattern or from its
+// board.
+func (h *Handler) newQuadtreeGame(ctx context.Context, req createGameRequest) (*domain.Game, error) {
+	if req.Rule == "" {
+		req.Rule = life.Conway.String()
+	}
+	rule, err := h.lifeRule(ctx, req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if req.Engine != engineAuto && req.Engine != life.EngineHashLife {
+		return nil, fmt.Errorf("%w: %s games only support engines %q and %q", domain.ErrInvalidData, domain.KindQuadtree, engineAuto, life.EngineHashLife)
+	}
+	if _, err := h.treeEngine(rule); err != nil {
+		return nil, err
+	}
+	if req.Soup != (soupJSON{}) {
+		return nil, fmt.Errorf("%w: %s games cannot be created from a soup", domain.ErrInvalidData, domain.KindQuadtree)
+	}
+
+	tree := req.tree
+	if tree == nil {
+		board, err := req.Board.board(rule)
+		if err != nil {
+			return nil, err
+		}
+		tree = life.NewQuadtree(board)
+	}
+	return &domain.Game{Rule: rule.String(), Engine: req.Engine, Tree: tree}, nil
+}
+
+// stepQuadtree advances the tree of a quadtree game by n generations with
+// HashLife, without ever expanding it into a board.
+func (h *Handler) stepQuadtree(ctx context.Context, game *domain.Game, n int64) (*life.Quadtree, error) {
+	rule, err := h.lifeRule(ctx, game.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > maxHashLifeGenerations {
+		return nil, fmt.Errorf("%w: generations must be between 1 and %d", domain.ErrInvalidData, int64(maxHashLifeGenerations))
+	}
+	engine, err := h.treeEngine(rule)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := engine.AdvanceTree(ctx, game.Tree, rule, n)
+	if errors.Is(err, life.ErrTooLarge) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	return tree, err
+}
+
+// treeEngine returns the registered HashLife engine, which advances
+// quadtrees, when it supports the rule.
+func (h *Handler) treeEngine(rule life.Rule) (life.TreeEngine, error) {
+	engine, _ := h.engines.Engine(life.EngineHashLife)
+	tree, ok := engine.(life.TreeEngine)
+	if !ok {
+		return nil, fmt.Errorf("%w: no registered engine advances %s games", domain.ErrInvalidData, domain.KindQuadtree)
+	}
+	if err := tree.Check(rule); err != nil {
+		return nil, fmt.Errorf("%w: %s games require a two-state range 1 rule on an unbounded plane", domain.ErrInvalidData, domain.KindQuadtree)
+	}
+	return tree, nil
+}
+
+// newTreeJSON converts a quadtree into its JSON representation.
+func newTreeJSON(q *life.Quadtree) treeJSON {
+	tj := treeJSON{Level: q.Level()}
+	if minX, minY, maxX, maxY, ok := q.Bounds(); ok {
+		tj.X, tj.Y = minX, minY
+		tj.Width, tj.Height = maxX-minX+1, maxY-minY+1
+	}
+	return tj
+}
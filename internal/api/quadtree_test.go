@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQuadtreeGame(t *testing.T) {
+	h := newTestHandler(t)
+	var created gameResponse
+	body := `{"pattern":"[M2] (golly 4.2)\n#R B3/S23\n.*$..*$***$\n"}`
+	if code := do(t, h, http.MethodPost, "/games", body, &created); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	if created.Kind != "quadtree" || created.Population != 5 {
+		t.Errorf("expected a quadtree game of 5 cells, got a %s game of %d cells", created.Kind, created.Population)
+	}
+	if diff := cmp.Diff(treeJSON{X: -4, Y: -4, Width: 3, Height: 3, Level: 3}, created.Tree); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	var stepped gameResponse
+	if code := do(t, h, http.MethodPost, "/games/"+created.ID.String()+"/step", `{"generations":1048576}`, &stepped); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	want := treeJSON{X: -4 + 1<<18, Y: -4 + 1<<18, Width: 3, Height: 3, Level: stepped.Tree.Level}
+	if diff := cmp.Diff(want, stepped.Tree); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	formats := []struct {
+		target string
+		want   string
+	}{
+		{target: "4.mc", want: "[M2]\n#R B3/S23\n#G 4\n$..*$...*$.***$\n"},
+		{target: "4.rle", want: "#CXRLE Pos=-3,-3 Gen=4\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"},
+	}
+	for _, tc := range formats {
+		t.Run(tc.target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/games/"+created.ID.String()+"/generations/"+tc.target, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if diff := cmp.Diff(tc.want, w.Body.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuadtreeGameInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	cases := []struct {
+		name string
+		body string
+	}{
+		{name: "generations", body: `{"kind":"quadtree","rule":"B3/S23/C3","board":{"cells":["O"]}}`},
+		{name: "torus", body: `{"kind":"quadtree","rule":"B3/S23:T8,8","board":{"cells":["O"]}}`},
+		{name: "engine", body: `{"kind":"quadtree","engine":"naive","board":{"cells":["O"]}}`},
+		{name: "soup", body: `{"kind":"quadtree","soup":{"seed":"a"}}`},
+		{name: "moved", body: `{"board":{"x":1},"pattern":"[M2]\n**$\n"}`},
+		{name: "multistate", body: `{"pattern":"[M2]\n1 0 0 0 1\n"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := do(t, h, http.MethodPost, "/games", tc.body, nil); code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+			}
+		})
+	}
+
+	// two cells 4096 apart, in the top left corners of the top left and
+	// bottom right quadrants of a level 13 tree
+	mc := `[M2]\n*$\n`
+	for level := 4; level < 13; level++ {
+		mc += fmt.Sprintf(`%d %d 0 0 0\n`, level, level-3)
+	}
+	mc += `13 10 0 0 10\n`
+	var huge gameResponse
+	body := `{"pattern":"` + mc + `"}`
+	if code := do(t, h, http.MethodPost, "/games", body, &huge); code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, code)
+	}
+	if code := do(t, h, http.MethodGet, "/games/"+huge.ID.String()+"/generations/0.rle", "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, code)
+	}
+	if code := do(t, h, http.MethodGet, "/games/"+huge.ID.String()+"/generations/0.mc", "", nil); code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, code)
+	}
+}
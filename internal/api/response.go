@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-json-experiment/json"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+// maxBodySize is the maximum number of bytes read from a request body.
+const maxBodySize = 8 << 20
+
+// errorResponse is the body returned for any failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// readJSON decodes the request body into v. Decoding errors are reported as
+// [domain.ErrInvalidData].
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	body := http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := json.UnmarshalRead(body, v); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	return nil
+}
+
+// writeJSON encodes v as the response body with the given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.MarshalWrite(w, v); err != nil {
+		logger := logging.FromContext(r.Context())
+		logger.Error("failed to write response", slog.Any("error", err))
+	}
+}
+
+// writeError maps err to an HTTP status code and writes it as the response.
+// Errors that do not map to a known domain error are logged and hidden from
+// the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, msg := http.StatusInternalServerError, domain.ErrInternal.Error()
+	switch {
+	case errors.Is(err, domain.ErrInvalidID), errors.Is(err, domain.ErrInvalidData),
+		errors.Is(err, domain.ErrInvalidRule):
+		status, msg = http.StatusBadRequest, err.Error()
+	case errors.Is(err, domain.ErrNotFound):
+		status, msg = http.StatusNotFound, err.Error()
+	case errors.Is(err, domain.ErrConflict):
+		status, msg = http.StatusConflict, err.Error()
+	case errors.Is(err, domain.ErrUnavailable):
+		status, msg = http.StatusServiceUnavailable, err.Error()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		status, msg = http.StatusServiceUnavailable, domain.ErrUnavailable.Error()
+	default:
+		logger := logging.FromContext(r.Context())
+		logger.Error("request failed", slog.Any("error", err))
+	}
+	writeJSON(w, r, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/rydelll/conway/internal/domain"
+)
+
+func TestReadJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		err  bool
+	}{
+		{name: "valid", body: `{"generations":1}`},
+		{name: "malformed", body: `{"generations":`, err: true},
+		{name: "wrong type", body: `{"generations":"one"}`, err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v stepGameRequest
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			err := readJSON(httptest.NewRecorder(), r, &v)
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidData) {
+				t.Errorf("expected %v, got %v", domain.ErrInvalidData, err)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code int
+		want string
+	}{
+		{name: "invalid id", err: domain.ErrInvalidID, code: http.StatusBadRequest, want: "invalid resource id"},
+		{name: "invalid data", err: fmt.Errorf("%w: oops", domain.ErrInvalidData), code: http.StatusBadRequest, want: "invalid data: oops"},
+		{name: "invalid rule", err: fmt.Errorf("%w \"B9\"", domain.ErrInvalidRule), code: http.StatusBadRequest, want: "invalid rule \"B9\""},
+		{name: "not found", err: domain.ErrNotFound, code: http.StatusNotFound, want: "not found"},
+		{name: "conflict", err: domain.ErrConflict, code: http.StatusConflict, want: "data conflict"},
+		{name: "unavailable", err: fmt.Errorf("%w: busy", domain.ErrUnavailable), code: http.StatusServiceUnavailable, want: "service unavailable: busy"},
+		{name: "cancelled", err: fmt.Errorf("step: %w", context.Canceled), code: http.StatusServiceUnavailable, want: "service unavailable"},
+		{name: "unknown", err: errors.New("secret"), code: http.StatusInternalServerError, want: "internal server error"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			writeError(w, r, tc.err)
+			if w.Code != tc.code {
+				t.Errorf("expected status %d, got %d", tc.code, w.Code)
+			}
+			var got errorResponse
+			if err := json.UnmarshalRead(w.Result().Body, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got.Error); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// createRuleRequest is the JSON body used to upload a rule file.
+type createRuleRequest struct {
+	Source string `json:"source"`
+}
+
+// ruleResponse is the JSON representation of a rule file.
+type ruleResponse struct {
+	Name      string    `json:"name"`
+	States    int       `json:"states"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// listRules responds with every uploaded rule file.
+func (h *Handler) listRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.rules.ListRules(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := make([]ruleResponse, 0, len(rules))
+	for _, rule := range rules {
+		f, err := parseRuleFile(rule.Source)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resp = append(resp, newRuleResponse(rule, f))
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// createRule stores the Golly .rule file in the request body under the name
+// declared by its @RULE line. Games may then use the name as their rule.
+func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
+	var req createRuleRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	f, err := parseRuleFile(req.Source)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	rule := &domain.RuleFile{Name: f.Name(), Source: req.Source}
+	if err := h.rules.CreateRule(r.Context(), rule); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, newRuleResponse(rule, f))
+}
+
+// getRule responds with a single rule file.
+func (h *Handler) getRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.rules.GetRule(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	f, err := parseRuleFile(rule.Source)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, newRuleResponse(rule, f))
+}
+
+// lifeRule parses the rule of a game, which is either a rulestring or the
+// name of an uploaded rule file followed by an optional bounded grid, such
+// as "WireWorld:T64,64". Failures are reported as [domain.ErrInvalidRule].
+func (h *Handler) lifeRule(ctx context.Context, s string) (life.Rule, error) {
+	rule, parseErr := parseRule(s)
+	if parseErr == nil {
+		return rule, nil
+	}
+
+	name, grid, bounded := strings.Cut(s, ":")
+	stored, err := h.rules.GetRule(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		return life.Rule{}, parseErr
+	} else if err != nil {
+		return life.Rule{}, err
+	}
+	f, err := parseRuleFile(stored.Source)
+	if err != nil {
+		return life.Rule{}, err
+	}
+
+	var topo life.Topology
+	if bounded {
+		if topo, err = life.ParseTopology(grid); err != nil {
+			return life.Rule{}, ruleError(err)
+		}
+	}
+	if rule, err = f.Rule(topo); err != nil {
+		return life.Rule{}, ruleError(err)
+	}
+	return rule, nil
+}
+
+// parseRuleFile parses the source of a rule file, reporting failures as
+// [domain.ErrInvalidRule].
+func parseRuleFile(source string) (*life.RuleFile, error) {
+	f, err := life.ParseRuleFile(strings.NewReader(source))
+	if err != nil {
+		return nil, ruleError(err)
+	}
+	return f, nil
+}
+
+// ruleError converts an error wrapping [life.ErrInvalidRule] into one
+// wrapping [domain.ErrInvalidRule] with the same detail.
+func ruleError(err error) error {
+	detail := strings.TrimPrefix(err.Error(), life.ErrInvalidRule.Error())
+	return fmt.Errorf("%w%s", domain.ErrInvalidRule, detail)
+}
+
+// newRuleResponse converts a rule file into its JSON representation.
+func newRuleResponse(rule *domain.RuleFile, f *life.RuleFile) ruleResponse {
+	return ruleResponse{
+		Name:      rule.Name,
+		States:    f.States(),
+		Source:    rule.Source,
+		CreatedAt: rule.CreatedAt,
+	}
+}
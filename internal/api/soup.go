@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// soupJSON is the JSON representation of the options of a random soup. The
+// size defaults to 16x16, the density to one half, and the symmetry to C1
+// when omitted.
+type soupJSON struct {
+	Seed     string   `json:"seed"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	Density  *float64 `json:"density"`
+	Symmetry string   `json:"symmetry"`
+}
+
+// generateSoup responds with the random soup generated from the options in
+// the request body. The same options always generate the same soup.
+func (h *Handler) generateSoup(w http.ResponseWriter, r *http.Request) {
+	var req soupJSON
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	soup, err := req.board()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, newBoardJSON(soup))
+}
+
+// board generates the soup described by the options with [life.NewSoup].
+func (sj soupJSON) board() (*life.Board, error) {
+	if sj.Seed == "" {
+		return nil, fmt.Errorf("%w: soup requires a seed", domain.ErrInvalidData)
+	}
+	// symmetries may round the size up by a cell
+	if sj.Width >= maxBoardSize || sj.Height >= maxBoardSize {
+		return nil, fmt.Errorf("%w: soup must be smaller than %dx%d", domain.ErrInvalidData, maxBoardSize, maxBoardSize)
+	}
+
+	var opts []life.SoupOption
+	if sj.Width != 0 || sj.Height != 0 {
+		opts = append(opts, life.WithSoupSize(sj.Width, sj.Height))
+	}
+	if sj.Density != nil {
+		opts = append(opts, life.WithDensity(*sj.Density))
+	}
+	if sj.Symmetry != "" {
+		opts = append(opts, life.WithSymmetry(sj.Symmetry))
+	}
+	soup, err := life.NewSoup(sj.Seed, opts...)
+	if errors.Is(err, life.ErrInvalidSoup) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidData, err)
+	}
+	return soup, err
+}
@@ -7,10 +7,17 @@ var (
 	ErrInternal = errors.New("internal server error")
 	// ErrInvalidID when a resource ID is unable to be parsed.
 	ErrInvalidID = errors.New("invalid resource id")
+	// ErrInvalidData when the provided data fails validation.
+	ErrInvalidData = errors.New("invalid data")
+	// ErrInvalidRule when a rulestring is unable to be parsed.
+	ErrInvalidRule = errors.New("invalid rule")
 	// ErrNotFound when the requested resource is not found.
 	ErrNotFound = errors.New("not found")
 	// ErrConflict when data conflicts with the current state of the server.
 	ErrConflict = errors.New("data conflict")
+	// ErrUnavailable when a request is cancelled before it completes, such as
+	// when the server is shutting down.
+	ErrUnavailable = errors.New("service unavailable")
 	// ErrNoUpdate when no data is provider for an update.
 	ErrNoUpdate = errors.New("no update data")
 	// ErrNull when an option is null.
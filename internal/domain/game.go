@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// Kinds of games, which decide how the rule of a game is interpreted and
+// what its board holds.
+const (
+	// KindLife is a two dimensional game of Life-like cellular automata.
+	KindLife = "life"
+	// KindElementary is a one dimensional elementary cellular automaton,
+	// whose board is its spacetime diagram.
+	KindElementary = "elementary"
+	// KindLife3D is a three dimensional game of Life, whose cells are held
+	// by its volume instead of its board.
+	KindLife3D = "life3d"
+	// KindMargolus is a block cellular automaton on the Margolus
+	// neighbourhood, which can be stepped backward when it is reversible.
+	KindMargolus = "margolus"
+	// KindQuadtree is a two dimensional game of a two-state Life-like rule
+	// whose cells are held by its quadtree instead of its board, so it may
+	// be far larger than any board. It is advanced with HashLife.
+	KindQuadtree = "quadtree"
+)
+
+// Game represents a simulation and its most recent generation. The kind
+// decides how the rule is interpreted and what the board holds.
+type Game struct {
+	ID         uuid.UUID
+	Kind       string
+	Rule       string
+	Engine     string
+	Generation int64
+	Board      *life.Board
+	Volume     *life.Volume
+	Tree       *life.Quadtree
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Object represents a still life, oscillator, or spaceship identified by its
+// apgcode under a rule. Each object is stored once per rule and counted
+// every time it is found, and the game and generation are where it was
+// first found.
+type Object struct {
+	Rule       string
+	Apgcode    string
+	Population int
+	Count      int64
+	GameID     uuid.UUID
+	Generation int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ObjectFilter selects objects by their rule and the start of their
+// apgcode, such as "xp2_" for every period 2 oscillator. Empty fields match
+// every object.
+type ObjectFilter struct {
+	Rule   string
+	Prefix string
+}
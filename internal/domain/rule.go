@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// RuleFile represents a custom rule uploaded as a Golly .rule file. Games
+// refer to it by its name, which is declared by the @RULE line of its
+// source.
+type RuleFile struct {
+	Name      string
+	Source    string
+	CreatedAt time.Time
+}
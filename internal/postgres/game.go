@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rydelll/conway/internal/domain"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint
+// violation.
+const uniqueViolation = "23505"
+
+// selectGame selects games joined with their most recent generation in the
+// column order expected by [scanGame].
+const selectGame = `
+	SELECT g.id, g.kind, g.rule, g.engine, g.created_at, g.updated_at, n.generation, n.board
+	FROM games g
+	JOIN LATERAL (
+		SELECT generation, board FROM generations
+		WHERE game_id = g.id
+		ORDER BY generation DESC
+		LIMIT 1
+	) n ON true`
+
+// selectGameAt selects a game joined with its most recent generation at or
+// before a given generation, in the column order expected by [scanGame].
+const selectGameAt = `
+	SELECT g.id, g.kind, g.rule, g.engine, g.created_at, g.updated_at, n.generation, n.board
+	FROM games g
+	JOIN LATERAL (
+		SELECT generation, board FROM generations
+		WHERE game_id = g.id AND generation <= $2
+		ORDER BY generation DESC
+		LIMIT 1
+	) n ON true
+	WHERE g.id = $1`
+
+// GameStore persists games and their generations in PostgreSQL.
+type GameStore struct {
+	db Database
+}
+
+// NewGameStore creates a [GameStore] backed by the given database.
+func NewGameStore(db Database) *GameStore {
+	return &GameStore{db: db}
+}
+
+// CreateGame stores a new game along with its current generation. The
+// timestamps of the game are populated by the database.
+func (s *GameStore) CreateGame(ctx context.Context, game *domain.Game) error {
+	board, population, err := marshalCells(game)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO games (id, kind, rule, engine) VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`,
+		game.ID, game.Kind, game.Rule, game.Engine,
+	).Scan(&game.CreatedAt, &game.UpdatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO generations (game_id, generation, population, board) VALUES ($1, $2, $3, $4)`,
+		game.ID, game.Generation, population, board,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetGame retrieves a game along with its most recent generation.
+func (s *GameStore) GetGame(ctx context.Context, id uuid.UUID) (*domain.Game, error) {
+	row := s.db.QueryRow(ctx, selectGame+` WHERE g.id = $1`, id)
+	game, err := scanGame(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return game, nil
+}
+
+// GetGeneration retrieves a game along with its most recent generation at or
+// before the given generation.
+func (s *GameStore) GetGeneration(ctx context.Context, id uuid.UUID, generation int64) (*domain.Game, error) {
+	row := s.db.QueryRow(ctx, selectGameAt, id, generation)
+	game, err := scanGame(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return game, nil
+}
+
+// ListGames retrieves every game along with its most recent generation,
+// ordered from newest to oldest.
+func (s *GameStore) ListGames(ctx context.Context) ([]*domain.Game, error) {
+	rows, err := s.db.Query(ctx, selectGame+` ORDER BY g.created_at DESC`)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	games := []*domain.Game{}
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		games = append(games, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+	return games, nil
+}
+
+// SaveGeneration stores a new generation for an existing game and updates
+// the game to point at it.
+func (s *GameStore) SaveGeneration(ctx context.Context, game *domain.Game) error {
+	board, population, err := marshalCells(game)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`UPDATE games SET updated_at = now() WHERE id = $1 RETURNING updated_at`,
+		game.ID,
+	).Scan(&game.UpdatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO generations (game_id, generation, population, board) VALUES ($1, $2, $3, $4)`,
+		game.ID, game.Generation, population, board,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RewindGeneration stores an earlier generation for an existing game,
+// discarding every generation from it onward so that it becomes the most
+// recent generation.
+func (s *GameStore) RewindGeneration(ctx context.Context, game *domain.Game) error {
+	board, population, err := marshalCells(game)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`UPDATE games SET updated_at = now() WHERE id = $1 RETURNING updated_at`,
+		game.ID,
+	).Scan(&game.UpdatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+	_, err = tx.Exec(ctx,
+		`DELETE FROM generations WHERE game_id = $1 AND generation >= $2`,
+		game.ID, game.Generation,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO generations (game_id, generation, population, board) VALUES ($1, $2, $3, $4)`,
+		game.ID, game.Generation, population, board,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteGame removes a game and all of its generations.
+func (s *GameStore) DeleteGame(ctx context.Context, id uuid.UUID) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM games WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// scanGame scans a single game row selected with [selectGame].
+func scanGame(row pgx.Row) (*domain.Game, error) {
+	var (
+		game  domain.Game
+		board []byte
+	)
+	if err := row.Scan(&game.ID, &game.Kind, &game.Rule, &game.Engine, &game.CreatedAt, &game.UpdatedAt, &game.Generation, &board); err != nil {
+		return nil, err
+	}
+	var err error
+	switch game.Kind {
+	case domain.KindLife3D:
+		game.Volume = new(life.Volume)
+		err = game.Volume.UnmarshalBinary(board)
+	case domain.KindQuadtree:
+		game.Tree = new(life.Quadtree)
+		err = game.Tree.UnmarshalBinary(board)
+	default:
+		game.Board = new(life.Board)
+		err = game.Board.UnmarshalBinary(board)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("game %s: %w", game.ID, err)
+	}
+	return &game, nil
+}
+
+// marshalCells encodes the cells of a game along with their population. The
+// cells of a three dimensional game are held by its volume, those of a
+// quadtree game by its tree, and by its board otherwise.
+func marshalCells(game *domain.Game) ([]byte, int64, error) {
+	switch game.Kind {
+	case domain.KindLife3D:
+		data, err := game.Volume.MarshalBinary()
+		return data, int64(game.Volume.Population()), err
+	case domain.KindQuadtree:
+		data, err := game.Tree.MarshalBinary()
+		return data, int64(min(game.Tree.Population(), math.MaxInt64)), err
+	}
+	data, err := game.Board.MarshalBinary()
+	return data, int64(game.Board.Population()), err
+}
+
+// mapError converts database errors into domain errors where possible.
+func mapError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return domain.ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rydelll/conway/internal/domain"
+)
+
+// selectObject selects objects in the column order expected by [scanObject].
+const selectObject = `
+	SELECT rule, apgcode, population, count, game_id, generation, created_at, updated_at
+	FROM objects`
+
+// likeEscaper escapes the wildcards of a LIKE pattern, so that a prefix such
+// as "xs4_" matches the underscore literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ObjectStore persists the objects found in games in PostgreSQL.
+type ObjectStore struct {
+	db Database
+}
+
+// NewObjectStore creates an [ObjectStore] backed by the given database.
+func NewObjectStore(db Database) *ObjectStore {
+	return &ObjectStore{db: db}
+}
+
+// RecordObject stores an object found in a game, or counts it again when its
+// rule and apgcode are already stored. The count, the game and generation it
+// was first found in, and the timestamps of the object are populated by the
+// database.
+func (s *ObjectStore) RecordObject(ctx context.Context, object *domain.Object) error {
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO objects (rule, apgcode, population, game_id, generation) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rule, apgcode) DO UPDATE SET count = objects.count + 1, updated_at = now()
+		RETURNING count, game_id, generation, created_at, updated_at`,
+		object.Rule, object.Apgcode, object.Population, object.GameID, object.Generation,
+	).Scan(&object.Count, &object.GameID, &object.Generation, &object.CreatedAt, &object.UpdatedAt)
+	return mapError(err)
+}
+
+// GetObject retrieves an object by its rule and apgcode.
+func (s *ObjectStore) GetObject(ctx context.Context, rule, apgcode string) (*domain.Object, error) {
+	row := s.db.QueryRow(ctx, selectObject+` WHERE rule = $1 AND apgcode = $2`, rule, apgcode)
+	object, err := scanObject(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return object, nil
+}
+
+// ListObjects retrieves the objects selected by the filter, ordered by rule
+// and apgcode.
+func (s *ObjectStore) ListObjects(ctx context.Context, filter domain.ObjectFilter) ([]*domain.Object, error) {
+	rows, err := s.db.Query(ctx,
+		selectObject+` WHERE ($1 = '' OR rule = $1) AND apgcode LIKE $2 || '%' ORDER BY rule, apgcode`,
+		filter.Rule, likeEscaper.Replace(filter.Prefix),
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	objects := []*domain.Object{}
+	for rows.Next() {
+		object, err := scanObject(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+	return objects, nil
+}
+
+// scanObject scans a single object row.
+func scanObject(row pgx.Row) (*domain.Object, error) {
+	var object domain.Object
+	err := row.Scan(
+		&object.Rule, &object.Apgcode, &object.Population, &object.Count,
+		&object.GameID, &object.Generation, &object.CreatedAt, &object.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rydelll/conway/internal/domain"
+)
+
+// RuleStore persists custom rule files in PostgreSQL.
+type RuleStore struct {
+	db Database
+}
+
+// NewRuleStore creates a [RuleStore] backed by the given database.
+func NewRuleStore(db Database) *RuleStore {
+	return &RuleStore{db: db}
+}
+
+// CreateRule stores a new rule file. The creation time of the rule is
+// populated by the database.
+func (s *RuleStore) CreateRule(ctx context.Context, rule *domain.RuleFile) error {
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO rules (name, source) VALUES ($1, $2) RETURNING created_at`,
+		rule.Name, rule.Source,
+	).Scan(&rule.CreatedAt)
+	return mapError(err)
+}
+
+// GetRule retrieves a rule file by its name.
+func (s *RuleStore) GetRule(ctx context.Context, name string) (*domain.RuleFile, error) {
+	row := s.db.QueryRow(ctx, `SELECT name, source, created_at FROM rules WHERE name = $1`, name)
+	rule, err := scanRule(row)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return rule, nil
+}
+
+// ListRules retrieves every rule file ordered by name.
+func (s *RuleStore) ListRules(ctx context.Context) ([]*domain.RuleFile, error) {
+	rows, err := s.db.Query(ctx, `SELECT name, source, created_at FROM rules ORDER BY name`)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.RuleFile{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapError(err)
+	}
+	return rules, nil
+}
+
+// scanRule scans a single rule file row.
+func scanRule(row pgx.Row) (*domain.RuleFile, error) {
+	var rule domain.RuleFile
+	if err := row.Scan(&rule.Name, &rule.Source, &rule.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS generations;
+DROP TABLE IF EXISTS games;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS games (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS generations (
+    game_id UUID NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    generation BIGINT NOT NULL CHECK (generation >= 0),
+    population BIGINT NOT NULL,
+    board BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, generation)
+);
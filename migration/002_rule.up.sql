@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN IF NOT EXISTS rule TEXT NOT NULL DEFAULT 'B3/S23';
@@ -0,0 +1,4 @@
+-- Rules before bounded grid suffixes were always evaluated on a bounded
+-- plane the size of the board, so strip every suffix to keep each game
+-- loadable. Wrapping topologies are lost and become bounded planes.
+UPDATE games SET rule = split_part(rule, ':', 1) WHERE rule LIKE '%:%';
@@ -0,0 +1,12 @@
+-- Rules without a bounded grid suffix are now evaluated on an unbounded
+-- plane. Existing games were evaluated on a bounded plane the size of their
+-- board, so pin them to it.
+UPDATE games g
+SET rule = g.rule || ':P'
+    || ('x' || encode(substring(n.board FROM 1 FOR 4), 'hex'))::bit(32)::int
+    || ','
+    || ('x' || encode(substring(n.board FROM 5 FOR 4), 'hex'))::bit(32)::int
+FROM generations n
+WHERE n.game_id = g.id
+    AND n.generation = 0
+    AND position(':' IN g.rule) = 0;
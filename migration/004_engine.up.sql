@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN IF NOT EXISTS engine TEXT NOT NULL DEFAULT 'auto';
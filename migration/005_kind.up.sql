@@ -0,0 +1 @@
+ALTER TABLE games ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'life';
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS rules (
+    name TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DELETE FROM generations WHERE generation < 0;
+ALTER TABLE generations ADD CONSTRAINT generations_generation_check CHECK (generation >= 0);
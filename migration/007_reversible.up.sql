@@ -0,0 +1,2 @@
+-- Reversible games can be stepped backward before their first generation.
+ALTER TABLE generations DROP CONSTRAINT IF EXISTS generations_generation_check;
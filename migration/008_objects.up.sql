@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS objects (
+    rule TEXT NOT NULL,
+    apgcode TEXT NOT NULL,
+    population INTEGER NOT NULL,
+    count BIGINT NOT NULL DEFAULT 1,
+    game_id UUID NOT NULL,
+    generation BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (rule, apgcode)
+);
+
+-- Objects are searched by the start of their apgcode across every rule.
+CREATE INDEX IF NOT EXISTS objects_apgcode_idx ON objects (apgcode text_pattern_ops);
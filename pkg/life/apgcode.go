@@ -0,0 +1,334 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidApgcode when an apgcode cannot be decoded.
+	ErrInvalidApgcode = errors.New("invalid apgcode")
+	// ErrInvalidObject when a pattern is not a still life, oscillator, or
+	// spaceship that can be given an apgcode.
+	ErrInvalidObject = errors.New("invalid object")
+)
+
+const (
+	// maxObjectPeriod is the longest period of an object given an apgcode.
+	maxObjectPeriod = 4096
+	// maxObjectSize is the largest width and height of any phase of an
+	// object given an apgcode, so growing patterns are rejected quickly.
+	maxObjectSize = 256
+	// apgcodeDigits are the characters of the extended Wechsler format. A
+	// column of a strip is one of the first 32, and runs of four or more
+	// blank columns are counted by any of them after a 'y'.
+	apgcodeDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// maxBlankRun is the longest run of blank columns written after a 'y'.
+	maxBlankRun = 4 + len(apgcodeDigits) - 1
+)
+
+// Apgcode returns the apgcode used by Catagolue to identify the object on
+// the board: "xs" followed by the population for still lifes, "xp" followed
+// by the period for oscillators, and "xq" followed by the period for
+// spaceships, then an underscore and the cells in the extended Wechsler
+// format. Every phase of the object is encoded in each of its eight
+// orientations, and the shortest encoding wins, ties going to the first in
+// ASCII order, so the same object always has the same apgcode. An empty
+// board is "xs0_0".
+//
+// The board must return to the same cells, possibly moved, within 4096
+// generations of the two-state rule on an unbounded plane, without growing
+// beyond 256x256. Hexagonal rules are rejected, as their objects have other
+// orientations. Apgcode stops between generations when the context is
+// cancelled and returns the context error.
+func Apgcode(ctx context.Context, b *Board, r Rule) (string, error) {
+	if r.States() != 2 || r.Topology().Bounded() || r.Neighborhood() == Hexagonal {
+		return "", fmt.Errorf("%w: apgcodes require a two-state rule on an unbounded square plane", ErrInvalidObject)
+	}
+	b = trimPlane(b)
+	if b.Population() == 0 {
+		return "xs0_0", nil
+	}
+
+	phases := []*Board{b}
+	next := b
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		next = Step(next, r)
+		if next.Population() == 0 {
+			return "", fmt.Errorf("%w: pattern dies out after %d generations", ErrInvalidObject, len(phases))
+		}
+		if sameCells(b, next) {
+			break
+		}
+		if next.width > maxObjectSize || next.height > maxObjectSize {
+			return "", fmt.Errorf("%w: pattern grows beyond %dx%d", ErrInvalidObject, maxObjectSize, maxObjectSize)
+		}
+		if len(phases) == maxObjectPeriod {
+			return "", fmt.Errorf("%w: pattern does not repeat within %d generations", ErrInvalidObject, maxObjectPeriod)
+		}
+		phases = append(phases, next)
+	}
+
+	var prefix string
+	switch {
+	case next.x != b.x || next.y != b.y:
+		prefix = "xq" + strconv.Itoa(len(phases))
+	case len(phases) > 1:
+		prefix = "xp" + strconv.Itoa(len(phases))
+	default:
+		prefix = "xs" + strconv.Itoa(b.Population())
+	}
+
+	var best string
+	for _, phase := range phases {
+		for _, o := range orientations(phase) {
+			code := wechsler(o)
+			if best == "" || len(code) < len(best) || (len(code) == len(best) && code < best) {
+				best = code
+			}
+		}
+	}
+	return prefix + "_" + best, nil
+}
+
+// DecodeApgcode returns the cells of a still life, oscillator, or spaceship
+// from its apgcode, in the orientation and phase it encodes with its top
+// left cell at the origin. The population of a still life must match its
+// prefix. Objects that would not fit in a maxSize by maxSize board return
+// [ErrTooLarge].
+func DecodeApgcode(code string, maxSize int) (*Board, error) {
+	prefix, body, ok := strings.Cut(code, "_")
+	if !ok || len(prefix) < 3 || prefix[0] != 'x' || !strings.ContainsRune("spq", rune(prefix[1])) {
+		return nil, fmt.Errorf("%w: %q must start with xs, xp, or xq followed by a number and an underscore", ErrInvalidApgcode, code)
+	}
+	n, err := strconv.Atoi(prefix[2:])
+	if err != nil || n < 0 || (n == 0 && prefix[1] != 's') || strconv.Itoa(n) != prefix[2:] {
+		return nil, fmt.Errorf("%w: %q has an invalid number %q", ErrInvalidApgcode, code, prefix[2:])
+	}
+
+	type cell struct{ x, y int }
+	var cells []cell
+	var width, height int
+	var x, v int
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == 'z':
+			x, v = 0, v+1
+		case c == 'w':
+			x += 2
+		case c == 'x':
+			x += 3
+		case c == 'y':
+			i++
+			if i == len(body) {
+				return nil, fmt.Errorf("%w: %q ends with y", ErrInvalidApgcode, code)
+			}
+			run := strings.IndexByte(apgcodeDigits, body[i])
+			if run < 0 {
+				return nil, fmt.Errorf("%w: %q has an unexpected %q after y", ErrInvalidApgcode, code, body[i])
+			}
+			x += 4 + run
+		default:
+			column := strings.IndexByte(apgcodeDigits[:32], c)
+			if column < 0 {
+				return nil, fmt.Errorf("%w: %q has an unexpected %q", ErrInvalidApgcode, code, c)
+			}
+			for w := range 5 {
+				if column>>w&1 == 1 {
+					cells = append(cells, cell{x, 5*v + w})
+					height = max(height, 5*v+w+1)
+				}
+			}
+			x++
+			width = max(width, x)
+		}
+		if x > maxSize || 5*v >= maxSize {
+			return nil, fmt.Errorf("%w: apgcode %q must fit within %dx%d", ErrTooLarge, code, maxSize, maxSize)
+		}
+	}
+
+	b := NewBoard(width, height)
+	for _, c := range cells {
+		b.Set(c.x, c.y, true)
+	}
+	b = trimPlane(b)
+	if prefix[1] == 's' && b.Population() != n {
+		return nil, fmt.Errorf("%w: %q has %d cells, not %d", ErrInvalidApgcode, code, b.Population(), n)
+	}
+	if prefix[1] != 's' && b.Population() == 0 {
+		return nil, fmt.Errorf("%w: %q has no cells", ErrInvalidApgcode, code)
+	}
+	if b.Population() > 0 {
+		b.SetOrigin(0, 0)
+	}
+	return b, nil
+}
+
+// SeparateObjects splits the live cells of a board, such as the ash of a
+// soup, into the objects that are each given an apgcode. Cells within two
+// cells of each other share a neighbour and so may interact, and belong to
+// the same object. Objects that only come that close in later generations
+// are not joined. The objects are ordered by their first cell in row major
+// order, and each is trimmed to its live cells in place on the plane.
+func SeparateObjects(b *Board) []*Board {
+	// union the live cells with those within two cells ahead of them
+	ids := make(map[int]int)
+	var parent []int
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, state := range b.cells {
+		if state != 0 {
+			ids[i] = len(parent)
+			parent = append(parent, len(parent))
+		}
+	}
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			id, ok := ids[y*b.width+x]
+			if !ok {
+				continue
+			}
+			for dy := 0; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					nx, ny := x+dx, y+dy
+					if (dy == 0 && dx <= 0) || nx < 0 || nx >= b.width || ny >= b.height {
+						continue
+					}
+					if other, ok := ids[ny*b.width+nx]; ok {
+						parent[find(other)] = find(id)
+					}
+				}
+			}
+		}
+	}
+
+	var objects [][]int
+	index := make(map[int]int)
+	for i, state := range b.cells {
+		if state == 0 {
+			continue
+		}
+		root := find(ids[i])
+		k, ok := index[root]
+		if !ok {
+			k = len(objects)
+			index[root] = k
+			objects = append(objects, nil)
+		}
+		objects[k] = append(objects[k], i)
+	}
+
+	boards := make([]*Board, len(objects))
+	for k, cells := range objects {
+		minX, minY, maxX, maxY := b.width, b.height, -1, -1
+		for _, i := range cells {
+			x, y := i%b.width, i/b.width
+			minX, minY, maxX, maxY = min(minX, x), min(minY, y), max(maxX, x), max(maxY, y)
+		}
+		o := NewBoard(maxX-minX+1, maxY-minY+1)
+		o.x, o.y = b.x+minX, b.y+minY
+		for _, i := range cells {
+			o.cells[(i/b.width-minY)*o.width+i%b.width-minX] = b.cells[i]
+		}
+		boards[k] = o
+	}
+	return boards
+}
+
+// wechsler encodes the cells of a trimmed board in the extended Wechsler
+// format. The board is cut into strips of five rows, and each column of a
+// strip is written as a character whose bits are the cells from top to
+// bottom. Blank columns at the end of a strip are dropped, runs of them
+// elsewhere are shortened, and strips are separated by 'z'.
+func wechsler(b *Board) string {
+	var sb strings.Builder
+	for top := 0; top < b.height; top += 5 {
+		if top > 0 {
+			sb.WriteByte('z')
+		}
+		blank := 0
+		for x := 0; x < b.width; x++ {
+			column := 0
+			for w := range 5 {
+				if b.Alive(x, top+w) {
+					column |= 1 << w
+				}
+			}
+			if column == 0 {
+				blank++
+				continue
+			}
+			for ; blank > 0; blank -= min(blank, maxBlankRun) {
+				switch run := min(blank, maxBlankRun); run {
+				case 1:
+					sb.WriteByte('0')
+				case 2:
+					sb.WriteByte('w')
+				case 3:
+					sb.WriteByte('x')
+				default:
+					sb.WriteByte('y')
+					sb.WriteByte(apgcodeDigits[run-4])
+				}
+			}
+			sb.WriteByte(apgcodeDigits[column])
+		}
+	}
+	return sb.String()
+}
+
+// orientations returns the eight rotations and reflections of a board.
+func orientations(b *Board) []*Board {
+	boards := make([]*Board, 0, 8)
+	for _, transposed := range []bool{false, true} {
+		for _, flip := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			width, height := b.width, b.height
+			if transposed {
+				width, height = height, width
+			}
+			o := NewBoard(width, height)
+			for y := 0; y < b.height; y++ {
+				for x := 0; x < b.width; x++ {
+					ox, oy := x, y
+					if flip[0] {
+						ox = b.width - 1 - x
+					}
+					if flip[1] {
+						oy = b.height - 1 - y
+					}
+					if transposed {
+						ox, oy = oy, ox
+					}
+					o.cells[oy*width+ox] = b.cells[y*b.width+x]
+				}
+			}
+			boards = append(boards, o)
+		}
+	}
+	return boards
+}
+
+// sameCells reports whether both boards have the same dimensions and cells,
+// wherever they are on the plane.
+func sameCells(a, b *Board) bool {
+	if a.width != b.width || a.height != b.height {
+		return false
+	}
+	for i := range a.cells {
+		if a.cells[i] != b.cells[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApgcode(t *testing.T) {
+	cases := []struct {
+		name string
+		rows []string
+		rule string
+		want string
+	}{
+		{name: "empty", rows: []string{"..."}, rule: "B3/S23", want: "xs0_0"},
+		{name: "block", rows: []string{"OO", "OO"}, rule: "B3/S23", want: "xs4_33"},
+		{name: "beehive", rows: []string{".OO.", "O..O", ".OO."}, rule: "B3/S23", want: "xs6_696"},
+		{name: "boat", rows: []string{"OO.", "O.O", ".O."}, rule: "B3/S23", want: "xs5_253"},
+		{name: "blinker", rows: []string{"OOO"}, rule: "B3/S23", want: "xp2_7"},
+		{name: "toad", rows: []string{".OOO", "OOO."}, rule: "B3/S23", want: "xp2_7e"},
+		{name: "glider", rows: []string{".O.", "..O", "OOO"}, rule: "B3/S23", want: "xq4_153"},
+		{name: "lwss", rows: []string{".O..O", "O....", "O...O", "OOOO."}, rule: "B3/S23", want: "xq4_6frc"},
+		{name: "pentadecathlon", rows: []string{"..O....O..", "OO.OOOO.OO", "..O....O.."}, rule: "B3/S23", want: "xp15_4r4z4r4"},
+		{name: "bi-block", rows: []string{"OO...OO", "OO...OO"}, rule: "B3/S23", want: "xs8_33x33"},
+		{name: "highlife", rows: []string{"OO", "OO"}, rule: "B36/S23", want: "xs4_33"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.rows)
+			b.SetOrigin(7, -3)
+			rule, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := Apgcode(context.Background(), b, rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestApgcodeInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		rows []string
+		rule string
+	}{
+		{name: "dies", rows: []string{"O"}, rule: "B3/S23"},
+		{name: "grows", rows: []string{"OO"}, rule: "B1/S"},
+		{name: "generations", rows: []string{"OO", "OO"}, rule: "B3/S23/C3"},
+		{name: "torus", rows: []string{"OO", "OO"}, rule: "B3/S23:T8,8"},
+		{name: "hexagonal", rows: []string{"OO", "OO"}, rule: "B2/S34H"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.rows)
+			rule, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := Apgcode(context.Background(), b, rule); !errors.Is(err, ErrInvalidObject) {
+				t.Errorf("expected %v, got %v", ErrInvalidObject, err)
+			}
+		})
+	}
+}
+
+func TestDecodeApgcode(t *testing.T) {
+	cases := []struct {
+		code string
+		rows []string
+	}{
+		{code: "xs0_0", rows: []string{}},
+		{code: "xs4_33", rows: []string{"OO", "OO"}},
+		{code: "xq4_153", rows: []string{"OOO", "..O", ".O."}},
+		{code: "xp2_7", rows: []string{"O", "O", "O"}},
+		{code: "xs12_33z33z33", rows: []string{"OO", "OO", "..", "..", "..", "OO", "OO", "..", "..", "..", "OO", "OO"}},
+		{code: "xs8_33w33", rows: []string{"OO..OO", "OO..OO"}},
+		{code: "xs2_1y01", rows: []string{"O....O"}},
+		{code: "xs2_1yz1", rows: []string{"O" + strings.Repeat(".", 39) + "O"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			got, err := DecodeApgcode(tc.code, 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.rows, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeApgcodeInvalid(t *testing.T) {
+	cases := []struct {
+		code string
+		err  error
+	}{
+		{code: "33", err: ErrInvalidApgcode},
+		{code: "xx4_33", err: ErrInvalidApgcode},
+		{code: "xs_33", err: ErrInvalidApgcode},
+		{code: "xs04_33", err: ErrInvalidApgcode},
+		{code: "xp0_7", err: ErrInvalidApgcode},
+		{code: "xs5_33", err: ErrInvalidApgcode},
+		{code: "xp2_", err: ErrInvalidApgcode},
+		{code: "xs4_33y", err: ErrInvalidApgcode},
+		{code: "xs4_3!3", err: ErrInvalidApgcode},
+		{code: "xs8_33yz33", err: ErrTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.code, func(t *testing.T) {
+			if _, err := DecodeApgcode(tc.code, 32); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestApgcodeRoundTrip(t *testing.T) {
+	for _, code := range []string{"xs6_696", "xq4_6frc", "xp2_318c", "xp15_4r4z4r4"} {
+		b, err := DecodeApgcode(code, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := Apgcode(context.Background(), b, Conway)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != code {
+			t.Errorf("expected %s, got %s", code, got)
+		}
+	}
+}
+
+func TestSeparateObjects(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{
+		"OO.....OOO",
+		"OO........",
+		"..OO......",
+		"..OO......",
+		"..........",
+		"..........",
+		"O...O.O...",
+	})
+	b.SetOrigin(-3, 2)
+
+	// the blocks of the beacon and the cells two apart share neighbours
+	type object struct {
+		X, Y int
+		Rows []string
+	}
+	want := []object{
+		{X: -3, Y: 2, Rows: []string{"OO..", "OO..", "..OO", "..OO"}},
+		{X: 4, Y: 2, Rows: []string{"OOO"}},
+		{X: -3, Y: 8, Rows: []string{"O"}},
+		{X: 1, Y: 8, Rows: []string{"O.O"}},
+	}
+	var got []object
+	for _, o := range SeparateObjects(b) {
+		x, y := o.Origin()
+		got = append(got, object{X: x, Y: y, Rows: o.Rows()})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got := SeparateObjects(NewBoard(3, 3)); len(got) != 0 {
+		t.Errorf("expected no objects, got %d", len(got))
+	}
+}
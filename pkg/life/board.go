@@ -0,0 +1,291 @@
+// Package life implements the rules and data structures needed to simulate
+// Conway's Game of Life.
+package life
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidBoard when a board cannot be constructed from the given data.
+var ErrInvalidBoard = errors.New("invalid board")
+
+// Board represents a finite rectangular grid of cells. Cells outside of the
+// grid are always considered dead.
+//
+// Each cell has a state. State 0 is dead and state 1 is alive. The higher
+// states are used by Generations rules for cells that are dying, which are
+// not counted as neighbours but are not yet dead.
+//
+// The origin places the top left cell of the board on the plane. It only
+// changes when a board is stepped on an unbounded [Topology], which lets
+// patterns that move or grow keep stable coordinates.
+type Board struct {
+	width  int
+	height int
+	x      int
+	y      int
+	cells  []uint8
+}
+
+// NewBoard creates a board of the given dimensions with every cell dead. It
+// panics if either dimension is negative.
+func NewBoard(width, height int) *Board {
+	if width < 0 || height < 0 {
+		panic(fmt.Sprintf("life: negative board size %dx%d", width, height))
+	}
+	return &Board{
+		width:  width,
+		height: height,
+		cells:  make([]uint8, width*height),
+	}
+}
+
+// NewBoardFromRows creates a board from rows of text. A '.' is a dead cell
+// and an 'O', '*', or 'A' is a live cell. The letters 'B' through 'Y' are the
+// dying states 2 through 25 of Generations rules. Rows shorter than the
+// longest row are padded with dead cells.
+func NewBoardFromRows(rows []string) (*Board, error) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	b := NewBoard(width, len(rows))
+	for y, row := range rows {
+		for x, c := range []byte(row) {
+			switch {
+			case c == '.':
+			case c == 'O' || c == '*':
+				b.Set(x, y, true)
+			case c >= 'A' && c < 'A'+MaxStates-1:
+				b.SetState(x, y, c-'A'+1)
+			default:
+				return nil, fmt.Errorf("%w: unexpected character %q at %d,%d", ErrInvalidBoard, c, x, y)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Width of the board in cells.
+func (b *Board) Width() int {
+	return b.width
+}
+
+// Height of the board in cells.
+func (b *Board) Height() int {
+	return b.height
+}
+
+// Origin returns the coordinates of the top left cell of the board.
+func (b *Board) Origin() (x, y int) {
+	return b.x, b.y
+}
+
+// SetOrigin sets the coordinates of the top left cell of the board.
+func (b *Board) SetOrigin(x, y int) {
+	b.x, b.y = x, y
+}
+
+// Alive reports whether the cell at x, y is not dead, which includes dying
+// cells. Cells outside of the board are always dead.
+func (b *Board) Alive(x, y int) bool {
+	if !b.contains(x, y) {
+		return false
+	}
+	return b.cells[y*b.width+x] != 0
+}
+
+// Set the cell at x, y to be alive or dead. Setting a cell outside of the
+// board does nothing.
+func (b *Board) Set(x, y int, alive bool) {
+	if !b.contains(x, y) {
+		return
+	}
+	var v uint8
+	if alive {
+		v = 1
+	}
+	b.cells[y*b.width+x] = v
+}
+
+// State returns the state of the cell at x, y. Cells outside of the board
+// are always dead.
+func (b *Board) State(x, y int) uint8 {
+	if !b.contains(x, y) {
+		return 0
+	}
+	return b.cells[y*b.width+x]
+}
+
+// SetState sets the state of the cell at x, y. Setting a cell outside of the
+// board does nothing. It panics if the state is not below [MaxStates].
+func (b *Board) SetState(x, y int, state uint8) {
+	if state >= MaxStates {
+		panic(fmt.Sprintf("life: state %d exceeds the maximum of %d", state, MaxStates-1))
+	}
+	if !b.contains(x, y) {
+		return
+	}
+	b.cells[y*b.width+x] = state
+}
+
+// MaxState returns the highest state of any cell on the board.
+func (b *Board) MaxState() uint8 {
+	var m uint8
+	for _, c := range b.cells {
+		m = max(m, c)
+	}
+	return m
+}
+
+// Population is the number of cells on the board that are not dead,
+// including dying cells.
+func (b *Board) Population() int {
+	n := 0
+	for _, c := range b.cells {
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Clone returns a deep copy of the board.
+func (b *Board) Clone() *Board {
+	c := NewBoard(b.width, b.height)
+	c.x, c.y = b.x, b.y
+	copy(c.cells, b.cells)
+	return c
+}
+
+// Trim returns a copy of the board cropped to the smallest rectangle that
+// contains every live cell. The origin is adjusted so live cells keep their
+// coordinates. An empty board is trimmed to zero width and height.
+func (b *Board) Trim() *Board {
+	minX, minY, maxX, maxY, ok := b.bounds()
+	if !ok {
+		t := NewBoard(0, 0)
+		t.x, t.y = b.x, b.y
+		return t
+	}
+	return b.crop(minX, minY, maxX-minX+1, maxY-minY+1)
+}
+
+// Equal reports whether both boards have the same origin, dimensions, and
+// cells.
+func (b *Board) Equal(o *Board) bool {
+	if b.width != o.width || b.height != o.height || b.x != o.x || b.y != o.y {
+		return false
+	}
+	for i := range b.cells {
+		if b.cells[i] != o.cells[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Rows returns the board as rows of text in the same format accepted by
+// [NewBoardFromRows].
+func (b *Board) Rows() []string {
+	rows := make([]string, b.height)
+	buf := make([]byte, b.width)
+	for y := range rows {
+		for x := range buf {
+			switch state := b.cells[y*b.width+x]; state {
+			case 0:
+				buf[x] = '.'
+			case 1:
+				buf[x] = 'O'
+			default:
+				buf[x] = 'A' + state - 1
+			}
+		}
+		rows[y] = string(buf)
+	}
+	return rows
+}
+
+// String implements the [fmt.Stringer] interface.
+func (b *Board) String() string {
+	return strings.Join(b.Rows(), "\n")
+}
+
+// MarshalBinary implements the [encoding.BinaryMarshaler] interface. The
+// encoding is the width and height as 32-bit big endian integers, the origin
+// as 64-bit big endian integers, and then a byte per cell in row major order.
+func (b *Board) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 24, 24+len(b.cells))
+	binary.BigEndian.PutUint32(data[0:], uint32(b.width))
+	binary.BigEndian.PutUint32(data[4:], uint32(b.height))
+	binary.BigEndian.PutUint64(data[8:], uint64(b.x))
+	binary.BigEndian.PutUint64(data[16:], uint64(b.y))
+	return append(data, b.cells...), nil
+}
+
+// UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface. The
+// origin may be omitted, as it was by earlier versions of the encoding, in
+// which case it is zero.
+func (b *Board) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("%w: short header", ErrInvalidBoard)
+	}
+	width, height := int(binary.BigEndian.Uint32(data[0:])), int(binary.BigEndian.Uint32(data[4:]))
+	var x, y int
+	switch len(data) - width*height {
+	case 8:
+		data = data[8:]
+	case 24:
+		x, y = int(int64(binary.BigEndian.Uint64(data[8:]))), int(int64(binary.BigEndian.Uint64(data[16:])))
+		data = data[24:]
+	default:
+		return fmt.Errorf("%w: expected %d cells", ErrInvalidBoard, width*height)
+	}
+	for i, c := range data {
+		if c >= MaxStates {
+			return fmt.Errorf("%w: cell %d has state %d", ErrInvalidBoard, i, c)
+		}
+	}
+	b.width, b.height, b.x, b.y = width, height, x, y
+	b.cells = append(make([]uint8, 0, width*height), data...)
+	return nil
+}
+
+// bounds returns the smallest rectangle, in board coordinates, that contains
+// every live cell. It is not ok when the board is empty.
+func (b *Board) bounds() (minX, minY, maxX, maxY int, ok bool) {
+	minX, minY, maxX, maxY = b.width, b.height, -1, -1
+	for y := 0; y < b.height; y++ {
+		row := b.cells[y*b.width : (y+1)*b.width]
+		for x, c := range row {
+			if c != 0 {
+				minX, maxX = min(minX, x), max(maxX, x)
+				minY, maxY = min(minY, y), max(maxY, y)
+			}
+		}
+	}
+	return minX, minY, maxX, maxY, maxX >= 0
+}
+
+// crop returns a copy of the width by height rectangle of the board with its
+// top left corner at x, y. The rectangle may extend beyond the board, in
+// which case those cells are dead.
+func (b *Board) crop(x, y, width, height int) *Board {
+	c := NewBoard(width, height)
+	c.x, c.y = b.x+x, b.y+y
+	for cy := 0; cy < height; cy++ {
+		for cx := 0; cx < width; cx++ {
+			c.cells[cy*width+cx] = b.State(x+cx, y+cy)
+		}
+	}
+	return c
+}
+
+// contains reports whether x, y is within the bounds of the board.
+func (b *Board) contains(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.width && y < b.height
+}
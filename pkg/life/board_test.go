@@ -0,0 +1,172 @@
+package life
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewBoard(t *testing.T) {
+	b := NewBoard(3, 2)
+	if diff := cmp.Diff([]int{3, 2, 0}, []int{b.Width(), b.Height(), b.Population()}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected negative size to panic")
+		}
+	}()
+	NewBoard(-1, 1)
+}
+
+func TestNewBoardFromRows(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []string
+		want  []string
+		err   bool
+	}{
+		{name: "empty", input: nil, want: []string{}},
+		{name: "square", input: []string{".O.", "..O", "OOO"}, want: []string{".O.", "..O", "OOO"}},
+		{name: "star", input: []string{"*.", ".*"}, want: []string{"O.", ".O"}},
+		{name: "ragged", input: []string{"O", "..O"}, want: []string{"O..", "..O"}},
+		{name: "states", input: []string{"ABC", ".Y."}, want: []string{"OBC", ".Y."}},
+		{name: "invalid", input: []string{"O?"}, err: true},
+		{name: "invalid state", input: []string{"Z"}, err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewBoardFromRows(tc.input)
+			if tc.err {
+				if !errors.Is(err, ErrInvalidBoard) {
+					t.Fatalf("expected %v, got %v", ErrInvalidBoard, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, b.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBoardAliveSet(t *testing.T) {
+	b := NewBoard(2, 2)
+	b.Set(1, 0, true)
+	b.Set(5, 5, true)
+	b.Set(-1, 0, true)
+
+	cases := []struct {
+		x, y int
+		want bool
+	}{
+		{x: 0, y: 0, want: false},
+		{x: 1, y: 0, want: true},
+		{x: 5, y: 5, want: false},
+		{x: -1, y: 0, want: false},
+	}
+
+	for _, tc := range cases {
+		if got := b.Alive(tc.x, tc.y); got != tc.want {
+			t.Errorf("Alive(%d, %d) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	b.Set(1, 0, false)
+	if b.Population() != 0 {
+		t.Errorf("expected empty board, got:\n%s", b)
+	}
+}
+
+func TestBoardCloneEqual(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{"O.", ".O"})
+	c := b.Clone()
+	if !b.Equal(c) {
+		t.Fatal("expected clone to equal original")
+	}
+	c.Set(0, 0, false)
+	if b.Equal(c) {
+		t.Error("expected modified clone to differ from original")
+	}
+	if b.Equal(NewBoard(3, 2)) {
+		t.Error("expected boards of different sizes to differ")
+	}
+	c = b.Clone()
+	c.SetOrigin(1, 0)
+	if b.Equal(c) {
+		t.Error("expected boards with different origins to differ")
+	}
+}
+
+func TestBoardString(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{"O.", ".O"})
+	if diff := cmp.Diff("O.\n.O", b.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBoardBinary(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	b.SetOrigin(-5, 1<<40)
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := new(Board)
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b.Equal(got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", b, got)
+	}
+
+	// earlier encodings have no origin
+	legacy := new(Board)
+	if err := legacy.UnmarshalBinary([]byte{0, 0, 0, 2, 0, 0, 0, 1, 1, 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"O."}, legacy.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	states, _ := NewBoardFromRows([]string{".OBC"})
+	data, _ = states.MarshalBinary()
+	got = new(Board)
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !states.Equal(got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", states, got)
+	}
+
+	for _, data := range [][]byte{nil, {0, 0, 0, 1, 0, 0, 0, 1}, {0, 0, 0, 1, 0, 0, 0, 1, 26}} {
+		if err := new(Board).UnmarshalBinary(data); !errors.Is(err, ErrInvalidBoard) {
+			t.Errorf("expected %v, got %v", ErrInvalidBoard, err)
+		}
+	}
+}
+
+func TestBoardTrim(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{"....", "..O.", ".O..", "...."})
+	b.SetOrigin(10, -10)
+	got := b.Trim()
+	if diff := cmp.Diff([]string{".O", "O."}, got.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	x, y := got.Origin()
+	if diff := cmp.Diff([]int{11, -9}, []int{x, y}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	empty := NewBoard(3, 3).Trim()
+	if empty.Width() != 0 || empty.Height() != 0 {
+		t.Errorf("expected empty board, got %dx%d", empty.Width(), empty.Height())
+	}
+}
@@ -0,0 +1,98 @@
+package life
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadCells reads a pattern in the plaintext .cells format used by the
+// LifeWiki. Lines starting with '!' are comments, where "!Name:" and
+// "!Author:" give the name and author of the pattern, and the other lines
+// are rows of cells where '.' is dead and 'O' is alive. The format has no
+// rule or position, so the pattern is placed at the origin.
+//
+// It fails with [ErrTooLarge] when the pattern is wider or taller than
+// maxSize.
+func ReadCells(r io.Reader, maxSize int) (*PatternFile, error) {
+	p := &PatternFile{}
+	var rows []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if text, ok := strings.CutPrefix(line, "!"); ok {
+			p.readComment(text)
+			continue
+		}
+		if len(rows) == 0 && line == "" {
+			continue
+		}
+		if strings.Trim(line, ".O*") != "" {
+			return nil, fmt.Errorf("%w: unexpected row %q", ErrInvalidPattern, line)
+		}
+		rows = append(rows, line)
+		if len(rows) > maxSize || len(line) > maxSize {
+			return nil, fmt.Errorf("%w: pattern exceeds %dx%d", ErrTooLarge, maxSize, maxSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	b, err := NewBoardFromRows(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	p.Board = b
+	return p, nil
+}
+
+// readComment reads a comment line of a plaintext pattern, which gives the
+// name or author of the pattern when it starts with "Name:" or "Author:".
+func (p *PatternFile) readComment(text string) {
+	text = strings.TrimSpace(text)
+	if name, ok := strings.CutPrefix(text, "Name:"); ok {
+		p.Name = strings.TrimSpace(name)
+	} else if author, ok := strings.CutPrefix(text, "Author:"); ok {
+		p.Author = strings.TrimSpace(author)
+	} else {
+		p.Comments = append(p.Comments, text)
+	}
+}
+
+// WriteCells writes a pattern in the plaintext .cells format read by
+// [ReadCells]. The format only holds dead and live cells, and has no rule,
+// position, or generation, which are left out.
+func WriteCells(w io.Writer, p *PatternFile) error {
+	if err := checkTwoStates(p.Board); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if p.Name != "" {
+		fmt.Fprintf(bw, "!Name: %s\n", p.Name)
+	}
+	if p.Author != "" {
+		fmt.Fprintf(bw, "!Author: %s\n", p.Author)
+	}
+	for _, comment := range p.Comments {
+		fmt.Fprintf(bw, "!%s\n", comment)
+	}
+	for _, row := range p.Board.Rows() {
+		fmt.Fprintf(bw, "%s\n", row)
+	}
+	return bw.Flush()
+}
+
+// checkTwoStates returns an error wrapping [ErrInvalidPattern] when a board
+// has cells in states other than dead and alive, which formats such as
+// .cells cannot hold.
+func checkTwoStates(b *Board) error {
+	if state := b.MaxState(); state > 1 {
+		return fmt.Errorf("%w: state %d cannot be written in a two-state format", ErrInvalidPattern, state)
+	}
+	return nil
+}
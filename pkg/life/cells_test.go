@@ -0,0 +1,73 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadCells(t *testing.T) {
+	const glider = `!Name: Glider
+!Author: Richard K. Guy
+!The smallest spaceship.
+.O
+..O
+OOO
+
+`
+	p, err := ReadCells(strings.NewReader(glider), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	board, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	want := &PatternFile{
+		Name:     "Glider",
+		Author:   "Richard K. Guy",
+		Comments: []string{"The smallest spaceship."},
+		Board:    board,
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadCellsInvalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		cells string
+		err   error
+	}{
+		{name: "unknown cell", cells: ".O\nxO", err: ErrInvalidPattern},
+		{name: "multistate", cells: ".B", err: ErrInvalidPattern},
+		{name: "too wide", cells: "......", err: ErrTooLarge},
+		{name: "too tall", cells: "O\nO\nO\nO\nO\nO", err: ErrTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ReadCells(strings.NewReader(tc.cells), 5); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestWriteCells(t *testing.T) {
+	board, _ := NewBoardFromRows([]string{".O.", "", "OOO"})
+	p := &PatternFile{Name: "Test", Comments: []string{"A comment."}, Rule: "B3/S23", Board: board}
+	var sb strings.Builder
+	if err := WriteCells(&sb, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "!Name: Test\n!A comment.\n.O.\n...\nOOO\n"
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	wire, _ := NewBoardFromRows([]string{"AB"})
+	if err := WriteCells(&sb, &PatternFile{Board: wire}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected %v, got %v", ErrInvalidPattern, err)
+	}
+}
@@ -0,0 +1,241 @@
+package life
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Fate describes how a pattern that repeats itself ends.
+type Fate int
+
+const (
+	// Extinct patterns have no live cells left.
+	Extinct Fate = iota + 1
+	// Still patterns no longer change from one generation to the next.
+	Still
+	// Oscillating patterns repeat a cycle of more than one generation.
+	Oscillating
+)
+
+// String implements the [fmt.Stringer] interface.
+func (f Fate) String() string {
+	switch f {
+	case Extinct:
+		return "extinct"
+	case Still:
+		return "still"
+	case Oscillating:
+		return "oscillating"
+	default:
+		return "unknown"
+	}
+}
+
+// Cycle is a sequence of generations that a pattern repeats forever. The
+// zero value means no cycle was found.
+type Cycle struct {
+	Fate Fate
+	// Start is the first generation of the cycle, counted from the start of
+	// the run.
+	Start int64
+	// Period is the number of generations in the cycle.
+	Period int64
+}
+
+// Found reports whether a cycle was found.
+func (c Cycle) Found() bool {
+	return c.Period > 0
+}
+
+// Pattern is a set of cells that can be watched for cycles. Patterns that
+// are equal must have the same hash, which includes their position so that
+// a moving spaceship is not mistaken for a cycle.
+type Pattern interface {
+	Hash() uint64
+	Population() int
+}
+
+// Snapshot is a [Pattern] that can be copied and compared, so [Run] can
+// confirm that a generation whose hash was seen before really repeats.
+type Snapshot[P any] interface {
+	Pattern
+	// Clone returns a copy that is not changed by stepping the pattern.
+	Clone() P
+	// Equal reports whether both patterns have the same cells at the same
+	// positions.
+	Equal(P) bool
+}
+
+// maxCyclePeriod is the number of recent generations a [Detector]
+// remembers, and so the longest period of a cycle it can find.
+const maxCyclePeriod = 1 << 16
+
+// Detector finds the first generation of a run whose hash matches that of a
+// recent generation, which is then likely to repeat it. It only remembers
+// the hashes of the last 65536 generations, so memory stays bounded however
+// long the run is, and longer cycles are not found.
+type Detector struct {
+	seen   map[uint64]int64
+	recent [][2]uint64
+}
+
+// NewDetector creates a detector that has not seen any generation.
+func NewDetector() *Detector {
+	return &Detector{seen: make(map[uint64]int64)}
+}
+
+// Observe records a pattern as the given generation of the run, and reports
+// the cycle it completes when it dies or has the hash of a recent
+// generation. Generations must be observed in order.
+func (d *Detector) Observe(gen int64, p Pattern) (Cycle, bool) {
+	if p.Population() == 0 {
+		return Cycle{Fate: Extinct, Start: gen, Period: 1}, true
+	}
+	hash := p.Hash()
+	if start, ok := d.seen[hash]; ok {
+		c := Cycle{Fate: Oscillating, Start: start, Period: gen - start}
+		if c.Period == 1 {
+			c.Fate = Still
+		}
+		return c, true
+	}
+
+	// forget the generation that falls out of the window
+	if len(d.recent) < maxCyclePeriod {
+		d.recent = append(d.recent, [2]uint64{})
+	} else if old := d.recent[gen%maxCyclePeriod]; d.seen[old[0]] == int64(old[1]) {
+		delete(d.seen, old[0])
+	}
+	d.recent[gen%maxCyclePeriod] = [2]uint64{hash, uint64(gen)}
+	d.seen[hash] = gen
+	return Cycle{}, false
+}
+
+// Run advances a pattern by n generations with the step function, one
+// generation at a time. Every generation is observed by a [Detector], and
+// once the pattern dies or repeats a recent generation Run stops stepping
+// through the cycle and only steps as many more generations as it takes to
+// reach the same point of the cycle as the nth generation. A repeat is
+// confirmed by stepping a copy of the pattern through one period and
+// comparing the cells, so a hash collision is never taken for a cycle. The
+// returned cycle is the zero value when none was found.
+//
+// Run stops between generations when the context is cancelled and returns
+// the context error.
+func Run[P Snapshot[P]](ctx context.Context, p P, n int64, step func(P) (P, error)) (P, Cycle, error) {
+	d := NewDetector()
+	cycle, found := d.Observe(0, p)
+	var gen int64
+	for gen < n && !found {
+		if err := ctx.Err(); err != nil {
+			return p, Cycle{}, err
+		}
+		var err error
+		if p, err = step(p); err != nil {
+			return p, Cycle{}, err
+		}
+		gen++
+		if cycle, found = d.Observe(gen, p); found && cycle.Fate != Extinct {
+			next, err := repeats(ctx, p, cycle.Period, step)
+			if err != nil {
+				return p, Cycle{}, err
+			}
+			if next == nil {
+				// the hashes collided without the cells repeating
+				cycle, found = Cycle{}, false
+			} else {
+				p, gen = *next, gen+cycle.Period
+			}
+		}
+	}
+
+	if found {
+		for skip := ((n-gen)%cycle.Period + cycle.Period) % cycle.Period; skip > 0; skip-- {
+			if err := ctx.Err(); err != nil {
+				return p, Cycle{}, err
+			}
+			var err error
+			if p, err = step(p); err != nil {
+				return p, Cycle{}, err
+			}
+		}
+	}
+	return p, cycle, nil
+}
+
+// repeats steps a copy of the pattern by the period and returns the result
+// when it has the same cells as the pattern, or nil when it does not.
+func repeats[P Snapshot[P]](ctx context.Context, p P, period int64, step func(P) (P, error)) (*P, error) {
+	next := p.Clone()
+	for i := int64(0); i < period; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		if next, err = step(next); err != nil {
+			return nil, err
+		}
+	}
+	if !next.Equal(p) {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// Hash returns a hash of the states of the cells that are not dead and their
+// positions on the plane. It does not depend on the dead cells around the
+// pattern, so it is equal to the hash of the same cells packed or sparse.
+func (b *Board) Hash() uint64 {
+	var sum uint64
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if state := b.cells[y*b.width+x]; state != 0 {
+				sum += cellHash(int64(b.x+x), int64(b.y+y), state)
+			}
+		}
+	}
+	return sum
+}
+
+// Hash returns a hash of the origin, dimensions, and cells of the volume.
+func (v *Volume) Hash() uint64 {
+	h := fnv.New64a()
+	var header [36]byte
+	binary.BigEndian.PutUint32(header[0:], uint32(v.width))
+	binary.BigEndian.PutUint32(header[4:], uint32(v.height))
+	binary.BigEndian.PutUint32(header[8:], uint32(v.depth))
+	binary.BigEndian.PutUint64(header[12:], uint64(v.x))
+	binary.BigEndian.PutUint64(header[20:], uint64(v.y))
+	binary.BigEndian.PutUint64(header[28:], uint64(v.z))
+	h.Write(header[:])
+	h.Write(v.cells)
+	return h.Sum64()
+}
+
+// Hash returns a hash of the live cells of the pattern. It does not depend
+// on the order the cells are visited.
+func (s *Sparse) Hash() uint64 {
+	var sum uint64
+	for p := range s.cells {
+		sum += cellHash(p.X, p.Y, 1)
+	}
+	return sum
+}
+
+// cellHash returns the hash of a cell in the given state. Patterns are hashed
+// by summing the hashes of their cells, which does not depend on the order
+// the cells are visited.
+func cellHash(x, y int64, state uint8) uint64 {
+	return mix64(mix64(mix64(uint64(x))^uint64(y)) ^ uint64(state))
+}
+
+// mix64 scrambles the bits of a 64-bit integer with the finalizer of
+// SplitMix64, so that nearby integers have unrelated hashes.
+func mix64(z uint64) uint64 {
+	z ^= z >> 30
+	z *= 0xbf58476d1ce4e5b9
+	z ^= z >> 27
+	z *= 0x94d049bb133111eb
+	return z ^ z>>31
+}
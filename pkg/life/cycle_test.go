@@ -0,0 +1,214 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  string
+		input []string
+		n     int64
+		want  Cycle
+	}{
+		{
+			name:  "extinct",
+			rule:  "B3/S23",
+			input: []string{"O.", ".O"},
+			n:     100,
+			want:  Cycle{Fate: Extinct, Start: 1, Period: 1},
+		},
+		{
+			name:  "still",
+			rule:  "B3/S23",
+			input: []string{"OO.", "O..", "..."},
+			n:     100,
+			want:  Cycle{Fate: Still, Start: 1, Period: 1},
+		},
+		{
+			name:  "blinker odd",
+			rule:  "B3/S23",
+			input: []string{"OOO"},
+			n:     101,
+			want:  Cycle{Fate: Oscillating, Start: 0, Period: 2},
+		},
+		{
+			name:  "blinker even",
+			rule:  "B3/S23",
+			input: []string{"OOO"},
+			n:     100,
+			want:  Cycle{Fate: Oscillating, Start: 0, Period: 2},
+		},
+		{
+			name:  "glider on a torus",
+			rule:  "B3/S23:T8,8",
+			input: []string{".O......", "..O.....", "OOO.....", "........", "........", "........", "........", "........"},
+			n:     1000,
+			want:  Cycle{Fate: Oscillating, Start: 0, Period: 32},
+		},
+		{
+			name:  "glider on a plane",
+			rule:  "B3/S23",
+			input: []string{".O.", "..O", "OOO"},
+			n:     100,
+			want:  Cycle{},
+		},
+		{
+			name:  "too short",
+			rule:  "B3/S23",
+			input: []string{"OOO"},
+			n:     1,
+			want:  Cycle{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, _ := NewBoardFromRows(tc.input)
+			if !r.Topology().Bounded() {
+				b = b.Trim()
+			}
+
+			steps := 0
+			got, cycle, err := Run(context.Background(), b, tc.n, func(b *Board) (*Board, error) {
+				steps++
+				return Step(b, r), nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, cycle); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if want := StepN(b, r, int(tc.n)); !want.Equal(got) {
+				t.Errorf("expected %v, got %v", want.Rows(), got.Rows())
+			}
+			if cycle.Found() && int64(steps) >= tc.n {
+				t.Errorf("expected to stop early, stepped %d generations", steps)
+			}
+		})
+	}
+}
+
+func TestRunSparse(t *testing.T) {
+	b, _ := NewBoardFromRows([]string{"OOO"})
+	got, cycle, err := Run(context.Background(), NewSparse(b), 1001, func(s *Sparse) (*Sparse, error) {
+		return s.Step(Conway)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Cycle{Fate: Oscillating, Start: 0, Period: 2}, cycle); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if !got.Alive(1, -1) || !got.Alive(1, 1) || got.Alive(0, 0) {
+		t.Errorf("expected vertical blinker")
+	}
+}
+
+// collidingBoard is a board whose hash is only its population, so every
+// phase of a glider has the same hash.
+type collidingBoard struct{ *Board }
+
+func (b collidingBoard) Hash() uint64                { return uint64(b.Population()) }
+func (b collidingBoard) Clone() collidingBoard       { return collidingBoard{b.Board.Clone()} }
+func (b collidingBoard) Equal(o collidingBoard) bool { return b.Board.Equal(o.Board) }
+
+func TestRunCollision(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	got, cycle, err := Run(context.Background(), collidingBoard{glider}, 9, func(b collidingBoard) (collidingBoard, error) {
+		return collidingBoard{Step(b.Board, Conway)}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Cycle{}, cycle); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if want := StepN(glider, Conway, 9); !want.Equal(got.Board) {
+		t.Errorf("expected %v, got %v", want.Rows(), got.Rows())
+	}
+}
+
+// countingPattern is a pattern whose hash is its generation, so it never
+// repeats.
+type countingPattern uint64
+
+func (p countingPattern) Hash() uint64    { return uint64(p) }
+func (p countingPattern) Population() int { return 1 }
+
+func TestDetectorWindow(t *testing.T) {
+	d := NewDetector()
+	for gen := int64(0); gen < 3*maxCyclePeriod; gen++ {
+		if _, found := d.Observe(gen, countingPattern(gen)); found {
+			t.Fatalf("unexpected cycle at generation %d", gen)
+		}
+	}
+	if len(d.seen) != maxCyclePeriod {
+		t.Errorf("expected %d remembered generations, got %d", maxCyclePeriod, len(d.seen))
+	}
+
+	// a generation that fell out of the window is no longer matched
+	if _, found := d.Observe(3*maxCyclePeriod, countingPattern(0)); found {
+		t.Errorf("expected generation 0 to be forgotten")
+	}
+	if cycle, found := d.Observe(3*maxCyclePeriod+1, countingPattern(3*maxCyclePeriod-1)); !found || cycle.Period != 2 {
+		t.Errorf("expected a cycle of period 2, got %+v", cycle)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	_, _, err := Run(ctx, b, 10, func(b *Board) (*Board, error) {
+		return Step(b, Conway), nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, _ := NewBoardFromRows([]string{"OO"})
+	b, _ := NewBoardFromRows([]string{"OO"})
+	if a.Hash() != b.Hash() {
+		t.Error("expected equal boards to have equal hashes")
+	}
+	b.SetOrigin(1, 0)
+	if a.Hash() == b.Hash() {
+		t.Error("expected moved boards to have different hashes")
+	}
+
+	s := NewSparse(a)
+	moved := NewSparse(b)
+	if s.Hash() == moved.Hash() || s.Hash() != a.Hash() {
+		t.Error("expected sparse hashes to equal board hashes")
+	}
+
+	padded, _ := NewBoardFromRows([]string{"....", ".OO.", "...."})
+	padded.SetOrigin(-1, -1)
+	p, err := NewPacked(padded, Conway)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if padded.Hash() != a.Hash() || p.Hash() != a.Hash() {
+		t.Error("expected hashes to ignore dead cells")
+	}
+
+	v, _ := NewVolumeFromLayers([][]string{{"O"}})
+	w := v.Clone()
+	w.SetOrigin(0, 0, 1)
+	if v.Hash() == w.Hash() || v.Hash() != v.Clone().Hash() {
+		t.Error("expected volume hashes to depend on origin and cells")
+	}
+}
@@ -0,0 +1,157 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ElementaryRule is one of Wolfram's 256 elementary cellular automata, which
+// evolve a single row of two-state cells. The next state of a cell depends
+// on itself and its left and right neighbours, and the bit of the rule
+// number at the index formed by those three cells gives the new state.
+//
+// An elementary automaton is simulated as a spacetime diagram, a board with
+// a row per generation from top to bottom, so it can be stored and rendered
+// like any other board.
+type ElementaryRule struct {
+	number   uint8
+	topology Topology
+}
+
+// ParseElementaryRule parses a rulestring in Golly notation, such as "W30"
+// or "W110". The rule may be followed by a bounded grid suffix with an
+// infinite height, such as ":T100,0" for a ring of 100 cells or ":P100,0" for
+// a line of 100 cells where cells beyond the ends are dead. Rules that give
+// birth to cells with no neighbours, which are the odd numbers, require a
+// bounded grid. Parsing is case insensitive.
+func ParseElementaryRule(s string) (ElementaryRule, error) {
+	str, grid, bounded := strings.Cut(strings.TrimSpace(s), ":")
+	digits, ok := strings.CutPrefix(strings.ToLower(str), "w")
+	if !ok {
+		return ElementaryRule{}, fmt.Errorf("%w %q: expected W notation", ErrInvalidRule, s)
+	}
+	number, err := strconv.ParseUint(digits, 10, 8)
+	if err != nil || !isDigits(digits) {
+		return ElementaryRule{}, fmt.Errorf("%w %q: rule number must be between 0 and 255", ErrInvalidRule, s)
+	}
+
+	r := ElementaryRule{number: uint8(number)}
+	if bounded {
+		if r.topology, err = parseLine(grid); err != nil {
+			return ElementaryRule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+		}
+	}
+	if r.number&1 != 0 && !r.topology.Bounded() {
+		return ElementaryRule{}, fmt.Errorf("%w %q: odd rules require a bounded grid", ErrInvalidRule, s)
+	}
+	return r, nil
+}
+
+// parseLine parses the bounded grid specifier of an elementary automaton,
+// without the leading colon. Only a bounded plane or a torus with a positive
+// width and an infinite height of zero are supported.
+func parseLine(s string) (Topology, error) {
+	if s == "" {
+		return Topology{}, errors.New("empty bounded grid")
+	}
+	var t Topology
+	switch strings.ToUpper(s[:1]) {
+	case "P":
+		t.Kind = Bounded
+	case "T":
+		t.Kind = Torus
+	default:
+		return Topology{}, fmt.Errorf("unsupported bounded grid %q for a one dimensional rule", s[:1])
+	}
+	width, height, ok := strings.Cut(s[1:], ",")
+	if !ok || height != "0" {
+		return Topology{}, errors.New("expected bounded grid width,0")
+	}
+	if strings.HasSuffix(width, "*") {
+		return Topology{}, errors.New("only a Klein bottle can twist edges")
+	}
+	var err error
+	if t.Width, _, err = parseDimension(width); err != nil {
+		return Topology{}, err
+	}
+	return t, nil
+}
+
+// String returns the canonical Golly form of the rule, including the bounded
+// grid suffix if there is one.
+func (r ElementaryRule) String() string {
+	return "W" + strconv.Itoa(int(r.number)) + r.topology.String()
+}
+
+// Number returns the Wolfram code of the rule.
+func (r ElementaryRule) Number() uint8 {
+	return r.number
+}
+
+// Topology returns the grid the rule is evaluated on. The height of a
+// bounded grid is zero, since the spacetime diagram grows without limit.
+func (r ElementaryRule) Topology() Topology {
+	return r.topology
+}
+
+// next returns the next state of a cell from the states of its left
+// neighbour, itself, and its right neighbour.
+func (r ElementaryRule) next(left, center, right bool) bool {
+	idx := 0
+	for _, alive := range []bool{left, center, right} {
+		idx <<= 1
+		if alive {
+			idx |= 1
+		}
+	}
+	return r.number>>idx&1 != 0
+}
+
+// StepElementary computes the next n generations of a spacetime diagram and
+// returns a new diagram with n more rows. The last row of the board is the
+// current generation. On a bounded grid the board must be as wide as the
+// grid. On an unbounded line the diagram grows to fit its live cells, and is
+// trimmed to the columns that have ever been alive. Dying states are not
+// supported, so any state other than dead is alive.
+func StepElementary(b *Board, r ElementaryRule, n int) *Board {
+	n = max(n, 0)
+	grow := 0
+	if !r.topology.Bounded() {
+		grow = n
+	}
+
+	next := b.crop(-grow, 0, b.width+2*grow, b.height+n)
+	if b.height == 0 {
+		return next
+	}
+	// neighbours beyond the ends of a ring wrap around to the other end
+	alive := func(x, y int) bool {
+		if r.topology.Kind == Torus {
+			x = mod(x, next.width)
+		}
+		return next.Alive(x, y)
+	}
+	for y := b.height; y < next.height; y++ {
+		for x := 0; x < next.width; x++ {
+			next.Set(x, y, r.next(alive(x-1, y-1), alive(x, y-1), alive(x+1, y-1)))
+		}
+	}
+	if r.topology.Bounded() {
+		return next
+	}
+
+	minX, maxX := next.width, -1
+	for y := 0; y < next.height; y++ {
+		for x := 0; x < next.width; x++ {
+			if next.Alive(x, y) {
+				minX, maxX = min(minX, x), max(maxX, x)
+			}
+		}
+	}
+	if maxX < 0 {
+		return next.crop(grow, 0, b.width, next.height)
+	}
+	return next.crop(minX, 0, maxX-minX+1, next.height)
+}
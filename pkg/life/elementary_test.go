@@ -0,0 +1,149 @@
+package life
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseElementaryRule(t *testing.T) {
+	cases := []struct {
+		input  string
+		want   string
+		number uint8
+	}{
+		{input: "W30", want: "W30", number: 30},
+		{input: "w110", want: "W110", number: 110},
+		{input: "W0", want: "W0", number: 0},
+		{input: "W255:T100,0", want: "W255:T100,0", number: 255},
+		{input: "w1:p8,0", want: "W1:P8,0", number: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseElementaryRule(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, r.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.number, r.Number()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseElementaryRuleInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"30",
+		"W",
+		"W256",
+		"W-1",
+		"W+30",
+		"W3x",
+		"W1",
+		"W30:",
+		"W30:T100",
+		"W30:T100,10",
+		"W30:K100*,0",
+		"W30:T0,0",
+		"W30:T100*,0",
+	}
+
+	for _, input := range cases {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseElementaryRule(input); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestStepElementary(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  string
+		input []string
+		n     int
+		x     int
+		want  []string
+	}{
+		{
+			name:  "rule 30",
+			rule:  "W30",
+			input: []string{"O"},
+			n:     3,
+			x:     -3,
+			want:  []string{"...O...", "..OOO..", ".OO..O.", "OO.OOOO"},
+		},
+		{
+			name:  "rule 90",
+			rule:  "W90",
+			input: []string{"O"},
+			n:     3,
+			x:     -3,
+			want:  []string{"...O...", "..O.O..", ".O...O.", "O.O.O.O"},
+		},
+		{
+			name:  "rule 110",
+			rule:  "W110",
+			input: []string{"O"},
+			n:     3,
+			x:     -3,
+			want:  []string{"...O", "..OO", ".OOO", "OO.O"},
+		},
+		{
+			name:  "appended",
+			rule:  "W90",
+			input: []string{".O.", "O.O"},
+			n:     1,
+			x:     -1,
+			want:  []string{"..O..", ".O.O.", "O...O"},
+		},
+		{
+			name:  "ring",
+			rule:  "W90:T5,0",
+			input: []string{"O...."},
+			n:     2,
+			want:  []string{"O....", ".O..O", "..OO."},
+		},
+		{
+			name:  "bounded",
+			rule:  "W1:P3,0",
+			input: []string{"O.."},
+			n:     2,
+			want:  []string{"O..", "..O", "O.."},
+		},
+		{
+			name:  "dies",
+			rule:  "W0",
+			input: []string{"OO"},
+			n:     2,
+			want:  []string{"OO", "..", ".."},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := ParseElementaryRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, _ := NewBoardFromRows(tc.input)
+			got := StepElementary(b, r, tc.n)
+			if diff := cmp.Diff(tc.want, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if x, y := got.Origin(); x != tc.x || y != 0 {
+				t.Errorf("expected origin %d, 0, got %d, %d", tc.x, x, y)
+			}
+			if diff := cmp.Diff(tc.input, b.Rows()); diff != "" {
+				t.Errorf("input modified (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
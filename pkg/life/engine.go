@@ -0,0 +1,297 @@
+package life
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// The names of the engines registered by [NewDefaultRegistry].
+const (
+	EngineNaive    = "naive"
+	EnginePacked   = "packed"
+	EngineSparse   = "sparse"
+	EngineHashLife = "hashlife"
+)
+
+// minTiledCells is the minimum number of cells in a board before the naive
+// and bit-packed engines split each generation into tiles that are stepped
+// in parallel.
+const minTiledCells = 256 * 256
+
+// Engine is an algorithm that advances boards by a number of generations.
+// Engines are interchangeable: every engine that supports a rule computes
+// the same generations of a board.
+type Engine interface {
+	// Check returns an error wrapping [ErrUnsupportedRule] when the engine
+	// cannot advance boards with the rule.
+	Check(r Rule) error
+	// Advance computes the nth generation after the board. On an unbounded
+	// plane the result is trimmed to its live cells, and it fails with
+	// [ErrTooLarge] when it does not fit within a maxSize by maxSize board.
+	// Engines that step every generation fail as soon as the pattern grows
+	// too large.
+	// Engines that watch every generation report the cycle that let them
+	// skip ahead like [Run], and the zero cycle otherwise. Advance stops
+	// early when the context is cancelled and returns the context error.
+	Advance(ctx context.Context, b *Board, r Rule, n int64, maxSize int) (*Board, Cycle, error)
+}
+
+// TreeEngine is an [Engine] that can also advance patterns held by a
+// [Quadtree], which may be far larger than any board.
+type TreeEngine interface {
+	Engine
+	// AdvanceTree computes the nth generation after the quadtree. It stops
+	// early when the context is cancelled and returns the context error.
+	AdvanceTree(ctx context.Context, q *Quadtree, r Rule, n int64) (*Quadtree, error)
+}
+
+// Registry holds engines by name. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	engines map[string]Engine
+}
+
+// NewRegistry creates a registry without any engines.
+func NewRegistry() *Registry {
+	return &Registry{engines: make(map[string]Engine)}
+}
+
+// NewDefaultRegistry creates a registry of the engines of this package:
+// [EngineNaive], [EnginePacked], [EngineSparse], and [EngineHashLife], which
+// is configured with the optional HashLife configuration.
+func NewDefaultRegistry(opts ...HashLifeOption) *Registry {
+	r := NewRegistry()
+	r.Register(EngineNaive, NaiveEngine())
+	r.Register(EnginePacked, PackedEngine())
+	r.Register(EngineSparse, SparseEngine())
+	r.Register(EngineHashLife, HashLifeEngine(opts...))
+	return r
+}
+
+// Register an engine under the given name, replacing any engine already
+// registered under it. It panics if the engine is nil.
+func (r *Registry) Register(name string, e Engine) {
+	if e == nil {
+		panic("life: register nil engine " + name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.engines[name] = e
+}
+
+// Engine returns the engine registered under the given name.
+func (r *Registry) Engine(name string) (Engine, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.engines[name]
+	return e, ok
+}
+
+// Names returns the names of the registered engines in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.engines))
+	for name := range r.engines {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// naiveEngine steps boards cell by cell with [Step].
+type naiveEngine struct{}
+
+// NaiveEngine returns an [Engine] that steps every cell of a board like
+// [Step], watching every generation for cycles. It supports every rule.
+// Large boards are split into tiles that are stepped in parallel.
+func NaiveEngine() Engine {
+	return naiveEngine{}
+}
+
+func (naiveEngine) Check(r Rule) error {
+	return nil
+}
+
+func (naiveEngine) Advance(ctx context.Context, b *Board, r Rule, n int64, maxSize int) (*Board, Cycle, error) {
+	// the workers are started once the board is first large enough to be
+	// tiled, which a growing pattern may only become part way through
+	var pool *tilePool
+	defer func() {
+		if pool != nil {
+			pool.close()
+		}
+	}()
+	b, cycle, err := Run(ctx, b, n, func(b *Board) (*Board, error) {
+		var err error
+		if b.width*b.height >= minTiledCells {
+			if pool == nil {
+				pool = newTilePool(ctx, runtime.GOMAXPROCS(0))
+			}
+			if b, err = b.stepTiled(pool, r); err != nil {
+				return nil, err
+			}
+		} else {
+			b = Step(b, r)
+		}
+		// stop as soon as a growing pattern no longer fits rather than
+		// stepping every generation
+		return b, checkSize(int64(b.width), int64(b.height), maxSize)
+	})
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	return fitBoard(b, cycle, maxSize)
+}
+
+// packedEngine steps boards with [Packed].
+type packedEngine struct{}
+
+// PackedEngine returns an [Engine] that keeps a board bit-packed like
+// [Packed], watching every generation for cycles. It supports two-state
+// totalistic rules. Large boards are split into tiles that are stepped in
+// parallel.
+func PackedEngine() Engine {
+	return packedEngine{}
+}
+
+func (packedEngine) Check(r Rule) error {
+	_, err := newPackedRule(r)
+	return err
+}
+
+func (packedEngine) Advance(ctx context.Context, b *Board, r Rule, n int64, maxSize int) (*Board, Cycle, error) {
+	p, err := NewPacked(b, r)
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	// the workers are started once the board is first large enough to be
+	// tiled, which a growing pattern may only become part way through
+	var pool *tilePool
+	defer func() {
+		if pool != nil {
+			pool.close()
+		}
+	}()
+	p, cycle, err := Run(ctx, p, n, func(p *Packed) (*Packed, error) {
+		width, height := p.g.width, p.g.height
+		if width*height >= minTiledCells {
+			if pool == nil {
+				pool = newTilePool(ctx, runtime.GOMAXPROCS(0))
+			}
+			if err := p.stepTiled(pool); err != nil {
+				return nil, err
+			}
+		} else {
+			p.Step()
+		}
+		// the grid grows to the live cells and a margin whenever they
+		// reach its edge, so check their size each time it does
+		if p.g.width != width || p.g.height != height {
+			return p, checkSize(int64(p.g.width-2*packedMargin), int64(p.g.height-2*packedMargin), maxSize)
+		}
+		return p, nil
+	})
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	return fitBoard(p.Board(), cycle, maxSize)
+}
+
+// sparseEngine steps boards with [Sparse].
+type sparseEngine struct{}
+
+// SparseEngine returns an [Engine] that stores only the live cells of a
+// board like [Sparse], watching every generation for cycles. It supports
+// two-state range 1 rules on an unbounded plane.
+func SparseEngine() Engine {
+	return sparseEngine{}
+}
+
+func (sparseEngine) Check(r Rule) error {
+	return checkSparse(r)
+}
+
+func (sparseEngine) Advance(ctx context.Context, b *Board, r Rule, n int64, maxSize int) (*Board, Cycle, error) {
+	if err := checkSparse(r); err != nil {
+		return nil, Cycle{}, err
+	}
+	s, cycle, err := Run(ctx, NewSparse(b), n, func(s *Sparse) (*Sparse, error) {
+		s, err := s.Step(r)
+		if err != nil {
+			return nil, err
+		}
+		minX, minY, maxX, maxY, ok := s.Bounds()
+		if !ok {
+			return s, nil
+		}
+		return s, checkSize(maxX-minX+1, maxY-minY+1, maxSize)
+	})
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	b, err = s.Board(maxSize)
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	return b, cycle, nil
+}
+
+// hashLifeEngine advances boards with [HashLife].
+type hashLifeEngine struct {
+	opts []HashLifeOption
+}
+
+// HashLifeEngine returns a [TreeEngine] that advances a board or quadtree
+// with a new [HashLife] for each call, configured with the optional
+// configuration. It does not watch for cycles. It supports two-state range 1
+// rules on an unbounded plane.
+func HashLifeEngine(opts ...HashLifeOption) TreeEngine {
+	return hashLifeEngine{opts: opts}
+}
+
+func (e hashLifeEngine) Check(r Rule) error {
+	return checkHashLife(r)
+}
+
+func (e hashLifeEngine) Advance(ctx context.Context, b *Board, r Rule, n int64, maxSize int) (*Board, Cycle, error) {
+	h, err := NewHashLife(r, e.opts...)
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	b, err = h.Advance(ctx, b, n, maxSize)
+	if err != nil {
+		return nil, Cycle{}, err
+	}
+	return b, Cycle{}, nil
+}
+
+func (e hashLifeEngine) AdvanceTree(ctx context.Context, q *Quadtree, r Rule, n int64) (*Quadtree, error) {
+	h, err := NewHashLife(r, e.opts...)
+	if err != nil {
+		return nil, err
+	}
+	return h.AdvanceTree(ctx, q, n)
+}
+
+// fitBoard returns the board and cycle when the board fits within a maxSize
+// by maxSize board, and fails with [ErrTooLarge] otherwise.
+func fitBoard(b *Board, cycle Cycle, maxSize int) (*Board, Cycle, error) {
+	if err := checkSize(int64(b.width), int64(b.height), maxSize); err != nil {
+		return nil, Cycle{}, err
+	}
+	return b, cycle, nil
+}
+
+// checkSize fails with [ErrTooLarge] when a pattern of the size does not fit
+// within a maxSize by maxSize board. Engines check it every generation, so
+// a growing pattern is stopped as soon as it is too large.
+func checkSize(width, height int64, maxSize int) error {
+	if width > int64(maxSize) || height > int64(maxSize) {
+		return fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrTooLarge, width, height, maxSize, maxSize)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEngines(t *testing.T) {
+	rng := rand.New(rand.NewPCG(11, 12))
+	board := randomBoard(rng, 20, 20, 0.4)
+	registry := NewDefaultRegistry()
+	rules := []string{"B3/S23", "B36/S23", "B2-a/S12", "B3/S23:T20,20", "B2/S/C3", "R2,C0,M1,S3..5,B3..4,NM"}
+
+	for _, name := range registry.Names() {
+		engine, _ := registry.Engine(name)
+		for _, rs := range rules {
+			rule, _ := ParseRule(rs)
+			t.Run(name+" "+rs, func(t *testing.T) {
+				if err := engine.Check(rule); err != nil {
+					if !errors.Is(err, ErrUnsupportedRule) {
+						t.Fatalf("expected %v, got %v", ErrUnsupportedRule, err)
+					}
+					if _, _, err := engine.Advance(context.Background(), board, rule, 1, 100); !errors.Is(err, ErrUnsupportedRule) {
+						t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+					}
+					return
+				}
+				got, _, err := engine.Advance(context.Background(), board, rule, 30, 1000)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if want := StepN(board, rule, 30); !want.Equal(got) {
+					t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestEngineCycle(t *testing.T) {
+	blinker, _ := NewBoardFromRows([]string{"OOO"})
+	want, _ := NewBoardFromRows([]string{"O", "O", "O"})
+	want.SetOrigin(1, -1)
+	for _, name := range []string{EngineNaive, EnginePacked, EngineSparse} {
+		t.Run(name, func(t *testing.T) {
+			engine, _ := NewDefaultRegistry().Engine(name)
+			got, cycle, err := engine.Advance(context.Background(), blinker, Conway, 1_000_001, 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+			if diff := cmp.Diff(Cycle{Fate: Oscillating, Start: 0, Period: 2}, cycle); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEngineTooLarge(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	registry := NewDefaultRegistry()
+	for _, name := range registry.Names() {
+		t.Run(name, func(t *testing.T) {
+			engine, _ := registry.Engine(name)
+			if _, _, err := engine.Advance(context.Background(), glider, Conway, 1, 2); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("expected %v, got %v", ErrTooLarge, err)
+			}
+		})
+	}
+}
+
+// TestEngineGrowth advances a pattern that grows without end by far more
+// generations than could ever be stepped, so the engines that step every
+// generation must stop as soon as it no longer fits.
+func TestEngineGrowth(t *testing.T) {
+	rule, _ := ParseRule("B1/S")
+	cell, _ := NewBoardFromRows([]string{"O"})
+	registry := NewDefaultRegistry()
+	for _, name := range []string{EngineNaive, EnginePacked, EngineSparse} {
+		t.Run(name, func(t *testing.T) {
+			engine, _ := registry.Engine(name)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if _, _, err := engine.Advance(ctx, cell, rule, 1<<40, 256); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("expected %v, got %v", ErrTooLarge, err)
+			}
+		})
+	}
+}
+
+// TestEngineTiledGrowth advances a pattern that starts smaller than a tiled
+// board and grows beyond one, so it is stepped in tiles part way through.
+func TestEngineTiledGrowth(t *testing.T) {
+	rule, _ := ParseRule("B1/S")
+	cell, _ := NewBoardFromRows([]string{"O"})
+	want := StepN(cell, rule, 160)
+	if want.Width()*want.Height() < minTiledCells {
+		t.Fatalf("expected at least %d cells, got %dx%d", minTiledCells, want.Width(), want.Height())
+	}
+
+	registry := NewDefaultRegistry()
+	for _, name := range []string{EngineNaive, EnginePacked} {
+		t.Run(name, func(t *testing.T) {
+			engine, _ := registry.Engine(name)
+			got, _, err := engine.Advance(context.Background(), cell, rule, 160, 1024)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Engine(EngineNaive); ok {
+		t.Error("expected an empty registry")
+	}
+	r.Register("slow", NaiveEngine())
+	r.Register("fast", PackedEngine())
+	if diff := cmp.Diff([]string{"fast", "slow"}, r.Names()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if _, ok := r.Engine("fast"); !ok {
+		t.Error("expected a registered engine")
+	}
+
+	want := []string{EngineHashLife, EngineNaive, EnginePacked, EngineSparse}
+	if diff := cmp.Diff(want, NewDefaultRegistry().Names()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
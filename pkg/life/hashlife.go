@@ -0,0 +1,559 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/bits"
+
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+var (
+	// ErrUnsupportedRule when an algorithm cannot simulate the given rule.
+	ErrUnsupportedRule = errors.New("unsupported rule")
+	// ErrMemoryLimit when a simulation needs more memory than it is allowed.
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+	// ErrTooLarge when a pattern is too large to be converted into a board.
+	ErrTooLarge = errors.New("pattern too large")
+)
+
+const (
+	// defaultHashLifeMemory is the default memory limit of a [HashLife].
+	defaultHashLifeMemory = 256 << 20
+	// hashLifeNodeSize is the approximate number of bytes used by a node,
+	// including its entry in the node cache.
+	hashLifeNodeSize = 128
+	// hashLifeCheckInterval is the number of new nodes created between
+	// checks of the context and the memory limit.
+	hashLifeCheckInterval = 1 << 12
+)
+
+// HashLifeOption configures a [HashLife] by overriding a default setting.
+type HashLifeOption func(*HashLife)
+
+// WithMaxMemory sets the approximate number of bytes the node cache may use.
+// When the limit is reached the cache is collected, keeping only the nodes of
+// the current pattern. A zero or negative value means the default is used.
+func WithMaxMemory(bytes int) HashLifeOption {
+	return func(h *HashLife) {
+		if bytes > 0 {
+			h.maxNodes = max(bytes/hashLifeNodeSize, 1)
+		}
+	}
+}
+
+// HashLifeStats describes the use of the caches of a [HashLife].
+type HashLifeStats struct {
+	// Nodes is the number of nodes in the cache.
+	Nodes int
+	// MaxNodes is the number of nodes allowed in the cache.
+	MaxNodes int
+	// NodeHits counts lookups that found an existing node.
+	NodeHits uint64
+	// NodeMisses counts lookups that created a new node.
+	NodeMisses uint64
+	// ResultHits counts successor computations that were memoized.
+	ResultHits uint64
+	// ResultMisses counts successor computations that were not memoized.
+	ResultMisses uint64
+	// Collections counts how many times the cache was collected.
+	Collections int
+}
+
+// HitRate is the fraction of successor computations that were memoized.
+func (s HashLifeStats) HitRate() float64 {
+	total := s.ResultHits + s.ResultMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.ResultHits) / float64(total)
+}
+
+// LogValue implements the [slog.LogValuer] interface.
+func (s HashLifeStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("nodes", s.Nodes),
+		slog.Int("maxNodes", s.MaxNodes),
+		slog.Uint64("nodeHits", s.NodeHits),
+		slog.Uint64("nodeMisses", s.NodeMisses),
+		slog.Uint64("resultHits", s.ResultHits),
+		slog.Uint64("resultMisses", s.ResultMisses),
+		slog.Float64("hitRate", s.HitRate()),
+		slog.Int("collections", s.Collections),
+	)
+}
+
+// node is a hash-consed quadtree node. A node of level k is a square of 2^k
+// cells. Level 0 nodes are single cells and have no children. Two nodes with
+// the same contents are always the same pointer.
+type node struct {
+	nw, ne, sw, se *node
+	level          int
+	population     uint64
+	// next is the center of the node advanced 2^(level-2) generations.
+	next *node
+}
+
+// quad is the key of a node in the node cache.
+type quad struct {
+	nw, ne, sw, se *node
+}
+
+// stepKey is the key of a memoized successor advanced fewer generations
+// than the maximum for the node.
+type stepKey struct {
+	n *node
+	j int
+}
+
+// hashLifeAbort is panicked from deep within a computation to unwind it.
+type hashLifeAbort struct {
+	err error
+}
+
+// HashLife simulates a rule using Bill Gosper's HashLife algorithm. Patterns
+// are stored as quadtrees whose nodes are shared and whose futures are
+// memoized, which lets regular patterns be advanced by astronomically many
+// generations.
+//
+// HashLife only supports two-state range 1 rules on an unbounded [Plane]. It
+// is not safe for concurrent use.
+type HashLife struct {
+	rule     Rule
+	maxNodes int
+	ctx      context.Context
+	nodes    map[quad]*node
+	steps    map[stepKey]*node
+	on, off  *node
+	empty    []*node
+	stats    HashLifeStats
+}
+
+// NewHashLife creates a [HashLife] for the given rule with optional
+// configuration.
+func NewHashLife(rule Rule, opts ...HashLifeOption) (*HashLife, error) {
+	if err := checkHashLife(rule); err != nil {
+		return nil, err
+	}
+
+	h := &HashLife{
+		rule:     rule,
+		maxNodes: defaultHashLifeMemory / hashLifeNodeSize,
+		ctx:      context.Background(),
+		on:       &node{population: 1},
+		off:      &node{},
+	}
+	h.reset()
+
+	// apply optional configuration
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+// checkHashLife returns an error wrapping [ErrUnsupportedRule] when HashLife
+// cannot simulate the rule.
+func checkHashLife(rule Rule) error {
+	if rule.Topology().Bounded() {
+		return fmt.Errorf("%w: hashlife requires an unbounded plane", ErrUnsupportedRule)
+	}
+	if rule.States() > 2 || rule.Range() > 1 {
+		return fmt.Errorf("%w: hashlife requires a two-state range 1 rule", ErrUnsupportedRule)
+	}
+	return nil
+}
+
+// Stats returns the current use of the caches.
+func (h *HashLife) Stats() HashLifeStats {
+	s := h.stats
+	s.Nodes, s.MaxNodes = len(h.nodes), h.maxNodes
+	return s
+}
+
+// Advance computes the nth generation after the board. The result is trimmed
+// to its live cells and must fit within a maxSize by maxSize board. Advance
+// stops early if the context is cancelled. The cache statistics are logged
+// with the logger from the context.
+func (h *HashLife) Advance(ctx context.Context, b *Board, n int64, maxSize int) (*Board, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("life: negative generations %d", n)
+	}
+	h.ctx = ctx
+	defer func() { h.ctx = context.Background() }()
+
+	root, x, y := h.fromBoard(b)
+	root, x, y, err := h.advance(root, x, y, uint64(n))
+	logger := logging.FromContext(ctx)
+	logger.Info("hashlife advance", slog.Int64("generations", n), slog.Any("stats", h.Stats()))
+	if err != nil {
+		return nil, err
+	}
+	return nodeBoard(root, x, y, maxSize)
+}
+
+// advance the root node, with its top left cell at x, y, by n generations.
+// Generations are advanced in the largest powers of two first.
+func (h *HashLife) advance(root *node, x, y int64, n uint64) (*node, int64, int64, error) {
+	for n > 0 {
+		j := 63 - bits.LeadingZeros64(n)
+		next, nx, ny, err := h.step(root, x, y, j)
+		if errors.Is(err, ErrMemoryLimit) {
+			// the cache filled up part way through, so collect it and try
+			// again with only the current pattern cached
+			h.collect(root)
+			next, nx, ny, err = h.step(root, x, y, j)
+		}
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		root, x, y = next, nx, ny
+		n -= 1 << j
+		if len(h.nodes) > h.maxNodes/2 {
+			h.collect(root)
+		}
+	}
+	return root, x, y, nil
+}
+
+// step advances the root node by 2^j generations. The root is first expanded
+// so the pattern cannot grow beyond the center of the node in that time,
+// failing with [ErrTooLarge] if it would need more than [maxTreeLevel] levels
+// for its coordinates to fit in 64 bits.
+func (h *HashLife) step(root *node, x, y int64, j int) (next *node, nx, ny int64, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			abort, ok := p.(hashLifeAbort)
+			if !ok {
+				panic(p)
+			}
+			next, nx, ny, err = nil, 0, 0, abort.err
+		}
+	}()
+
+	for root.level < j+2 || !h.centered(root) {
+		// leave room for the final expansion below
+		if root.level >= maxTreeLevel-1 {
+			return nil, 0, 0, fmt.Errorf("%w: pattern exceeds level %d", ErrTooLarge, maxTreeLevel)
+		}
+		root, x, y = h.expand(root, x, y)
+	}
+	root, x, y = h.expand(root, x, y)
+
+	offset := int64(1) << (root.level - 2)
+	return h.successor(root, j), x + offset, y + offset, nil
+}
+
+// successor returns the center of a node, of half its size, advanced by 2^j
+// generations, where j is at most the node level minus two.
+func (h *HashLife) successor(n *node, j int) *node {
+	if n.population == 0 {
+		return h.emptyNode(n.level - 1)
+	}
+	if n.level == 2 {
+		return h.base(n)
+	}
+
+	full := j == n.level-2
+	if full && n.next != nil {
+		h.stats.ResultHits++
+		return n.next
+	}
+	if !full {
+		if r, ok := h.steps[stepKey{n, j}]; ok {
+			h.stats.ResultHits++
+			return r
+		}
+	}
+	h.stats.ResultMisses++
+
+	// nine overlapping subnodes of half the size
+	n00 := n.nw
+	n01 := h.join(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw)
+	n02 := n.ne
+	n10 := h.join(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne)
+	n11 := h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+	n12 := h.join(n.ne.sw, n.ne.se, n.se.nw, n.se.ne)
+	n20 := n.sw
+	n21 := h.join(n.sw.ne, n.se.nw, n.sw.se, n.se.sw)
+	n22 := n.se
+
+	var r *node
+	if full {
+		// advance each subnode by half the generations, then advance the
+		// four overlapping combinations of those by the other half
+		c00, c01, c02 := h.successor(n00, j-1), h.successor(n01, j-1), h.successor(n02, j-1)
+		c10, c11, c12 := h.successor(n10, j-1), h.successor(n11, j-1), h.successor(n12, j-1)
+		c20, c21, c22 := h.successor(n20, j-1), h.successor(n21, j-1), h.successor(n22, j-1)
+		r = h.join(
+			h.successor(h.join(c00, c01, c10, c11), j-1),
+			h.successor(h.join(c01, c02, c11, c12), j-1),
+			h.successor(h.join(c10, c11, c20, c21), j-1),
+			h.successor(h.join(c11, c12, c21, c22), j-1),
+		)
+		n.next = r
+	} else {
+		// advance each subnode by all of the generations, then assemble the
+		// center from the inner quadrants of the results
+		c00, c01, c02 := h.successor(n00, j), h.successor(n01, j), h.successor(n02, j)
+		c10, c11, c12 := h.successor(n10, j), h.successor(n11, j), h.successor(n12, j)
+		c20, c21, c22 := h.successor(n20, j), h.successor(n21, j), h.successor(n22, j)
+		r = h.join(
+			h.join(c00.se, c01.sw, c10.ne, c11.nw),
+			h.join(c01.se, c02.sw, c11.ne, c12.nw),
+			h.join(c10.se, c11.sw, c20.ne, c21.nw),
+			h.join(c11.se, c12.sw, c21.ne, c22.nw),
+		)
+		h.steps[stepKey{n, j}] = r
+	}
+	return r
+}
+
+// base advances the center 2x2 cells of a level 2 node by one generation.
+func (h *HashLife) base(n *node) *node {
+	var cells [4][4]bool
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			cells[y][x] = cell(n, x, y)
+		}
+	}
+
+	var out [4]*node
+	for i := range out {
+		cx, cy := 1+i%2, 1+i/2
+		idx := 0
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if cells[cy+dy][cx+dx] {
+					idx |= 1 << ((dy+1)*3 + dx + 1)
+				}
+			}
+		}
+		out[i] = h.leaf(h.rule.next(idx))
+	}
+	return h.join(out[0], out[1], out[2], out[3])
+}
+
+// join returns the node with the given quadrants, creating it if it does not
+// already exist.
+func (h *HashLife) join(nw, ne, sw, se *node) *node {
+	key := quad{nw, ne, sw, se}
+	if n, ok := h.nodes[key]; ok {
+		h.stats.NodeHits++
+		return n
+	}
+	h.stats.NodeMisses++
+	if h.stats.NodeMisses%hashLifeCheckInterval == 0 {
+		if err := h.ctx.Err(); err != nil {
+			panic(hashLifeAbort{err: err})
+		}
+		if len(h.nodes) >= h.maxNodes {
+			panic(hashLifeAbort{err: ErrMemoryLimit})
+		}
+	}
+
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level:      nw.level + 1,
+		population: saturatingAdd(saturatingAdd(nw.population, ne.population), saturatingAdd(sw.population, se.population)),
+	}
+	h.nodes[key] = n
+	return n
+}
+
+// leaf returns the level 0 node for a live or dead cell.
+func (h *HashLife) leaf(alive bool) *node {
+	if alive {
+		return h.on
+	}
+	return h.off
+}
+
+// emptyNode returns the node of the given level with no live cells.
+func (h *HashLife) emptyNode(level int) *node {
+	for len(h.empty) <= level {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+	return h.empty[level]
+}
+
+// expand returns a node of the next level with the given node at its center
+// along with the coordinates of its top left cell.
+func (h *HashLife) expand(n *node, x, y int64) (*node, int64, int64) {
+	e := h.emptyNode(n.level - 1)
+	offset := int64(1) << (n.level - 1)
+	return h.join(
+		h.join(e, e, e, n.nw),
+		h.join(e, e, n.ne, e),
+		h.join(e, n.sw, e, e),
+		h.join(n.se, e, e, e),
+	), x - offset, y - offset
+}
+
+// centered reports whether every live cell of the node is within its center
+// half.
+func (h *HashLife) centered(n *node) bool {
+	if n.level < 2 {
+		return n.population == 0
+	}
+	return n.nw.population == n.nw.se.population &&
+		n.ne.population == n.ne.sw.population &&
+		n.sw.population == n.sw.ne.population &&
+		n.se.population == n.se.nw.population
+}
+
+// collect drops every cached node and memoized result except for the nodes
+// of the given root.
+func (h *HashLife) collect(root *node) {
+	h.reset()
+	var keep func(n *node)
+	keep = func(n *node) {
+		if n.level == 0 {
+			return
+		}
+		n.next = nil
+		key := quad{n.nw, n.ne, n.sw, n.se}
+		if _, ok := h.nodes[key]; ok {
+			return
+		}
+		h.nodes[key] = n
+		keep(n.nw)
+		keep(n.ne)
+		keep(n.sw)
+		keep(n.se)
+	}
+	keep(root)
+	h.stats.Collections++
+}
+
+// reset empties the caches.
+func (h *HashLife) reset() {
+	h.nodes = make(map[quad]*node)
+	h.steps = make(map[stepKey]*node)
+	h.empty = []*node{h.off}
+}
+
+// fromBoard builds a node containing every cell of the board and returns it
+// along with the coordinates of its top left cell.
+func (h *HashLife) fromBoard(b *Board) (*node, int64, int64) {
+	level := 3
+	for 1<<level < max(b.width, b.height) {
+		level++
+	}
+
+	var build func(x, y, level int) *node
+	build = func(x, y, level int) *node {
+		if x >= b.width || y >= b.height {
+			return h.emptyNode(level)
+		}
+		if level == 0 {
+			return h.leaf(b.cells[y*b.width+x] != 0)
+		}
+		half := 1 << (level - 1)
+		return h.join(
+			build(x, y, level-1),
+			build(x+half, y, level-1),
+			build(x, y+half, level-1),
+			build(x+half, y+half, level-1),
+		)
+	}
+	return build(0, 0, level), int64(b.x), int64(b.y)
+}
+
+// nodeBoard converts a node with its top left cell at x, y into a board
+// trimmed to its live cells.
+func nodeBoard(root *node, x, y int64, maxSize int) (*Board, error) {
+	r, ok := nodeBounds(root, make(map[*node]rect))
+	if !ok {
+		return NewBoard(0, 0), nil
+	}
+	width, height := r.maxX-r.minX+1, r.maxY-r.minY+1
+	if width > int64(maxSize) || height > int64(maxSize) {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrTooLarge, width, height, maxSize, maxSize)
+	}
+
+	b := NewBoard(int(width), int(height))
+	b.x, b.y = int(x+r.minX), int(y+r.minY)
+	var paint func(n *node, x, y int64)
+	paint = func(n *node, x, y int64) {
+		switch {
+		case n.population == 0:
+		case n.level == 0:
+			b.cells[(y-r.minY)*width+x-r.minX] = 1
+		default:
+			half := int64(1) << (n.level - 1)
+			paint(n.nw, x, y)
+			paint(n.ne, x+half, y)
+			paint(n.sw, x, y+half)
+			paint(n.se, x+half, y+half)
+		}
+	}
+	paint(root, 0, 0)
+	return b, nil
+}
+
+// rect is an inclusive rectangle of cells.
+type rect struct {
+	minX, minY, maxX, maxY int64
+}
+
+// nodeBounds returns the smallest rectangle, relative to the top left cell
+// of the node, containing every live cell. It is not ok when the node is
+// empty. Results are memoized in seen, since shared nodes have the same
+// bounds.
+func nodeBounds(n *node, seen map[*node]rect) (rect, bool) {
+	if n.population == 0 {
+		return rect{}, false
+	}
+	if n.level == 0 {
+		return rect{}, true
+	}
+	if r, ok := seen[n]; ok {
+		return r, true
+	}
+
+	half := int64(1) << (n.level - 1)
+	r := rect{math.MaxInt64, math.MaxInt64, math.MinInt64, math.MinInt64}
+	for i, q := range [4]*node{n.nw, n.ne, n.sw, n.se} {
+		qr, ok := nodeBounds(q, seen)
+		if !ok {
+			continue
+		}
+		dx, dy := int64(i%2)*half, int64(i/2)*half
+		r.minX, r.maxX = min(r.minX, qr.minX+dx), max(r.maxX, qr.maxX+dx)
+		r.minY, r.maxY = min(r.minY, qr.minY+dy), max(r.maxY, qr.maxY+dy)
+	}
+	seen[n] = r
+	return r, true
+}
+
+// cell reports whether the cell at x, y of the node is alive.
+func cell(n *node, x, y int) bool {
+	for n.level > 0 {
+		half := 1 << (n.level - 1)
+		switch {
+		case x < half && y < half:
+			n = n.nw
+		case y < half:
+			n, x = n.ne, x-half
+		case x < half:
+			n, y = n.sw, y-half
+		default:
+			n, x, y = n.se, x-half, y-half
+		}
+	}
+	return n.population != 0
+}
+
+// saturatingAdd adds two populations, saturating at the maximum uint64
+// rather than overflowing.
+func saturatingAdd(a, b uint64) uint64 {
+	if s := a + b; s >= a {
+		return s
+	}
+	return math.MaxUint64
+}
@@ -0,0 +1,134 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHashLifeAdvance(t *testing.T) {
+	highLife, _ := ParseRule("B36/S23")
+	hexagonal, _ := ParseRule("B2/S34H")
+	soup := NewBoard(16, 16)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			soup.Set(x, y, rng.IntN(2) == 0)
+		}
+	}
+	soup.SetOrigin(-3, 5)
+
+	cases := []struct {
+		name  string
+		rule  Rule
+		board *Board
+		n     int64
+	}{
+		{name: "zero", rule: Conway, board: soup, n: 0},
+		{name: "one", rule: Conway, board: soup, n: 1},
+		{name: "odd", rule: Conway, board: soup, n: 37},
+		{name: "many", rule: Conway, board: soup, n: 300},
+		{name: "highlife", rule: highLife, board: soup, n: 100},
+		{name: "hexagonal", rule: hexagonal, board: soup, n: 100},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewHashLife(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := h.Advance(context.Background(), tc.board, tc.n, 1024)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := StepN(tc.board, tc.rule, int(tc.n)).Trim()
+			if !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestHashLifeGlider(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	h, err := NewHashLife(Conway)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a glider moves one cell diagonally every four generations
+	got, err := h.Advance(context.Background(), glider, 1<<40, 16)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x, y := got.Origin()
+	if diff := cmp.Diff([]int{1 << 38, 1 << 38}, []int{x, y}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(glider.Rows(), got.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if stats := h.Stats(); stats.ResultHits == 0 || stats.HitRate() <= 0 {
+		t.Errorf("expected memoized results, got %+v", stats)
+	}
+}
+
+func TestHashLifeMaxMemory(t *testing.T) {
+	soup := NewBoard(32, 32)
+	rng := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 400; i++ {
+		soup.Set(rng.IntN(32), rng.IntN(32), true)
+	}
+
+	h, err := NewHashLife(Conway, WithMaxMemory(64*hashLifeNodeSize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := h.Advance(context.Background(), soup, 20, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := StepN(soup, Conway, 20).Trim(); !want.Equal(got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if h.Stats().Collections == 0 {
+		t.Error("expected the cache to be collected")
+	}
+}
+
+func TestHashLifeErrors(t *testing.T) {
+	bounded, _ := ParseRule("B3/S23:T10,10")
+	if _, err := NewHashLife(bounded); !errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+	}
+
+	// the R-pentomino grows beyond a small board
+	r, _ := NewBoardFromRows([]string{".OO", "OO.", ".O."})
+	h, _ := NewHashLife(Conway)
+	if _, err := h.Advance(context.Background(), r, 1000, 16); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+
+	// a glider travels beyond 64-bit coordinates
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	if _, err := h.Advance(context.Background(), glider, math.MaxInt64, 16); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	soup := NewBoard(64, 64)
+	rng := rand.New(rand.NewPCG(5, 6))
+	for i := 0; i < 2000; i++ {
+		soup.Set(rng.IntN(64), rng.IntN(64), true)
+	}
+	h, _ = NewHashLife(Conway)
+	if _, err := h.Advance(ctx, soup, 1<<20, 1<<20); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
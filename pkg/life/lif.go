@@ -0,0 +1,259 @@
+package life
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// life105Header is the first line of a Life 1.05 file.
+	life105Header = "#Life 1.05"
+	// life106Header is the first line of a Life 1.06 file.
+	life106Header = "#Life 1.06"
+	// life105LineWidth is the maximum number of cells in a row of a block of
+	// a Life 1.05 file.
+	life105LineWidth = 80
+)
+
+var (
+	// life105Rule matches the survival/birth rules of Life 1.05 files.
+	life105Rule = regexp.MustCompile(`^([0-8]*)/([0-8]*)$`)
+	// bsRule matches the B/S rulestrings that can be written to Life 1.05
+	// files.
+	bsRule = regexp.MustCompile(`^B([0-8]*)/S([0-8]*)$`)
+)
+
+// ReadLife105 reads a pattern in the Life 1.05 format. After the "#Life
+// 1.05" header, "#D" lines describe the pattern, where "Name:" and
+// "Author:" give its name and author, "#N" selects the rule B3/S23, and "#R"
+// gives a rule in survival/birth order such as "23/3". Each "#P x y" line
+// starts a block of rows with its top left cell at x, y, where '.' is dead
+// and '*' is alive.
+//
+// It fails with [ErrTooLarge] when the pattern is wider or taller than
+// maxSize.
+func ReadLife105(r io.Reader, maxSize int) (*PatternFile, error) {
+	p := &PatternFile{}
+	scanner := bufio.NewScanner(r)
+	if err := readLifeHeader(scanner, life105Header); err != nil {
+		return nil, err
+	}
+
+	var cells [][2]int
+	block := false
+	x, y := 0, 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		tag, text, _ := strings.Cut(line, " ")
+		switch {
+		case tag == "#D" || tag == "#C":
+			p.readComment(text)
+		case tag == "#N":
+			p.Rule = Conway.String()
+		case tag == "#R":
+			p.Rule = life105RuleString(strings.TrimSpace(text))
+		case tag == "#P":
+			if _, err := fmt.Sscan(text, &x, &y); err != nil {
+				return nil, fmt.Errorf("%w: expected #P x y, got %q", ErrInvalidPattern, line)
+			}
+			block = true
+		case strings.HasPrefix(line, "#"):
+		case line == "" && !block:
+		default:
+			if !block {
+				return nil, fmt.Errorf("%w: expected #P before row %q", ErrInvalidPattern, line)
+			}
+			for i, c := range []byte(line) {
+				switch c {
+				case '.':
+				case '*', 'O':
+					cells = append(cells, [2]int{x + i, y})
+				default:
+					return nil, fmt.Errorf("%w: unexpected cell %q in row %q", ErrInvalidPattern, c, line)
+				}
+			}
+			y++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.Board, _ = boardFromCells(cells, maxSize); p.Board == nil {
+		return nil, fmt.Errorf("%w: pattern exceeds %dx%d", ErrTooLarge, maxSize, maxSize)
+	}
+	return p, nil
+}
+
+// life105RuleString converts a survival/birth rule of a Life 1.05 file into
+// a B/S rulestring. Other rules are kept as they are.
+func life105RuleString(s string) string {
+	m := life105Rule.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+	return "B" + m[2] + "/S" + m[1]
+}
+
+// ReadLife106 reads a pattern in the Life 1.06 format: the "#Life 1.06"
+// header followed by the x and y coordinates of every live cell, one cell
+// per line. The format has no metadata, but "#D" lines are read like those
+// of Life 1.05 files.
+//
+// It fails with [ErrTooLarge] when the pattern is wider or taller than
+// maxSize.
+func ReadLife106(r io.Reader, maxSize int) (*PatternFile, error) {
+	p := &PatternFile{}
+	scanner := bufio.NewScanner(r)
+	if err := readLifeHeader(scanner, life106Header); err != nil {
+		return nil, err
+	}
+
+	var cells [][2]int
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if text, ok := strings.CutPrefix(line, "#D"); ok {
+			p.readComment(text)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var x, y int
+		if _, err := fmt.Sscan(line, &x, &y); err != nil {
+			return nil, fmt.Errorf("%w: expected x y, got %q", ErrInvalidPattern, line)
+		}
+		cells = append(cells, [2]int{x, y})
+		if len(cells) > maxSize*maxSize {
+			return nil, fmt.Errorf("%w: pattern exceeds %dx%d", ErrTooLarge, maxSize, maxSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.Board, _ = boardFromCells(cells, maxSize); p.Board == nil {
+		return nil, fmt.Errorf("%w: pattern exceeds %dx%d", ErrTooLarge, maxSize, maxSize)
+	}
+	return p, nil
+}
+
+// readLifeHeader reads the header line of a Life 1.05 or 1.06 file, skipping
+// any blank lines before it.
+func readLifeHeader(scanner *bufio.Scanner, header string) error {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line != header {
+			return fmt.Errorf("%w: expected %q, got %q", ErrInvalidPattern, header, line)
+		}
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: expected %q", ErrInvalidPattern, header)
+}
+
+// boardFromCells creates the smallest board holding the live cells at the
+// given coordinates, with its origin at its top left cell. It is not ok when
+// the board would be wider or taller than maxSize.
+func boardFromCells(cells [][2]int, maxSize int) (*Board, bool) {
+	if len(cells) == 0 {
+		return NewBoard(0, 0), true
+	}
+	minX, minY, maxX, maxY := cells[0][0], cells[0][1], cells[0][0], cells[0][1]
+	for _, c := range cells[1:] {
+		minX, minY = min(minX, c[0]), min(minY, c[1])
+		maxX, maxY = max(maxX, c[0]), max(maxY, c[1])
+	}
+	width, height := maxX-minX+1, maxY-minY+1
+	if width > maxSize || height > maxSize {
+		return nil, false
+	}
+	b := NewBoard(width, height)
+	b.x, b.y = minX, minY
+	for _, c := range cells {
+		b.cells[(c[1]-minY)*width+c[0]-minX] = 1
+	}
+	return b, true
+}
+
+// WriteLife105 writes a pattern in the Life 1.05 format read by
+// [ReadLife105]. The name, author, and comments are written as "#D" lines,
+// and B/S rules are converted to the survival/birth order of the format.
+// The board is written in blocks of at most 80 columns, and the generation
+// is left out.
+func WriteLife105(w io.Writer, p *PatternFile) error {
+	if err := checkTwoStates(p.Board); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n", life105Header)
+	writeLifeComments(bw, p)
+	switch m := bsRule.FindStringSubmatch(p.Rule); {
+	case p.Rule == Conway.String():
+		fmt.Fprintf(bw, "#N\n")
+	case m != nil:
+		fmt.Fprintf(bw, "#R %s/%s\n", m[2], m[1])
+	case p.Rule != "":
+		fmt.Fprintf(bw, "#R %s\n", p.Rule)
+	}
+
+	b := p.Board
+	rows := b.Rows()
+	for left := 0; left < b.width; left += life105LineWidth {
+		right := min(left+life105LineWidth, b.width)
+		fmt.Fprintf(bw, "#P %d %d\n", b.x+left, b.y)
+		for y := 0; y < b.height; y++ {
+			row := []byte(strings.TrimRight(rows[y][left:right], "."))
+			for i, c := range row {
+				if c == 'O' {
+					row[i] = '*'
+				}
+			}
+			if len(row) == 0 {
+				row = []byte{'.'}
+			}
+			fmt.Fprintf(bw, "%s\n", row)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteLife106 writes a pattern in the Life 1.06 format read by
+// [ReadLife106], with the coordinates of its live cells in row major order.
+// The name, author, and comments are written as "#D" lines, and the rule and
+// generation are left out.
+func WriteLife106(w io.Writer, p *PatternFile) error {
+	if err := checkTwoStates(p.Board); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n", life106Header)
+	writeLifeComments(bw, p)
+	b := p.Board
+	for i, c := range b.cells {
+		if c != 0 {
+			fmt.Fprintf(bw, "%d %d\n", b.x+i%b.width, b.y+i/b.width)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeLifeComments writes the name, author, and comments of a pattern as
+// the "#D" lines of a Life 1.05 or 1.06 file.
+func writeLifeComments(w io.Writer, p *PatternFile) {
+	if p.Name != "" {
+		fmt.Fprintf(w, "#D Name: %s\n", p.Name)
+	}
+	if p.Author != "" {
+		fmt.Fprintf(w, "#D Author: %s\n", p.Author)
+	}
+	for _, comment := range p.Comments {
+		fmt.Fprintf(w, "#D %s\n", comment)
+	}
+}
@@ -0,0 +1,145 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadLife105(t *testing.T) {
+	const pattern = `#Life 1.05
+#D Name: Two blocks
+#D Far apart.
+#R 23/36
+#P -1 -1
+**
+**
+#P 4 2
+*.*
+`
+	p, err := ReadLife105(strings.NewReader(pattern), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	board, _ := NewBoardFromRows([]string{"OO....", "OO....", "", ".....O.O"})
+	board.SetOrigin(-1, -1)
+	want := &PatternFile{
+		Name:     "Two blocks",
+		Comments: []string{"Far apart."},
+		Rule:     "B36/S23",
+		Board:    board.Trim(),
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadLife106(t *testing.T) {
+	const pattern = `#Life 1.06
+0 -1
+1 0
+-1 1
+0 1
+1 1
+`
+	p, err := ReadLife106(strings.NewReader(pattern), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	board, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	board.SetOrigin(-1, -1)
+	if diff := cmp.Diff(&PatternFile{Board: board}, p); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadLifeInvalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		err     error
+	}{
+		{name: "1.05 header", pattern: "#Life 1.06\n#P 0 0\n*", err: ErrInvalidPattern},
+		{name: "1.05 missing block", pattern: "#Life 1.05\n**", err: ErrInvalidPattern},
+		{name: "1.05 bad block", pattern: "#Life 1.05\n#P a\n**", err: ErrInvalidPattern},
+		{name: "1.05 bad cell", pattern: "#Life 1.05\n#P 0 0\n*x", err: ErrInvalidPattern},
+		{name: "1.05 too large", pattern: "#Life 1.05\n#P 0 0\n*\n#P 100 0\n*", err: ErrTooLarge},
+		{name: "1.06 header", pattern: "0 0", err: ErrInvalidPattern},
+		{name: "1.06 bad cell", pattern: "#Life 1.06\n0 x", err: ErrInvalidPattern},
+		{name: "1.06 too large", pattern: "#Life 1.06\n0 0\n0 -100", err: ErrTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			read := ReadLife105
+			if strings.HasPrefix(tc.name, "1.06") {
+				read = ReadLife106
+			}
+			if _, err := read(strings.NewReader(tc.pattern), 100); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestWriteLife(t *testing.T) {
+	board, _ := NewBoardFromRows([]string{".O.", "", "OOO"})
+	board.SetOrigin(2, -1)
+	p := &PatternFile{Name: "Test", Rule: "B36/S23", Board: board}
+	cases := []struct {
+		name  string
+		write func(*strings.Builder, *PatternFile) error
+		want  string
+	}{
+		{
+			name:  "1.05",
+			write: func(sb *strings.Builder, p *PatternFile) error { return WriteLife105(sb, p) },
+			want:  "#Life 1.05\n#D Name: Test\n#R 23/36\n#P 2 -1\n.*\n.\n***\n",
+		},
+		{
+			name:  "1.06",
+			write: func(sb *strings.Builder, p *PatternFile) error { return WriteLife106(sb, p) },
+			want:  "#Life 1.06\n#D Name: Test\n3 -1\n2 1\n3 1\n4 1\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tc.write(&sb, p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, sb.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteLife105Blocks(t *testing.T) {
+	b := NewBoard(200, 2)
+	b.Set(0, 0, true)
+	b.Set(199, 1, true)
+	var sb strings.Builder
+	if err := WriteLife105(&sb, &PatternFile{Rule: "B3/S23", Board: b}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if len(line) > life105LineWidth {
+			t.Errorf("line of %d columns exceeds %d", len(line), life105LineWidth)
+		}
+	}
+	if !strings.Contains(sb.String(), "#N\n") {
+		t.Errorf("expected #N for %s, got:\n%s", Conway, sb.String())
+	}
+
+	p, err := ReadLife105(strings.NewReader(sb.String()), 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b.Equal(p.Board) {
+		t.Errorf("expected:\n%s\ngot:\n%s", b, p.Board)
+	}
+}
@@ -0,0 +1,117 @@
+package life
+
+// Step computes the next generation of the board using the given rule. For
+// [Conway] the rules are:
+//
+//  1. Any live cell with fewer than two live neighbours dies.
+//  2. Any live cell with two or three live neighbours lives on.
+//  3. Any live cell with more than three live neighbours dies.
+//  4. Any dead cell with exactly three live neighbours becomes a live cell.
+//
+// On a bounded topology the edges of the board are joined as described by
+// the [Topology], so the board should match its dimensions. On an unbounded
+// plane the board is grown to fit any births and the result is trimmed to
+// its live cells, so a pattern that dies out becomes an empty board at the
+// origin. The given board is not modified.
+func Step(b *Board, r Rule) *Board {
+	topo := r.topology
+	if !topo.Bounded() {
+		rad := r.Range()
+		b = b.crop(-rad, -rad, b.width+2*rad, b.height+2*rad)
+	}
+
+	next := NewBoard(b.width, b.height)
+	next.x, next.y = b.x, b.y
+	b.rowStepper(r)(next, 0, b.height)
+
+	if !topo.Bounded() {
+		return trimPlane(next)
+	}
+	return next
+}
+
+// trimPlane trims a board on an unbounded plane to its live cells. An empty
+// board has no position, so it is placed at the origin.
+func trimPlane(b *Board) *Board {
+	t := b.Trim()
+	if t.width == 0 {
+		return NewBoard(0, 0)
+	}
+	return t
+}
+
+// StepN computes the nth generation after the board by calling [Step]
+// repeatedly. A non-positive n returns a copy of the board.
+func StepN(b *Board, r Rule, n int) *Board {
+	next := b.Clone()
+	for i := 0; i < n; i++ {
+		next = Step(next, r)
+	}
+	return next
+}
+
+// rowStepper returns a function that computes rows [from, to) of the next
+// generation of the board into next, which must have the same dimensions.
+// The function may be called concurrently for distinct rows.
+func (b *Board) rowStepper(r Rule) func(next *Board, from, to int) {
+	if r.ltl.radius > 0 {
+		return newLtLGrid(b, r).stepRows
+	}
+	if r.custom != nil {
+		return func(next *Board, from, to int) {
+			b.stepCustomRows(next, r, from, to)
+		}
+	}
+	return func(next *Board, from, to int) {
+		b.stepRows(next, r, from, to)
+	}
+}
+
+// stepRows computes rows [from, to) of the next generation of the board
+// into next, which must have the same dimensions.
+func (b *Board) stepRows(next *Board, r Rule, from, to int) {
+	for y := from; y < to; y++ {
+		for x := 0; x < b.width; x++ {
+			i := y*b.width + x
+			next.cells[i] = r.nextState(b.cells[i], b.neighborhood(x, y, r.topology))
+		}
+	}
+}
+
+// stepCustomRows computes rows [from, to) of the next generation of the
+// board into next with a custom rule, which reads the state of every cell of
+// the neighbourhood.
+func (b *Board) stepCustomRows(next *Board, r Rule, from, to int) {
+	var cells [9]uint8
+	for y := from; y < to; y++ {
+		for x := 0; x < b.width; x++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					var state uint8
+					if nx, ny, ok := r.topology.wrap(x+dx, y+dy, b.width, b.height); ok {
+						state = b.cells[ny*b.width+nx]
+					}
+					cells[(dy+1)*3+dx+1] = state
+				}
+			}
+			next.cells[y*b.width+x] = r.custom.next(&cells)
+		}
+	}
+}
+
+// neighborhood returns the index of the 3x3 neighbourhood centered on x, y
+// with a bit per live cell in row major order. Dying cells are not counted.
+// Neighbours beyond the edge of the board are found by wrapping them with the
+// topology.
+func (b *Board) neighborhood(x, y int, topo Topology) int {
+	idx := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			nx, ny, ok := topo.wrap(x+dx, y+dy, b.width, b.height)
+			if ok && b.cells[ny*b.width+nx] == 1 {
+				idx |= 1 << ((dy+1)*3 + dx + 1)
+			}
+		}
+	}
+	return idx
+}
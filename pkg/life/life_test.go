@@ -0,0 +1,208 @@
+package life
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStep(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []string
+		want  []string
+	}{
+		{
+			name:  "underpopulation",
+			input: []string{"...", ".O.", "..."},
+			want:  []string{"...", "...", "..."},
+		},
+		{
+			name:  "block",
+			input: []string{"....", ".OO.", ".OO.", "...."},
+			want:  []string{"....", ".OO.", ".OO.", "...."},
+		},
+		{
+			name:  "blinker",
+			input: []string{"...", "OOO", "..."},
+			want:  []string{".O.", ".O.", ".O."},
+		},
+		{
+			name:  "overpopulation",
+			input: []string{"OOO", "OOO", "OOO"},
+			want:  []string{"O.O", "...", "O.O"},
+		},
+		{
+			name:  "edge",
+			input: []string{"OOO"},
+			want:  []string{".O."},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.input)
+			got := Step(b, boundedRule(t, "B3/S23", b))
+			if diff := cmp.Diff(tc.want, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.input, b.Rows()); diff != "" {
+				t.Errorf("input modified (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepN(t *testing.T) {
+	glider := []string{
+		".O....",
+		"..O...",
+		"OOO...",
+		"......",
+		"......",
+		"......",
+	}
+	cases := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{name: "zero", n: 0, want: glider},
+		{name: "negative", n: -1, want: glider},
+		{
+			name: "period",
+			n:    4,
+			want: []string{
+				"......",
+				"..O...",
+				"...O..",
+				".OOO..",
+				"......",
+				"......",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(glider)
+			got := StepN(b, boundedRule(t, "B3/S23", b), tc.n)
+			if diff := cmp.Diff(tc.want, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepPlane(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{
+		"......",
+		"..O...",
+		"...O..",
+		".OOO..",
+		"......",
+	})
+
+	// a glider moves one cell down and right every four generations
+	got := StepN(glider, Conway, 8)
+	if diff := cmp.Diff([]string{".O.", "..O", "OOO"}, got.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	x, y := got.Origin()
+	if diff := cmp.Diff([]int{3, 3}, []int{x, y}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// a blinker on the edge of the board grows beyond it
+	blinker, _ := NewBoardFromRows([]string{"OOO"})
+	got = Step(blinker, Conway)
+	x, y = got.Origin()
+	if diff := cmp.Diff([]int{1, -1}, []int{x, y}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// a dying pattern leaves an empty board
+	if got := Step(NewBoard(3, 3), Conway); got.Width() != 0 || got.Height() != 0 {
+		t.Errorf("expected empty board, got %dx%d", got.Width(), got.Height())
+	}
+}
+
+func TestStepTopology(t *testing.T) {
+	glider := []string{
+		".O...",
+		"..O..",
+		"OOO..",
+		".....",
+		".....",
+	}
+	cases := []struct {
+		rule  string
+		input []string
+		n     int
+		want  []string
+	}{
+		{
+			// the glider dies against the edges of a bounded plane
+			rule:  "B3/S23:P5,5",
+			input: glider,
+			n:     20,
+			want:  []string{".....", ".....", ".....", "...OO", "...OO"},
+		},
+		{
+			// a glider returns to its starting position after crossing a torus
+			rule:  "B3/S23:T5,5",
+			input: glider,
+			n:     20,
+			want:  glider,
+		},
+		{
+			// a blinker crossing the twisted top and bottom edges of a Klein
+			// bottle continues from the mirrored column
+			rule:  "B3/S23:K5*,5",
+			input: []string{".O...", ".O...", ".....", ".....", "...O."},
+			n:     1,
+			want:  []string{"OOO..", ".....", ".....", ".....", "....."},
+		},
+		{
+			// the same cells on a torus do not form a blinker
+			rule:  "B3/S23:T5,5",
+			input: []string{".O...", ".O...", ".....", ".....", "...O."},
+			n:     1,
+			want:  []string{"..O..", ".....", ".....", ".....", "....."},
+		},
+		{
+			// a blinker crossing the twisted left and right edges of a
+			// cross-surface continues from the mirrored row
+			rule:  "B3/S23:C5,5",
+			input: []string{".....", "OO...", ".....", "....O", "....."},
+			n:     1,
+			want:  []string{"O....", "O....", "O....", ".....", "....."},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.rule, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.input)
+			r, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := StepN(b, r, tc.n)
+			if diff := cmp.Diff(tc.want, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// boundedRule parses the rulestring with a bounded plane suffix that matches
+// the size of the board.
+func boundedRule(t *testing.T, rule string, b *Board) Rule {
+	t.Helper()
+	r, err := ParseRule(fmt.Sprintf("%s:P%d,%d", rule, b.Width(), b.Height()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return r
+}
@@ -0,0 +1,275 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRange is the maximum range of a Larger than Life neighbourhood.
+const maxRange = 500
+
+// Neighborhood describes which cells around a cell are its neighbours.
+type Neighborhood int
+
+const (
+	// Moore is the square of cells within the range of a cell.
+	Moore Neighborhood = iota
+	// VonNeumann is the diamond of cells within the range of a cell, counted
+	// in orthogonal steps.
+	VonNeumann
+	// Circular is the disc of cells whose distance from a cell is less than
+	// the range plus one half.
+	Circular
+	// Hexagonal is the six cells around a cell of a hexagonal grid. It is
+	// emulated on a square grid by ignoring the north east and south west
+	// cells of the Moore neighbourhood, so each row is offset by half a cell
+	// from the row above. It is only supported at range 1.
+	Hexagonal
+)
+
+// mask returns the bits of a range 1 neighbourhood index that hold the
+// neighbours of the center cell.
+func (n Neighborhood) mask() int {
+	switch n {
+	case VonNeumann:
+		return 1<<1 | 1<<3 | 1<<5 | 1<<7
+	case Hexagonal:
+		return 0x1ff &^ (centerBit | 1<<2 | 1<<6)
+	default:
+		return 0x1ff &^ centerBit
+	}
+}
+
+// neighborhoodLetters maps each Larger than Life neighbourhood to its
+// letter in a rulestring. Hexagonal neighbourhoods are not supported.
+var neighborhoodLetters = map[Neighborhood]byte{
+	Moore:      'M',
+	VonNeumann: 'N',
+	Circular:   'C',
+}
+
+// ltlRule holds the parameters of a Larger than Life rule. Births and
+// survivals are inclusive ranges of live neighbour counts.
+type ltlRule struct {
+	radius       int
+	neighborhood Neighborhood
+	middle       bool
+	sMin, sMax   int
+	bMin, bMax   int
+}
+
+// parseLtL parses a Larger than Life rulestring in Golly notation, such as
+// "r5,c0,m1,s34..58,b34..45,nm", that has been lowercased and had its bounded
+// grid suffix removed. The fields may be in any order, and the neighbourhood
+// is optional and defaults to Moore.
+func parseLtL(s string) (ltlRule, int, error) {
+	l := ltlRule{neighborhood: Moore}
+	states := 2
+	seen := make(map[byte]bool)
+	for _, field := range strings.Split(s, ",") {
+		if field == "" {
+			return ltlRule{}, 0, errors.New("empty field")
+		}
+		key, value := field[0], field[1:]
+		if seen[key] {
+			return ltlRule{}, 0, fmt.Errorf("duplicate field %q", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case 'r':
+			l.radius, err = strconv.Atoi(value)
+			if err == nil && (l.radius < 1 || l.radius > maxRange) {
+				err = fmt.Errorf("range %d must be between 1 and %d", l.radius, maxRange)
+			}
+		case 'c':
+			states, err = strconv.Atoi(value)
+			if err == nil && states == 0 {
+				states = 2
+			}
+			if err == nil && (states < 2 || states > MaxStates) {
+				err = fmt.Errorf("number of states %d must be 0 or between 2 and %d", states, MaxStates)
+			}
+		case 'm':
+			switch value {
+			case "0":
+			case "1":
+				l.middle = true
+			default:
+				err = fmt.Errorf("middle %q must be 0 or 1", value)
+			}
+		case 's':
+			l.sMin, l.sMax, err = parseCountRange(value)
+		case 'b':
+			l.bMin, l.bMax, err = parseCountRange(value)
+		case 'n':
+			err = fmt.Errorf("unsupported neighbourhood %q", value)
+			for n, letter := range neighborhoodLetters {
+				if value == strings.ToLower(string(letter)) {
+					l.neighborhood, err = n, nil
+				}
+			}
+		default:
+			err = fmt.Errorf("unexpected field %q", field)
+		}
+		if err != nil {
+			return ltlRule{}, 0, err
+		}
+	}
+	for _, key := range []byte("rcmsb") {
+		if !seen[key] {
+			return ltlRule{}, 0, fmt.Errorf("missing field %q", strings.ToUpper(string(key)))
+		}
+	}
+
+	size := l.size()
+	if !l.middle {
+		size--
+	}
+	if l.sMax > size || l.bMax > size {
+		return ltlRule{}, 0, fmt.Errorf("counts must be at most %d", size)
+	}
+	return l, states, nil
+}
+
+// parseCountRange parses an inclusive range of neighbour counts such as
+// "34..58".
+func parseCountRange(s string) (int, int, error) {
+	lo, hi, ok := strings.Cut(s, "..")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected count range min..max, got %q", s)
+	}
+	minN, err1 := strconv.Atoi(lo)
+	maxN, err2 := strconv.Atoi(hi)
+	if err1 != nil || err2 != nil || minN < 0 || minN > maxN {
+		return 0, 0, fmt.Errorf("invalid count range %q", s)
+	}
+	return minN, maxN, nil
+}
+
+// String returns the canonical Golly form of the rule without a bounded grid
+// suffix.
+func (l ltlRule) String(states int) string {
+	middle := 0
+	if l.middle {
+		middle = 1
+	}
+	if states == 2 {
+		states = 0
+	}
+	return fmt.Sprintf("R%d,C%d,M%d,S%d..%d,B%d..%d,N%c",
+		l.radius, states, middle, l.sMin, l.sMax, l.bMin, l.bMax, neighborhoodLetters[l.neighborhood])
+}
+
+// width returns the number of cells either side of the center in the row
+// dy rows away from it.
+func (l ltlRule) width(dy int) int {
+	dy = max(dy, -dy)
+	switch l.neighborhood {
+	case VonNeumann:
+		return l.radius - dy
+	case Circular:
+		// cells within a distance of the range plus one half
+		limit := l.radius*l.radius + l.radius - dy*dy
+		w := 0
+		for (w+1)*(w+1) <= limit {
+			w++
+		}
+		return w
+	default:
+		return l.radius
+	}
+}
+
+// size returns the number of cells in the neighbourhood, including the
+// center.
+func (l ltlRule) size() int {
+	n := 0
+	for dy := -l.radius; dy <= l.radius; dy++ {
+		n += 2*l.width(dy) + 1
+	}
+	return n
+}
+
+// ltlGrid is a summed-area table of the live cells of a board and the cells
+// within range around it, wrapped according to the topology. Any rectangle
+// of the neighbourhood can then be counted in constant time.
+type ltlGrid struct {
+	board  *Board
+	rule   Rule
+	stride int
+	sums   []int32
+	// widths holds the width of each row of the neighbourhood, from the top,
+	// so it is not recomputed for every cell
+	widths []int
+}
+
+// newLtLGrid builds the summed-area table of a board for a Larger than Life
+// rule.
+func newLtLGrid(b *Board, r Rule) *ltlGrid {
+	rad := r.ltl.radius
+	width, height := b.width+2*rad, b.height+2*rad
+	g := &ltlGrid{board: b, rule: r, stride: width + 1}
+	g.sums = make([]int32, (width+1)*(height+1))
+	g.widths = make([]int, 2*rad+1)
+	for dy := -rad; dy <= rad; dy++ {
+		g.widths[dy+rad] = r.ltl.width(dy)
+	}
+	for py := 0; py < height; py++ {
+		var row int32
+		for px := 0; px < width; px++ {
+			x, y, ok := r.topology.wrap(px-rad, py-rad, b.width, b.height)
+			if ok && b.cells[y*b.width+x] == 1 {
+				row++
+			}
+			g.sums[(py+1)*g.stride+px+1] = g.sums[py*g.stride+px+1] + row
+		}
+	}
+	return g
+}
+
+// count returns the number of live cells in the inclusive rectangle of
+// board coordinates.
+func (g *ltlGrid) count(x0, y0, x1, y1 int) int {
+	rad := g.rule.ltl.radius
+	x0, y0, x1, y1 = x0+rad, y0+rad, x1+rad+1, y1+rad+1
+	return int(g.sums[y1*g.stride+x1] - g.sums[y0*g.stride+x1] - g.sums[y1*g.stride+x0] + g.sums[y0*g.stride+x0])
+}
+
+// stepRows computes rows [from, to) of the next generation of the board into
+// next, which must have the same dimensions.
+func (g *ltlGrid) stepRows(next *Board, from, to int) {
+	l, b := g.rule.ltl, g.board
+	for y := from; y < to; y++ {
+		for x := 0; x < b.width; x++ {
+			var n int
+			if l.neighborhood == Moore {
+				n = g.count(x-l.radius, y-l.radius, x+l.radius, y+l.radius)
+			} else {
+				for dy, w := range g.widths {
+					dy -= l.radius
+					n += g.count(x-w, y+dy, x+w, y+dy)
+				}
+			}
+
+			i := y*b.width + x
+			state := b.cells[i]
+			if state == 1 && !l.middle {
+				n--
+			}
+			switch {
+			case state == 0 && n >= l.bMin && n <= l.bMax:
+				next.cells[i] = 1
+			case state == 1 && n >= l.sMin && n <= l.sMax:
+				next.cells[i] = 1
+			case state == 0:
+				next.cells[i] = 0
+			default:
+				next.cells[i] = g.rule.decay(state)
+			}
+		}
+	}
+}
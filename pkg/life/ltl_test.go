@@ -0,0 +1,143 @@
+package life
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLtL(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+		size  int
+	}{
+		{input: "R5,C0,M1,S34..58,B34..45,NM", want: "R5,C0,M1,S34..58,B34..45,NM", size: 121},
+		{input: "r5,c2,m1,s34..58,b34..45", want: "R5,C0,M1,S34..58,B34..45,NM", size: 121},
+		{input: "R2,C3,M0,S1..4,B2..3,NN:T20,20", want: "R2,C3,M0,S1..4,B2..3,NN:T20,20", size: 13},
+		{input: "B3..3,S2..3,R2,M0,C0,NC", want: "R2,C0,M0,S2..3,B3..3,NC", size: 21},
+		{input: "R1,C0,M0,S2..3,B0..3,NM:P10,10", want: "R1,C0,M0,S2..3,B0..3,NM:P10,10", size: 9},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseRule(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, r.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.size, r.ltl.size()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseLtLInvalid(t *testing.T) {
+	cases := []string{
+		"R5,C0,M1,S34..58",
+		"R0,C0,M1,S1..2,B1..2",
+		"R501,C0,M1,S1..2,B1..2",
+		"R1,C1,M1,S1..2,B1..2",
+		"R1,C0,M2,S1..2,B1..2",
+		"R1,C0,M1,S2..1,B1..2",
+		"R1,C0,M1,S1-2,B1..2",
+		"R1,C0,M1,S1..2,B1..2,NX",
+		"R1,C0,M1,S1..2,B1..2,R2",
+		"R1,C0,M0,S1..9,B1..2",
+		"R1,C0,M1,S1..2,B0..2",
+		"R1,C0,M1,S1..2,,B1..2",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc, func(t *testing.T) {
+			if _, err := ParseRule(tc); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestStepLtLConway(t *testing.T) {
+	// range 1 Larger than Life rules can express Conway's Game of Life
+	ltl, _ := ParseRule("R1,C0,M0,S2..3,B3..3,NM")
+	b := randomBoard(rand.New(rand.NewPCG(15, 16)), 30, 30, 0.4)
+	if want, got := StepN(b, Conway, 20), StepN(b, ltl, 20); !want.Equal(got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestStepLtL(t *testing.T) {
+	rng := rand.New(rand.NewPCG(17, 18))
+	cases := []string{
+		"R3,C0,M1,S8..20,B7..12,NM:T30,20",
+		"R3,C0,M0,S4..9,B4..6,NN:K30*,20",
+		"R3,C4,M1,S8..20,B7..12,NC:P30,20",
+		"R4,C0,M1,S8..30,B9..20,NC",
+	}
+
+	for _, tc := range cases {
+		t.Run(tc, func(t *testing.T) {
+			r, err := ParseRule(tc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := randomBoard(rng, 30, 20, 0.4)
+			for i := 0; i < 3; i++ {
+				want, got := stepLtLReference(b, r), Step(b, r)
+				if !want.Equal(got) {
+					t.Fatalf("generation %d expected:\n%s\ngot:\n%s", i+1, want, got)
+				}
+				b = got
+			}
+		})
+	}
+}
+
+// stepLtLReference steps a Larger than Life rule by counting every
+// neighbour of every cell.
+func stepLtLReference(b *Board, r Rule) *Board {
+	l, topo := r.ltl, r.topology
+	if !topo.Bounded() {
+		b = b.crop(-l.radius, -l.radius, b.width+2*l.radius, b.height+2*l.radius)
+	}
+	next := NewBoard(b.width, b.height)
+	next.x, next.y = b.x, b.y
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			n := 0
+			for dy := -l.radius; dy <= l.radius; dy++ {
+				for dx := -l.radius; dx <= l.radius; dx++ {
+					inside := true
+					switch l.neighborhood {
+					case VonNeumann:
+						inside = max(dx, -dx)+max(dy, -dy) <= l.radius
+					case Circular:
+						inside = dx*dx+dy*dy <= l.radius*l.radius+l.radius
+					}
+					if !inside || (dx == 0 && dy == 0 && !l.middle) {
+						continue
+					}
+					if nx, ny, ok := topo.wrap(x+dx, y+dy, b.width, b.height); ok && b.State(nx, ny) == 1 {
+						n++
+					}
+				}
+			}
+			state := b.State(x, y)
+			switch {
+			case state == 0 && n >= l.bMin && n <= l.bMax, state == 1 && n >= l.sMin && n <= l.sMax:
+				next.SetState(x, y, 1)
+			case state != 0:
+				next.SetState(x, y, r.decay(state))
+			}
+		}
+	}
+	if !topo.Bounded() {
+		return trimPlane(next)
+	}
+	return next
+}
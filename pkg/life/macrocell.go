@@ -0,0 +1,242 @@
+package life
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// macrocellHeader starts the first line of a macrocell file.
+const macrocellHeader = "[M2]"
+
+// ReadMacrocell reads a pattern in Golly's macrocell format straight into a
+// [Quadtree], without expanding it into a board, so it may be far larger
+// than any board. After the "[M2]" header, "#R" and "#G" lines give the rule
+// and generation, and "#C" lines describe the pattern, where "Name:" and
+// "Author:" give its name and author. Every other line is a node of the
+// tree: leaves are 8x8 squares written as rows of '.' and '*' ending with
+// '$', and larger nodes are written as their level followed by the line
+// numbers of their four quadrants, or 0 when a quadrant is empty. The last
+// node is the root, centered on the origin. Only two-state patterns are
+// supported.
+//
+// The pattern returned holds its cells in its tree rather than its board.
+func ReadMacrocell(r io.Reader) (*PatternFile, error) {
+	p := &PatternFile{}
+	tb := newTreeBuilder()
+	nodes := []*node{nil}
+	scanner := bufio.NewScanner(r)
+	header := false
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case !header && text == "":
+		case !header:
+			if !strings.HasPrefix(text, macrocellHeader) {
+				return nil, fmt.Errorf("%w: expected %q, got %q", ErrInvalidPattern, macrocellHeader, text)
+			}
+			header = true
+		case strings.HasPrefix(text, "#"):
+			if err := p.readMacrocellComment(text); err != nil {
+				return nil, err
+			}
+		case text == "":
+		case text[0] == '.' || text[0] == '*' || text[0] == '$':
+			n, err := readMacrocellLeaf(tb, text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidPattern, line, err)
+			}
+			nodes = append(nodes, n)
+		default:
+			n, err := readMacrocellNode(tb, nodes, text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidPattern, line, err)
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("%w: expected %q", ErrInvalidPattern, macrocellHeader)
+	}
+
+	root := tb.emptyNode(minTreeLevel)
+	if len(nodes) > 1 {
+		root = nodes[len(nodes)-1]
+	}
+	p.Tree = &Quadtree{root: root}
+	return p, nil
+}
+
+// readMacrocellComment reads a comment line of a macrocell file into the
+// pattern.
+func (p *PatternFile) readMacrocellComment(line string) error {
+	tag, text := line[:min(len(line), 2)], strings.TrimSpace(line[min(len(line), 2):])
+	switch tag {
+	case "#R":
+		p.Rule = text
+	case "#G":
+		gen, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid generation %q", ErrInvalidPattern, text)
+		}
+		p.Generation = gen
+	case "#C", "#c":
+		p.readComment(text)
+	}
+	return nil
+}
+
+// readMacrocellLeaf reads a leaf line of a macrocell file into an 8x8 node.
+func readMacrocellLeaf(tb *treeBuilder, line string) (*node, error) {
+	var cells [8][8]bool
+	x, y := 0, 0
+	for _, c := range []byte(line) {
+		switch c {
+		case '$':
+			x, y = 0, y+1
+			continue
+		case '.', '*':
+		default:
+			return nil, fmt.Errorf("unexpected cell %q in leaf %q", c, line)
+		}
+		if x >= 8 || y >= 8 {
+			return nil, fmt.Errorf("leaf %q exceeds 8x8", line)
+		}
+		cells[y][x] = c == '*'
+		x++
+	}
+
+	var build func(x, y, level int) *node
+	build = func(x, y, level int) *node {
+		if level == 0 {
+			return tb.leaf(cells[y][x])
+		}
+		half := 1 << (level - 1)
+		return tb.join(
+			build(x, y, level-1),
+			build(x+half, y, level-1),
+			build(x, y+half, level-1),
+			build(x+half, y+half, level-1),
+		)
+	}
+	return build(0, 0, minTreeLevel), nil
+}
+
+// readMacrocellNode reads a line of a macrocell file giving the level and
+// quadrants of a node larger than a leaf.
+func readMacrocellNode(tb *treeBuilder, nodes []*node, line string) (*node, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected a level and four quadrants, got %q", line)
+	}
+	level, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %q", fields[0])
+	}
+	if level <= minTreeLevel {
+		return nil, fmt.Errorf("level %d node is not supported, as only two-state patterns are", level)
+	}
+	if level > maxTreeLevel {
+		return nil, fmt.Errorf("%w: level %d exceeds %d", ErrTooLarge, level, maxTreeLevel)
+	}
+
+	var quadrants [4]*node
+	for i, field := range fields[1:] {
+		idx, err := strconv.Atoi(field)
+		if err != nil || idx < 0 || idx >= len(nodes) {
+			return nil, fmt.Errorf("invalid quadrant %q", field)
+		}
+		q := nodes[idx]
+		if idx == 0 {
+			q = tb.emptyNode(level - 1)
+		}
+		if q.level != level-1 {
+			return nil, fmt.Errorf("quadrant %d of level %d is not of level %d", idx, q.level, level-1)
+		}
+		quadrants[i] = q
+	}
+	return tb.join(quadrants[0], quadrants[1], quadrants[2], quadrants[3]), nil
+}
+
+// WriteMacrocell writes a pattern in Golly's macrocell format read by
+// [ReadMacrocell]. The cells are written from the tree of the pattern, or
+// from its board when it has no tree, which must then only have dead and
+// live cells. The name, author, and comments are written as "#C" lines.
+func WriteMacrocell(w io.Writer, p *PatternFile) error {
+	q := p.Tree
+	if q == nil {
+		if err := checkTwoStates(p.Board); err != nil {
+			return err
+		}
+		q = NewQuadtree(p.Board)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n", macrocellHeader)
+	if p.Rule != "" {
+		fmt.Fprintf(bw, "#R %s\n", p.Rule)
+	}
+	if p.Generation != 0 {
+		fmt.Fprintf(bw, "#G %d\n", p.Generation)
+	}
+	if p.Name != "" {
+		fmt.Fprintf(bw, "#C Name: %s\n", p.Name)
+	}
+	if p.Author != "" {
+		fmt.Fprintf(bw, "#C Author: %s\n", p.Author)
+	}
+	for _, comment := range p.Comments {
+		fmt.Fprintf(bw, "#C %s\n", comment)
+	}
+
+	// nodes are numbered in the order they are written, after their
+	// quadrants, and empty nodes are 0
+	numbers := make(map[*node]int)
+	var write func(n *node) int
+	write = func(n *node) int {
+		if n.population == 0 {
+			return 0
+		}
+		if i, ok := numbers[n]; ok {
+			return i
+		}
+		if n.level == minTreeLevel {
+			writeMacrocellLeaf(bw, n)
+		} else {
+			nw, ne, sw, se := write(n.nw), write(n.ne), write(n.sw), write(n.se)
+			fmt.Fprintf(bw, "%d %d %d %d %d\n", n.level, nw, ne, sw, se)
+		}
+		numbers[n] = len(numbers) + 1
+		return numbers[n]
+	}
+	write(q.root)
+	return bw.Flush()
+}
+
+// writeMacrocellLeaf writes a leaf line for an 8x8 node, leaving out the
+// dead cells at the end of each row and the empty rows at the end.
+func writeMacrocellLeaf(w *bufio.Writer, n *node) {
+	var line []byte
+	end := 0
+	for y := 0; y < 8; y++ {
+		var row []byte
+		for x := 0; x < 8; x++ {
+			if cell(n, x, y) {
+				row = append(row, '*')
+			} else {
+				row = append(row, '.')
+			}
+		}
+		row = []byte(strings.TrimRight(string(row), "."))
+		line = append(append(line, row...), '$')
+		if len(row) > 0 {
+			end = len(line)
+		}
+	}
+	w.Write(line[:end])
+	w.WriteByte('\n')
+}
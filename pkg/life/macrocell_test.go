@@ -0,0 +1,157 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadMacrocell(t *testing.T) {
+	cases := []struct {
+		name string
+		mc   string
+		rows []string
+		x, y int
+	}{
+		{
+			name: "leaf",
+			mc:   "[M2] (golly 4.2)\n#R B3/S23\n.*$..*$***$\n",
+			rows: []string{".O.", "..O", "OOO"},
+			x:    -4, y: -4,
+		},
+		{
+			name: "node",
+			mc:   "[M2] (golly 4.2)\n#R B3/S23\n$$$$$$$**$\n4 0 1 0 0\n",
+			rows: []string{"OO"},
+			x:    0, y: -1,
+		},
+		{
+			name: "shared",
+			mc:   "[M2] (golly 4.2)\n**$**$\n4 1 0 0 1\n",
+			rows: []string{"OO......", "OO......", "", "", "", "", "", "", "........OO", "........OO"},
+			x:    -8, y: -8,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ReadMacrocell(strings.NewReader(tc.mc))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := p.Tree.Board(1024)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want, _ := NewBoardFromRows(tc.rows)
+			want.SetOrigin(tc.x, tc.y)
+			if want = want.Trim(); !want.Equal(got) {
+				x, y := got.Origin()
+				t.Errorf("expected:\n%s\ngot at %d,%d:\n%s", want, x, y, got)
+			}
+		})
+	}
+}
+
+func TestReadMacrocellMetadata(t *testing.T) {
+	const mc = "[M2] (golly 4.2)\n#R B36/S23\n#G 1000\n#C Name: Replicator\n#C A comment.\n*$\n"
+	p, err := ReadMacrocell(strings.NewReader(mc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &PatternFile{Name: "Replicator", Comments: []string{"A comment."}, Rule: "B36/S23", Generation: 1000, Board: NewBoard(1, 1)}
+	want.Board.SetOrigin(-4, -4)
+	want.Board.Set(0, 0, true)
+	if p.Board, err = p.Tree.Board(8); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Tree = nil
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestMacrocellHuge reads a pattern whose copies of a block are spread over
+// a square far larger than any board, which is held by a few nodes.
+func TestMacrocellHuge(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("[M2]\n**$**$\n")
+	for level := 4; level <= 40; level++ {
+		fmt.Fprintf(&sb, "%d %d 0 0 %d\n", level, level-3, level-3)
+	}
+	p, err := ReadMacrocell(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := p.Tree
+	if want := uint64(4) << 37; q.Population() != want {
+		t.Errorf("expected population %d, got %d", want, q.Population())
+	}
+	if _, err := q.Board(1 << 16); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+
+	// every block is a still life, so advancing leaves the tree unchanged
+	h, _ := NewHashLife(Conway)
+	next, err := h.AdvanceTree(context.Background(), q, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got strings.Builder
+	if err := WriteMacrocell(&got, &PatternFile{Tree: next}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(sb.String(), got.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadMacrocellInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		mc   string
+		err  error
+	}{
+		{name: "header", mc: "x = 1, y = 1\no!", err: ErrInvalidPattern},
+		{name: "empty", mc: "", err: ErrInvalidPattern},
+		{name: "leaf cell", mc: "[M2]\n*o$\n", err: ErrInvalidPattern},
+		{name: "leaf size", mc: "[M2]\n*********$\n", err: ErrInvalidPattern},
+		{name: "multistate", mc: "[M2]\n1 0 1 2 0\n", err: ErrInvalidPattern},
+		{name: "fields", mc: "[M2]\n*$\n4 1 0 0\n", err: ErrInvalidPattern},
+		{name: "quadrant", mc: "[M2]\n*$\n4 2 0 0 0\n", err: ErrInvalidPattern},
+		{name: "quadrant level", mc: "[M2]\n*$\n4 1 0 0 0\n5 1 0 0 0\n", err: ErrInvalidPattern},
+		{name: "generation", mc: "[M2]\n#G x\n*$\n", err: ErrInvalidPattern},
+		{name: "too large", mc: "[M2]\n*$\n63 0 0 0 0\n", err: ErrTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ReadMacrocell(strings.NewReader(tc.mc)); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestWriteMacrocell(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	glider.SetOrigin(2, -1)
+	p := &PatternFile{Name: "Glider", Rule: "B3/S23", Generation: 4, Board: glider}
+	var sb strings.Builder
+	if err := WriteMacrocell(&sb, p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "[M2]\n#R B3/S23\n#G 4\n#C Name: Glider\n$$$$$$$...*$\n....*$..***$\n4 0 1 0 2\n"
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	wire, _ := NewBoardFromRows([]string{"AB"})
+	if err := WriteMacrocell(&sb, &PatternFile{Board: wire}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected %v, got %v", ErrInvalidPattern, err)
+	}
+}
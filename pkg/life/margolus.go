@@ -0,0 +1,206 @@
+package life
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MargolusRule is a block cellular automaton on the Margolus neighbourhood.
+// The grid is partitioned into 2x2 blocks, and every block is replaced by
+// the block the rule maps it to. The partition alternates between
+// generations: blocks start at even coordinates on even generations and at
+// odd coordinates on odd generations, so information flows between blocks.
+//
+// A block is numbered by adding 1 for its top left cell, 2 for its top right
+// cell, 4 for its bottom left cell, and 8 for its bottom right cell when they
+// are alive. A rule that maps the 16 blocks onto each other one to one is
+// reversible, and its generations can be computed backward as well as
+// forward with [StepMargolusBack].
+type MargolusRule struct {
+	name       string
+	blocks     [16]uint8
+	inverse    [16]uint8
+	reversible bool
+	topology   Topology
+}
+
+// margolusRules maps the names of well known Margolus rules to their MCell
+// notation.
+var margolusRules = map[string]string{
+	// Critters complements every block except those with two live cells,
+	// and rotates blocks with three live cells by a half turn.
+	"Critters": "MS,D15;14;13;3;11;5;6;1;7;9;10;2;12;4;8;0",
+	// BBM is Fredkin's billiard ball machine, where balls travel
+	// diagonally, bounce off walls, and deflect each other.
+	"BBM": "MS,D0;8;4;3;2;5;9;7;1;6;10;11;12;13;14;15",
+	// Tron complements blocks that are all dead or all alive.
+	"Tron": "MS,D15;1;2;3;4;5;6;7;8;9;10;11;12;13;14;0",
+}
+
+// ParseMargolusRule parses a Margolus rule in MCell notation, such as
+// "MS,D0;8;4;3;2;5;9;7;1;6;10;11;12;13;14;15", which lists the block each of
+// the 16 blocks is replaced by, or one of the names Critters, BBM, or Tron.
+// The rule may be followed by a torus suffix with even dimensions, such as
+// ":T64,64", so that the blocks tile it. Rules that give birth to cells in
+// empty blocks require a torus. Parsing is case insensitive.
+func ParseMargolusRule(s string) (MargolusRule, error) {
+	str, grid, bounded := strings.Cut(strings.TrimSpace(s), ":")
+	name := ""
+	for n, notation := range margolusRules {
+		if strings.EqualFold(str, n) {
+			name, str = n, notation
+		}
+	}
+	list, ok := strings.CutPrefix(strings.ToUpper(str), "MS,D")
+	if !ok {
+		return MargolusRule{}, fmt.Errorf("%w %q: expected MS,D notation or a named Margolus rule", ErrInvalidRule, s)
+	}
+	elems := strings.Split(list, ";")
+	if len(elems) != 16 {
+		return MargolusRule{}, fmt.Errorf("%w %q: expected 16 blocks, got %d", ErrInvalidRule, s, len(elems))
+	}
+
+	r := MargolusRule{name: name, reversible: true}
+	var seen [16]bool
+	for i, elem := range elems {
+		block, err := strconv.ParseUint(elem, 10, 8)
+		if err != nil || !isDigits(elem) || block > 15 {
+			return MargolusRule{}, fmt.Errorf("%w %q: block %q must be between 0 and 15", ErrInvalidRule, s, elem)
+		}
+		r.blocks[i] = uint8(block)
+		r.reversible = r.reversible && !seen[block]
+		seen[block] = true
+		r.inverse[block] = uint8(i)
+	}
+
+	if bounded {
+		var err error
+		if r.topology, err = parseTopology(grid); err != nil {
+			return MargolusRule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+		}
+		if r.topology.Kind != Torus {
+			return MargolusRule{}, fmt.Errorf("%w %q: Margolus rules only support a torus", ErrInvalidRule, s)
+		}
+		if r.topology.Width%2 != 0 || r.topology.Height%2 != 0 {
+			return MargolusRule{}, fmt.Errorf("%w %q: torus dimensions must be even", ErrInvalidRule, s)
+		}
+	}
+	if r.blocks[0] != 0 && !r.topology.Bounded() {
+		return MargolusRule{}, fmt.Errorf("%w %q: births in empty blocks require a torus", ErrInvalidRule, s)
+	}
+	return r, nil
+}
+
+// String returns the name of the rule if it has one and its MCell notation
+// otherwise, including the bounded grid suffix if there is one.
+func (r MargolusRule) String() string {
+	if r.name != "" {
+		return r.name + r.topology.String()
+	}
+	var sb strings.Builder
+	sb.WriteString("MS,D")
+	for i, block := range r.blocks {
+		if i > 0 {
+			sb.WriteByte(';')
+		}
+		sb.WriteString(strconv.Itoa(int(block)))
+	}
+	sb.WriteString(r.topology.String())
+	return sb.String()
+}
+
+// Reversible reports whether every block is replaced by a distinct block,
+// so that the previous generation can be recovered from the next.
+func (r MargolusRule) Reversible() bool {
+	return r.reversible
+}
+
+// Topology returns the grid the rule is evaluated on.
+func (r MargolusRule) Topology() Topology {
+	return r.topology
+}
+
+// StepMargolus computes the board n generations after the given generation,
+// whose parity decides the partition of the first step. On a torus the board
+// should match its dimensions. On an unbounded plane the result is trimmed
+// to its live cells. The given board is not modified.
+func StepMargolus(b *Board, r MargolusRule, generation int64, n int) *Board {
+	next := b.Clone()
+	for i := 0; i < n; i++ {
+		next = stepBlocks(next, r.topology, &r.blocks, generation+int64(i))
+	}
+	return next
+}
+
+// StepMargolusBack computes the board n generations before the given
+// generation by undoing each step with the inverse of the rule. It fails
+// with [ErrUnsupportedRule] when the rule is not reversible.
+func StepMargolusBack(b *Board, r MargolusRule, generation int64, n int) (*Board, error) {
+	if !r.Reversible() {
+		return nil, fmt.Errorf("%w %s: rule is not reversible", ErrUnsupportedRule, r)
+	}
+	prev := b.Clone()
+	for i := 1; i <= n; i++ {
+		prev = stepBlocks(prev, r.topology, &r.inverse, generation-int64(i))
+	}
+	return prev, nil
+}
+
+// stepBlocks replaces every block of the partition used by the given
+// generation with the block it maps to. On a torus the blocks of odd
+// generations wrap around the edges.
+func stepBlocks(b *Board, topo Topology, blocks *[16]uint8, generation int64) *Board {
+	phase := int(generation & 1)
+	if topo.Bounded() {
+		next := NewBoard(b.width, b.height)
+		next.x, next.y = b.x, b.y
+		for y := phase; y < b.height+phase; y += 2 {
+			for x := phase; x < b.width+phase; x += 2 {
+				replaceBlock(b, next, blocks, x, y, func(x, y int) (int, int) {
+					return mod(x, b.width), mod(y, b.height)
+				})
+			}
+		}
+		return next
+	}
+
+	// grow the board to whole blocks of the partition in absolute
+	// coordinates, with a dead cell on every side so that only empty blocks
+	// are beyond it
+	x0 := b.x - 1 - mod(b.x-1-phase, 2)
+	y0 := b.y - 1 - mod(b.y-1-phase, 2)
+	width := b.x + b.width + 1 - x0
+	height := b.y + b.height + 1 - y0
+	width, height = width+width%2, height+height%2
+	grown := b.crop(x0-b.x, y0-b.y, width, height)
+	next := NewBoard(width, height)
+	next.x, next.y = grown.x, grown.y
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x += 2 {
+			replaceBlock(grown, next, blocks, x, y, func(x, y int) (int, int) {
+				return x, y
+			})
+		}
+	}
+	return trimPlane(next)
+}
+
+// replaceBlock writes the block that replaces the block of b with its top
+// left corner at x, y into next. The cells of the block are mapped onto the
+// boards by at.
+func replaceBlock(b, next *Board, blocks *[16]uint8, x, y int, at func(x, y int) (int, int)) {
+	var cells [4][2]int
+	block := 0
+	for i := range cells {
+		cx, cy := at(x+i%2, y+i/2)
+		cells[i] = [2]int{cx, cy}
+		if b.Alive(cx, cy) {
+			block |= 1 << i
+		}
+	}
+	block = int(blocks[block])
+	for i, c := range cells {
+		next.Set(c[0], c[1], block>>i&1 != 0)
+	}
+}
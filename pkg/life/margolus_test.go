@@ -0,0 +1,114 @@
+package life
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMargolusRule(t *testing.T) {
+	cases := []struct {
+		input      string
+		want       string
+		reversible bool
+	}{
+		{input: "Critters:T16,16", want: "Critters:T16,16", reversible: true},
+		{input: "bbm", want: "BBM", reversible: true},
+		{input: "ms,d0;8;4;3;2;5;9;7;1;6;10;11;12;13;14;15", want: "MS,D0;8;4;3;2;5;9;7;1;6;10;11;12;13;14;15", reversible: true},
+		{input: "MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;14", want: "MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;14", reversible: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseMargolusRule(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, r.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if r.Reversible() != tc.reversible {
+				t.Errorf("expected reversible %t, got %t", tc.reversible, r.Reversible())
+			}
+		})
+	}
+}
+
+func TestParseMargolusRuleInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"B3/S23",
+		"MS,D0;1;2",
+		"MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;16",
+		"MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;+5",
+		"Critters",
+		"Critters:T15,16",
+		"Critters:P16,16",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseMargolusRule(input); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestStepMargolus(t *testing.T) {
+	bbm, _ := ParseMargolusRule("BBM")
+	ball, _ := NewBoardFromRows([]string{"O"})
+	cases := []struct {
+		name       string
+		generation int64
+		n          int
+		x, y       int
+	}{
+		{name: "even", generation: 0, n: 4, x: 4, y: 4},
+		{name: "odd", generation: 1, n: 1, x: -1, y: -1},
+		{name: "negative", generation: -3, n: 3, x: -3, y: -3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := StepMargolus(ball, bbm, tc.generation, tc.n)
+			want := ball.Clone()
+			want.SetOrigin(tc.x, tc.y)
+			if !want.Equal(got) {
+				x, y := got.Origin()
+				t.Errorf("expected the ball at %d,%d, got:\n%s\nat %d,%d", tc.x, tc.y, got, x, y)
+			}
+		})
+	}
+}
+
+func TestStepMargolusBack(t *testing.T) {
+	rng := rand.New(rand.NewPCG(13, 14))
+	cases := []struct {
+		rule  string
+		board *Board
+	}{
+		{rule: "Critters:T16,12", board: randomBoard(rng, 16, 12, 0.3)},
+		{rule: "Tron:T8,8", board: randomBoard(rng, 8, 8, 0.5)},
+		{rule: "BBM", board: randomBoard(rng, 9, 7, 0.2).Trim()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.rule, func(t *testing.T) {
+			r, _ := ParseMargolusRule(tc.rule)
+			next := StepMargolus(tc.board, r, 3, 25)
+			got, err := StepMargolusBack(next, r, 28, 25)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.board.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.board, got)
+			}
+		})
+	}
+
+	r, _ := ParseMargolusRule("MS,D0;1;2;3;4;5;6;7;8;9;10;11;12;13;14;14")
+	if _, err := StepMargolusBack(NewBoard(2, 2), r, 1, 1); !errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+	}
+}
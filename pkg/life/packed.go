@@ -0,0 +1,373 @@
+package life
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// packedMargin is the number of dead cells added around a pattern on an
+// unbounded plane whenever it grows into the edge of its packed grid.
+const packedMargin = 64
+
+// packedGrid is a board with 64 cells packed into each word. Each row has a
+// ghost cell on either side and the grid has a ghost row above and below,
+// which are filled from the topology before every generation. The cell at x,
+// y of the board is bit (x+1)%64 of word (x+1)/64 of row y+1.
+type packedGrid struct {
+	width, height int
+	x, y          int
+	stride        int
+	words         []uint64
+}
+
+// newPackedGrid packs the cells of a board.
+func newPackedGrid(b *Board) *packedGrid {
+	g := &packedGrid{
+		width:  b.width,
+		height: b.height,
+		x:      b.x,
+		y:      b.y,
+		stride: (b.width + 2 + 63) / 64,
+	}
+	g.words = make([]uint64, g.stride*(b.height+2))
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if b.cells[y*b.width+x] != 0 {
+				g.set(x+1, y+1)
+			}
+		}
+	}
+	return g
+}
+
+// clone returns a copy of the grid.
+func (g *packedGrid) clone() *packedGrid {
+	c := *g
+	c.words = slices.Clone(g.words)
+	return &c
+}
+
+// board unpacks the cells of the grid.
+func (g *packedGrid) board() *Board {
+	b := NewBoard(g.width, g.height)
+	b.x, b.y = g.x, g.y
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if g.get(x+1, y+1) {
+				b.cells[y*g.width+x] = 1
+			}
+		}
+	}
+	return b
+}
+
+// get reports whether the bit at column c of row r is set.
+func (g *packedGrid) get(c, r int) bool {
+	return g.words[r*g.stride+c/64]&(1<<(c%64)) != 0
+}
+
+// set the bit at column c of row r.
+func (g *packedGrid) set(c, r int) {
+	g.words[r*g.stride+c/64] |= 1 << (c % 64)
+}
+
+// fillGhosts sets the ghost cells around the grid to the cells they wrap to
+// under the topology. Ghost cells beyond the edge of a bounded plane are
+// dead.
+func (g *packedGrid) fillGhosts(topo Topology) {
+	ghost := func(c, r int) {
+		i, bit := r*g.stride+c/64, uint64(1)<<(c%64)
+		g.words[i] &^= bit
+		if x, y, ok := topo.wrap(c-1, r-1, g.width, g.height); ok && g.get(x+1, y+1) {
+			g.words[i] |= bit
+		}
+	}
+	for c := 0; c < g.width+2; c++ {
+		ghost(c, 0)
+		ghost(c, g.height+1)
+	}
+	for r := 1; r <= g.height; r++ {
+		ghost(0, r)
+		ghost(g.width+1, r)
+	}
+}
+
+// edgeAlive reports whether any live cell is on the outermost rows or
+// columns of the grid.
+func (g *packedGrid) edgeAlive() bool {
+	for c := 1; c <= g.width; c++ {
+		if g.get(c, 1) || g.get(c, g.height) {
+			return true
+		}
+	}
+	for r := 1; r <= g.height; r++ {
+		if g.get(1, r) || g.get(g.width, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// packedRule is a totalistic rule as masks of neighbour counts.
+type packedRule struct {
+	birth, survival [9]bool
+}
+
+// newPackedRule converts a rule into neighbour counts. It fails when the
+// rule is not totalistic, since only counts are computed, or when it has more
+// than two states, since only one bit is stored per cell, or when it has a
+// larger range.
+func newPackedRule(r Rule) (packedRule, error) {
+	if !packable(r) {
+		return packedRule{}, fmt.Errorf("%w: bit-packed stepping requires a two-state totalistic rule", ErrUnsupportedRule)
+	}
+	var pr packedRule
+	for n := 0; n <= 8; n++ {
+		// any neighbourhood with n live neighbours, skipping the center bit
+		idx := (1 << n) - 1
+		if n > 4 {
+			idx = (1<<(n+1) - 1) &^ centerBit
+		}
+		pr.birth[n] = r.next(idx)
+		pr.survival[n] = r.next(idx | centerBit)
+	}
+	return pr, nil
+}
+
+// packable reports whether a rule can be stepped by the bit-packed engine.
+func packable(r Rule) bool {
+	return r.Range() == 1 && r.Totalistic() && r.States() == 2
+}
+
+// step computes the next generation of the grid into next, which must have
+// the same dimensions. The ghost cells of the grid must already be filled.
+func (g *packedGrid) step(next *packedGrid, r packedRule) {
+	g.stepRows(next, r, 1, g.height+1)
+}
+
+// stepRows computes rows [from, to) of the next generation into next.
+func (g *packedGrid) stepRows(next *packedGrid, r packedRule, from, to int) {
+	// only the interior cells are kept, leaving the ghost cells and the
+	// unused bits of the last word clear
+	mask := make([]uint64, g.stride)
+	for c := 1; c <= g.width; c++ {
+		mask[c/64] |= 1 << (c % 64)
+	}
+
+	for row := from; row < to; row++ {
+		above := g.words[(row-1)*g.stride : row*g.stride]
+		mid := g.words[row*g.stride : (row+1)*g.stride]
+		below := g.words[(row+1)*g.stride : (row+2)*g.stride]
+		out := next.words[row*next.stride : (row+1)*next.stride]
+		for i := range out {
+			n := [8]uint64{
+				west(above, i), above[i], east(above, i),
+				west(mid, i), east(mid, i),
+				west(below, i), below[i], east(below, i),
+			}
+			out[i] = r.apply(mid[i], n) & mask[i]
+		}
+	}
+}
+
+// apply computes the next state of 64 cells from their current state and
+// their eight neighbours using a bitwise adder.
+func (r packedRule) apply(center uint64, n [8]uint64) uint64 {
+	s0, c0 := fullAdder(n[0], n[1], n[2])
+	s1, c1 := fullAdder(n[3], n[4], n[5])
+	s2, c2 := n[6]^n[7], n[6]&n[7]
+	b0, c3 := fullAdder(s0, s1, s2)
+	s4, c4 := fullAdder(c0, c1, c2)
+	b1, c5 := s4^c3, s4&c3
+	b2, b3 := c4^c5, c4&c5
+
+	var next uint64
+	for k := 0; k <= 8; k++ {
+		if !r.birth[k] && !r.survival[k] {
+			continue
+		}
+		eq := ^uint64(0)
+		for i, b := range [4]uint64{b0, b1, b2, b3} {
+			if k&(1<<i) != 0 {
+				eq &= b
+			} else {
+				eq &= ^b
+			}
+		}
+		switch {
+		case r.birth[k] && r.survival[k]:
+			next |= eq
+		case r.birth[k]:
+			next |= eq &^ center
+		default:
+			next |= eq & center
+		}
+	}
+	return next
+}
+
+// fullAdder adds three bits in each position, returning the sum and carry.
+func fullAdder(a, b, c uint64) (uint64, uint64) {
+	t := a ^ b
+	return t ^ c, a&b | c&t
+}
+
+// west returns word i of the row shifted so each bit holds its western
+// neighbour.
+func west(row []uint64, i int) uint64 {
+	w := row[i] << 1
+	if i > 0 {
+		w |= row[i-1] >> 63
+	}
+	return w
+}
+
+// east returns word i of the row shifted so each bit holds its eastern
+// neighbour.
+func east(row []uint64, i int) uint64 {
+	w := row[i] >> 1
+	if i < len(row)-1 {
+		w |= row[i+1] << 63
+	}
+	return w
+}
+
+// StepPacked computes the next generation of the board like [Step], but
+// packs 64 cells into each word and counts neighbours with bitwise adders.
+// Its results are identical to [Step]. It fails with [ErrUnsupportedRule]
+// when the rule is not totalistic or has more than two states.
+func StepPacked(b *Board, r Rule) (*Board, error) {
+	return StepPackedN(b, r, 1)
+}
+
+// StepPackedN computes the nth generation after the board like [StepN],
+// keeping the board packed between generations. It fails with
+// [ErrUnsupportedRule] when the rule is not totalistic or has more than two
+// states.
+func StepPackedN(b *Board, r Rule, n int) (*Board, error) {
+	p, err := NewPacked(b, r)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		p.Step()
+	}
+	return p.Board(), nil
+}
+
+// Packed is a board that is stepped by the bit-packed engine of
+// [StepPackedN] and kept packed between generations, so it can be stepped
+// one generation at a time without unpacking every generation.
+type Packed struct {
+	g, next *packedGrid
+	rule    packedRule
+	topo    Topology
+}
+
+// NewPacked packs the cells of a board to be stepped with the rule. It fails
+// with [ErrUnsupportedRule] when the rule is not totalistic or has more than
+// two states.
+func NewPacked(b *Board, r Rule) (*Packed, error) {
+	pr, err := newPackedRule(r)
+	if err != nil {
+		return nil, err
+	}
+	g := newPackedGrid(b)
+	return &Packed{
+		g:    g,
+		next: newPackedGrid(NewBoard(g.width, g.height)),
+		rule: pr,
+		topo: r.topology,
+	}, nil
+}
+
+// Step computes the next generation in place.
+func (p *Packed) Step() {
+	p.advance(func(g, next *packedGrid, pr packedRule) error {
+		g.step(next, pr)
+		return nil
+	})
+}
+
+// advance computes the next generation in place, calling step to compute
+// the next packed grid. On an unbounded plane the grid first grows whenever
+// live cells reach its edge. It stops at the error returned by step.
+func (p *Packed) advance(step func(g, next *packedGrid, pr packedRule) error) error {
+	if !p.topo.Bounded() && (p.g.width == 0 || p.g.height == 0 || p.g.edgeAlive()) {
+		// grow the grid so births cannot reach its edge
+		t := trimPlane(p.g.board())
+		if t.width == 0 {
+			p.g, p.next = newPackedGrid(t), newPackedGrid(t)
+			return nil
+		}
+		p.g = newPackedGrid(t.crop(-packedMargin, -packedMargin, t.width+2*packedMargin, t.height+2*packedMargin))
+		p.next = newPackedGrid(NewBoard(p.g.width, p.g.height))
+	}
+	p.g.fillGhosts(p.topo)
+	if err := step(p.g, p.next, p.rule); err != nil {
+		return err
+	}
+	p.next.x, p.next.y = p.g.x, p.g.y
+	p.g, p.next = p.next, p.g
+	return nil
+}
+
+// Board unpacks the cells into a new board. On an unbounded plane the board
+// is trimmed to its live cells.
+func (p *Packed) Board() *Board {
+	if !p.topo.Bounded() {
+		return trimPlane(p.g.board())
+	}
+	return p.g.board()
+}
+
+// Clone returns a copy of the pattern that is stepped independently.
+func (p *Packed) Clone() *Packed {
+	c := *p
+	c.g, c.next = p.g.clone(), p.next.clone()
+	return &c
+}
+
+// Equal reports whether both patterns have the same live cells at the same
+// positions, however far their grids extend around them.
+func (p *Packed) Equal(o *Packed) bool {
+	return p.Board().Equal(o.Board())
+}
+
+// Population returns the number of live cells.
+func (p *Packed) Population() int {
+	n := 0
+	p.g.each(func(x, y int) {
+		n++
+	})
+	return n
+}
+
+// Hash returns a hash of the live cells and their positions on the plane.
+// It is equal to the hash of the unpacked board, so it does not depend on
+// the dead cells around the pattern.
+func (p *Packed) Hash() uint64 {
+	var sum uint64
+	p.g.each(func(x, y int) {
+		sum += cellHash(int64(p.g.x+x), int64(p.g.y+y), 1)
+	})
+	return sum
+}
+
+// each calls fn with the board coordinates of every live cell of the grid,
+// skipping the ghost cells.
+func (g *packedGrid) each(fn func(x, y int)) {
+	for y := 0; y < g.height; y++ {
+		row := g.words[(y+1)*g.stride : (y+2)*g.stride]
+		for i, word := range row {
+			for word != 0 {
+				c := i*64 + bits.TrailingZeros64(word)
+				word &= word - 1
+				if c >= 1 && c <= g.width {
+					fn(c-1, y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// randomBoard returns a width by height board where each cell is alive with
+// the given probability.
+func randomBoard(rng *rand.Rand, width, height int, density float64) *Board {
+	b := NewBoard(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			b.Set(x, y, rng.Float64() < density)
+		}
+	}
+	return b
+}
+
+func TestStepPackedN(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 8))
+	rules := []string{"B3/S23", "B36/S23", "B0/S8", "B2/S", "B3678/S34678"}
+	topologies := []string{"", ":P%d,%d", ":T%d,%d", ":K%d*,%d", ":K%d,%d*", ":C%d,%d"}
+	sizes := [][2]int{{1, 1}, {5, 3}, {62, 4}, {63, 5}, {64, 6}, {65, 7}, {130, 9}}
+
+	for _, rs := range rules {
+		for _, ts := range topologies {
+			for _, size := range sizes {
+				suffix := ts
+				if ts != "" {
+					suffix = fmt.Sprintf(ts, size[0], size[1])
+				}
+				rule, err := ParseRule(rs + suffix)
+				if err != nil {
+					// B0 is not allowed on an unbounded plane
+					continue
+				}
+				t.Run(rule.String(), func(t *testing.T) {
+					b := randomBoard(rng, size[0], size[1], 0.4)
+					b.SetOrigin(3, -2)
+					got, err := StepPackedN(b, rule, 12)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if want := StepN(b, rule, 12); !want.Equal(got) {
+						t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestStepPackedGlider(t *testing.T) {
+	// the glider travels beyond the margin of the packed grid several times
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	got, err := StepPackedN(glider, Conway, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := StepN(glider, Conway, 1000); !want.Equal(got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	blinker, _ := NewBoardFromRows([]string{"O"})
+	got, err = StepPacked(blinker, Conway)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Width() != 0 || got.Height() != 0 {
+		t.Errorf("expected empty board, got:\n%s", got)
+	}
+}
+
+func TestStepPackedUnsupported(t *testing.T) {
+	rule, _ := ParseRule("B2-a/S12")
+	if _, err := StepPacked(NewBoard(3, 3), rule); !errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	rule, _ := ParseRule("B3/S23:T512,512")
+	board := randomBoard(rand.New(rand.NewPCG(1, 1)), 512, 512, 0.5)
+
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Step(board, rule)
+		}
+	})
+	b.Run("packed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			StepPacked(board, rule)
+		}
+	})
+}
+
+func TestPacked(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	for _, rs := range []string{"B3/S23", "B3/S23:T70,9"} {
+		rule, _ := ParseRule(rs)
+		t.Run(rule.String(), func(t *testing.T) {
+			b := randomBoard(rng, 70, 9, 0.4)
+			p, err := NewPacked(b, rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for gen := 1; gen <= 20; gen++ {
+				p.Step()
+				b = Step(b, rule)
+				if got := p.Board(); !b.Equal(got) {
+					t.Fatalf("generation %d expected:\n%s\ngot:\n%s", gen, b, got)
+				}
+				if p.Population() != b.Population() || p.Hash() != b.Hash() {
+					t.Fatalf("generation %d expected population %d and hash %x, got %d and %x",
+						gen, b.Population(), b.Hash(), p.Population(), p.Hash())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package life
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidPattern when a pattern file cannot be parsed.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// PatternFile is a board along with the metadata carried by pattern files,
+// such as RLE files. Reading a pattern and writing it back keeps the
+// metadata.
+type PatternFile struct {
+	// Name is the name of the pattern, if the file gives one.
+	Name string
+	// Author is the person who found or wrote the pattern.
+	Author string
+	// Comments are the lines of free text describing the pattern.
+	Comments []string
+	// Rule is the rule the pattern runs under as written in the file, which
+	// is empty when the file does not give one.
+	Rule string
+	// Generation is the generation the board was taken from.
+	Generation int64
+	// Board holds the cells of the pattern, with its origin placing it on
+	// the plane.
+	Board *Board
+	// Tree holds the cells of a pattern read by [ReadMacrocell] in place of
+	// the board, as it may be far too large for one.
+	Tree *Quadtree
+}
+
+// The pattern file formats read by [ReadPattern] and written by
+// [WritePattern].
+const (
+	FormatRLE       = "rle"
+	FormatCells     = "cells"
+	FormatLife105   = "life105"
+	FormatLife106   = "life106"
+	FormatMacrocell = "mc"
+)
+
+// patternReaders reads patterns by format.
+var patternReaders = map[string]func(io.Reader, int) (*PatternFile, error){
+	FormatRLE:       ReadRLE,
+	FormatCells:     ReadCells,
+	FormatLife105:   ReadLife105,
+	FormatLife106:   ReadLife106,
+	FormatMacrocell: readMacrocellBoard,
+}
+
+// patternWriters writes patterns by format.
+var patternWriters = map[string]func(io.Writer, *PatternFile) error{
+	FormatRLE:       WriteRLE,
+	FormatCells:     WriteCells,
+	FormatLife105:   WriteLife105,
+	FormatLife106:   WriteLife106,
+	FormatMacrocell: WriteMacrocell,
+}
+
+// PatternFormats returns the names of the pattern file formats in sorted
+// order.
+func PatternFormats() []string {
+	formats := make([]string, 0, len(patternWriters))
+	for format := range patternWriters {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// ReadPattern reads a pattern in any of the pattern file formats, returning
+// the format it was written in, which is found by [DetectPatternFormat]. The
+// whole input is read into memory first. Macrocell patterns are expanded
+// into a board, so [ReadMacrocell] should be used for patterns that may not
+// fit.
+//
+// It fails with [ErrTooLarge] when the pattern is wider or taller than
+// maxSize.
+func ReadPattern(r io.Reader, maxSize int) (*PatternFile, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	format := DetectPatternFormat(data)
+	p, err := patternReaders[format](bytes.NewReader(data), maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, format, nil
+}
+
+// DetectPatternFormat returns the format of a pattern file from its first
+// line. Macrocell and Life 1.05 and 1.06 files are recognized by their
+// header, plaintext files by their first line being a '!' comment or a row
+// of cells, and anything else is taken to be RLE.
+func DetectPatternFormat(data []byte) string {
+	first, _, _ := bytes.Cut(bytes.TrimLeft(data, " \t\r\n"), []byte("\n"))
+	line := string(bytes.TrimSpace(first))
+	switch {
+	case strings.HasPrefix(line, macrocellHeader):
+		return FormatMacrocell
+	case line == life105Header:
+		return FormatLife105
+	case line == life106Header:
+		return FormatLife106
+	case strings.HasPrefix(line, "!"), line != "" && strings.Trim(line, ".O*") == "":
+		return FormatCells
+	default:
+		return FormatRLE
+	}
+}
+
+// readMacrocellBoard reads a macrocell pattern and expands its tree into a
+// board, which must fit within a maxSize by maxSize board.
+func readMacrocellBoard(r io.Reader, maxSize int) (*PatternFile, error) {
+	p, err := ReadMacrocell(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.Board, err = p.Tree.Board(maxSize); err != nil {
+		return nil, err
+	}
+	p.Tree = nil
+	return p, nil
+}
+
+// WritePattern writes a pattern in the given format, failing with
+// [ErrInvalidPattern] when the format is unknown or cannot hold the states
+// of the board.
+func WritePattern(w io.Writer, p *PatternFile, format string) error {
+	write, ok := patternWriters[format]
+	if !ok {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidPattern, format)
+	}
+	return write(w, p)
+}
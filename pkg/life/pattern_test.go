@@ -0,0 +1,72 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadPattern(t *testing.T) {
+	cases := []struct {
+		format  string
+		pattern string
+	}{
+		{format: FormatRLE, pattern: "#N Glider\nx = 3, y = 3\nbo$2bo$3o!"},
+		{format: FormatCells, pattern: "!Name: Glider\n.O.\n..O\nOOO"},
+		{format: FormatCells, pattern: "\n.O.\n..O\nOOO"},
+		{format: FormatLife105, pattern: "#Life 1.05\n#P 0 0\n.*.\n..*\n***"},
+		{format: FormatLife106, pattern: "#Life 1.06\n1 0\n2 1\n0 2\n1 2\n2 2"},
+	}
+	want, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			p, format, err := ReadPattern(strings.NewReader(tc.pattern), 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != tc.format {
+				t.Errorf("expected format %s, got %s", tc.format, format)
+			}
+			if !want.Equal(p.Board) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, p.Board)
+			}
+		})
+	}
+}
+
+// TestPatternRoundTrip writes a pattern in every format and reads it back.
+func TestPatternRoundTrip(t *testing.T) {
+	board, _ := NewBoardFromRows([]string{"OO..O", "", "..O.O"})
+	p := &PatternFile{
+		Name:     "Round trip",
+		Author:   "Someone",
+		Comments: []string{"First line.", "Second line."},
+		Board:    board,
+	}
+
+	for _, format := range PatternFormats() {
+		t.Run(format, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WritePattern(&sb, p, format); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, detected, err := ReadPattern(strings.NewReader(sb.String()), 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if detected != format {
+				t.Errorf("expected format %s, got %s", format, detected)
+			}
+			if diff := cmp.Diff(p, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	if err := WritePattern(&strings.Builder{}, p, "zip"); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected %v, got %v", ErrInvalidPattern, err)
+	}
+}
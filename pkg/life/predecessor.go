@@ -0,0 +1,333 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ErrGardenOfEden when a pattern has no predecessor within the region that
+// was searched.
+var ErrGardenOfEden = errors.New("garden of eden")
+
+// progressNodes is the number of cells assigned by a predecessor search
+// between checks of its context and reports of its progress.
+const progressNodes = 1 << 20
+
+// maxPredecessorCells is the largest number of cells in the region searched
+// for a predecessor, and the largest margin around the pattern.
+const maxPredecessorCells = 1 << 20
+
+// PredecessorProgress reports how far a predecessor search has come.
+type PredecessorProgress struct {
+	// Nodes is the number of cell assignments tried so far.
+	Nodes int64
+	// Depth is the number of cells of the region currently assigned.
+	Depth int
+	// Cells is the number of cells in the region.
+	Cells int
+	// Explored is the fraction of every possible predecessor that has been
+	// ruled out, between 0 and 1.
+	Explored float64
+}
+
+// predecessorConfig holds the configuration of a predecessor search.
+type predecessorConfig struct {
+	margin   int
+	region   bool
+	x, y     int
+	width    int
+	height   int
+	progress func(PredecessorProgress)
+}
+
+// PredecessorOption configures a predecessor search by overriding a default
+// setting.
+type PredecessorOption func(*predecessorConfig)
+
+// WithMargin sets the number of cells the region searched for a
+// predecessor extends beyond the live cells of the pattern on every side.
+// The default is 1.
+func WithMargin(margin int) PredecessorOption {
+	return func(c *predecessorConfig) {
+		c.margin = margin
+	}
+}
+
+// WithRegion sets the region searched for a predecessor to the width by
+// height rectangle with its top left corner at x, y, replacing the margin.
+func WithRegion(x, y, width, height int) PredecessorOption {
+	return func(c *predecessorConfig) {
+		c.region = true
+		c.x, c.y, c.width, c.height = x, y, width, height
+	}
+}
+
+// WithProgress sets a function that is called with the progress of a
+// predecessor search periodically and once it ends.
+func WithProgress(fn func(PredecessorProgress)) PredecessorOption {
+	return func(c *predecessorConfig) {
+		c.progress = fn
+	}
+}
+
+// Predecessor searches for a parent of the pattern: a board whose next
+// generation under the rule is exactly the pattern, with every other cell
+// dead. Only parents whose live cells lie within the search region are
+// considered. When there are none the pattern is a Garden of Eden within the
+// region and Predecessor fails with [ErrGardenOfEden]. The parent found is
+// trimmed to its live cells.
+//
+// The search backtracks over the cells of the region in row major order,
+// abandoning a partial parent as soon as any cell of the next generation can
+// no longer become its state in the pattern. It supports two-state range 1
+// rules on an unbounded plane, and stops early when the context is
+// cancelled and returns the context error. Regions of more than a million
+// cells fail with [ErrTooLarge].
+func Predecessor(ctx context.Context, b *Board, r Rule, opts ...PredecessorOption) (*Board, error) {
+	if err := checkSparse(r); err != nil {
+		return nil, err
+	}
+	c := predecessorConfig{margin: 1}
+
+	// apply optional configuration
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !c.region {
+		if c.margin < -maxPredecessorCells || c.margin > maxPredecessorCells {
+			return nil, fmt.Errorf("%w: margin %d exceeds %d", ErrTooLarge, c.margin, maxPredecessorCells)
+		}
+		minX, minY, maxX, maxY, ok := b.bounds()
+		if !ok {
+			minX, minY, maxX, maxY = 0, 0, -1, -1
+		}
+		c.x, c.y = b.x+minX-c.margin, b.y+minY-c.margin
+		c.width, c.height = maxX-minX+1+2*c.margin, maxY-minY+1+2*c.margin
+	}
+	if c.width < 0 || c.height < 0 {
+		return nil, fmt.Errorf("%w: negative region %dx%d", ErrInvalidBoard, c.width, c.height)
+	}
+	if c.width > maxPredecessorCells || c.height > maxPredecessorCells ||
+		(c.height > 0 && c.width > maxPredecessorCells/c.height) {
+		return nil, fmt.Errorf("%w: region %dx%d exceeds %d cells", ErrTooLarge, c.width, c.height, maxPredecessorCells)
+	}
+	s := newPredecessorSearch(b, r, c)
+	parent, err := s.run(ctx)
+	if c.progress != nil {
+		c.progress(s.progress())
+	}
+	return parent, err
+}
+
+// predecessorSearch is the state of a backtracking predecessor search. The
+// cells of the next generation that depend on the region are tracked by
+// the ternary code of their neighbourhood, with a digit per cell of the
+// neighbourhood in row major order that is 0 when dead, 1 when alive, and 2
+// when not yet assigned.
+type predecessorSearch struct {
+	config predecessorConfig
+	// cells is the number of cells in the region
+	cells int
+	// codes holds the neighbourhood code of every cell of the next
+	// generation within a cell of the region, in row major order
+	codes []int
+	// alive holds whether each of those cells is alive in the pattern
+	alive []bool
+	// beyond reports whether the pattern has live cells further from the
+	// region, which no parent within it can give birth to
+	beyond bool
+	// possible reports whether a cell whose neighbourhood has the given code
+	// can still become alive or dead
+	possible *[2][]bool
+	values   []int8
+	depth    int
+	nodes    int64
+	explored float64
+}
+
+// pow3 holds the powers of three used by neighbourhood codes.
+var pow3 = [10]int{1, 3, 9, 27, 81, 243, 729, 2187, 6561, 19683}
+
+// newPredecessorSearch prepares a predecessor search for the pattern.
+func newPredecessorSearch(b *Board, r Rule, c predecessorConfig) *predecessorSearch {
+	s := &predecessorSearch{
+		config:   c,
+		cells:    c.width * c.height,
+		possible: possibleCodes(r),
+	}
+	s.values = make([]int8, s.cells)
+	cw, ch := c.width+2, c.height+2
+	s.codes = make([]int, cw*ch)
+	s.alive = make([]bool, cw*ch)
+	population := 0
+	for j := 0; j < ch; j++ {
+		for i := 0; i < cw; i++ {
+			code := 0
+			for k := 0; k < 9; k++ {
+				// a neighbour of the cell is unknown when within the region
+				px, py := i-1+k%3-1, j-1+k/3-1
+				if px >= 0 && py >= 0 && px < c.width && py < c.height {
+					code += 2 * pow3[k]
+				}
+			}
+			s.codes[j*cw+i] = code
+			s.alive[j*cw+i] = b.Alive(c.x-1+i-b.x, c.y-1+j-b.y)
+			if s.alive[j*cw+i] {
+				population++
+			}
+		}
+	}
+	s.beyond = population != b.Population()
+	return s
+}
+
+// possibleCodeTables caches the tables of possible neighbourhood codes of
+// each rule.
+var possibleCodeTables sync.Map
+
+// possibleCodes returns whether a cell whose neighbourhood has each code can
+// become dead, and whether it can become alive, under the rule.
+func possibleCodes(r Rule) *[2][]bool {
+	if t, ok := possibleCodeTables.Load(r.table); ok {
+		return t.(*[2][]bool)
+	}
+	var t [2][]bool
+	t[0], t[1] = make([]bool, pow3[9]), make([]bool, pow3[9])
+	// assigning an unknown digit lowers the code, so every completion of a
+	// code is computed before it
+	for code := range t[0] {
+		unknown := -1
+		idx := 0
+		for k, rest := 0, code; k < 9; k, rest = k+1, rest/3 {
+			switch rest % 3 {
+			case 1:
+				idx |= 1 << k
+			case 2:
+				unknown = k
+			}
+		}
+		if unknown < 0 {
+			next := r.table[idx]
+			t[0][code], t[1][code] = !next, next
+			continue
+		}
+		dead, alive := code-2*pow3[unknown], code-pow3[unknown]
+		t[0][code] = t[0][dead] || t[0][alive]
+		t[1][code] = t[1][dead] || t[1][alive]
+	}
+	possibleCodeTables.Store(r.table, &t)
+	return &t
+}
+
+// run searches for a parent, returning it trimmed to its live cells.
+func (s *predecessorSearch) run(ctx context.Context) (*Board, error) {
+	c := s.config
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.beyond {
+		return nil, s.gardenOfEden()
+	}
+	for i, code := range s.codes {
+		if !s.ok(i, code) {
+			return nil, s.gardenOfEden()
+		}
+	}
+
+	p := 0
+	if s.cells > 0 {
+		s.values[0] = -1
+	}
+	for p < s.cells {
+		if p < 0 {
+			return nil, s.gardenOfEden()
+		}
+		s.nodes++
+		if s.nodes%progressNodes == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if c.progress != nil {
+				s.depth = p
+				c.progress(s.progress())
+			}
+		}
+
+		v := s.values[p]
+		if v >= 0 {
+			s.assign(p, v, -1)
+		}
+		if v == 1 {
+			// both states of the cell were tried
+			p--
+			continue
+		}
+		s.values[p] = v + 1
+		if s.assign(p, v+1, 1) {
+			p++
+			if p < s.cells {
+				s.values[p] = -1
+			}
+		} else {
+			s.explored += math.Ldexp(1, -(p + 1))
+		}
+	}
+
+	s.depth = s.cells
+	parent := NewBoard(c.width, c.height)
+	parent.x, parent.y = c.x, c.y
+	for i, v := range s.values {
+		parent.cells[i] = uint8(v)
+	}
+	return trimPlane(parent), nil
+}
+
+// assign the state of the parent cell p, updating the codes of the cells
+// of the next generation around it, or undo the assignment when dir is -1.
+// It reports whether every one of those cells can still become its state
+// in the pattern.
+func (s *predecessorSearch) assign(p int, state int8, dir int) bool {
+	cw := s.config.width + 2
+	px, py := p%s.config.width, p/s.config.width
+	ok := true
+	for k := 0; k < 9; k++ {
+		// the cell of the next generation that sees the parent cell as its
+		// kth neighbour
+		i, j := px+1+1-k%3, py+1+1-k/3
+		idx := j*cw + i
+		s.codes[idx] += dir * (int(state) - 2) * pow3[k]
+		if dir > 0 && !s.ok(idx, s.codes[idx]) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// ok reports whether the cell of the next generation with the given index
+// and neighbourhood code can still become its state in the pattern.
+func (s *predecessorSearch) ok(idx, code int) bool {
+	if s.alive[idx] {
+		return s.possible[1][code]
+	}
+	return s.possible[0][code]
+}
+
+// gardenOfEden returns the error reported when there is no predecessor.
+func (s *predecessorSearch) gardenOfEden() error {
+	s.explored = 1
+	c := s.config
+	return fmt.Errorf("%w: no predecessor within the %dx%d region at %d,%d", ErrGardenOfEden, c.width, c.height, c.x, c.y)
+}
+
+// progress returns the progress of the search.
+func (s *predecessorSearch) progress() PredecessorProgress {
+	return PredecessorProgress{
+		Nodes:    s.nodes,
+		Depth:    s.depth,
+		Cells:    s.cells,
+		Explored: s.explored,
+	}
+}
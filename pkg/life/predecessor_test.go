@@ -0,0 +1,133 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestPredecessor(t *testing.T) {
+	cases := []struct {
+		name   string
+		rule   string
+		parent []string
+	}{
+		{name: "blinker", rule: "B3/S23", parent: []string{"OOO"}},
+		{name: "glider", rule: "B3/S23", parent: []string{".O.", "..O", "OOO"}},
+		{name: "empty", rule: "B3/S23", parent: []string{"O.."}},
+		{name: "highlife", rule: "B36/S23", parent: []string{"OO.", "O.O", ".OO"}},
+		{name: "hexagonal", rule: "B2/S34H", parent: []string{"O.", ".O"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, _ := ParseRule(tc.rule)
+			b, _ := NewBoardFromRows(tc.parent)
+			b.SetOrigin(5, -3)
+			want := Step(b, rule)
+			var progress PredecessorProgress
+			parent, err := Predecessor(context.Background(), want, rule, WithMargin(2), WithProgress(func(p PredecessorProgress) {
+				progress = p
+			}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := Step(parent, rule); !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+			if progress.Depth != progress.Cells || progress.Explored >= 1 {
+				t.Errorf("unexpected progress %+v", progress)
+			}
+		})
+	}
+}
+
+// TestPredecessorExhaustive compares the search with trying every parent
+// within a small region.
+func TestPredecessorExhaustive(t *testing.T) {
+	rng := rand.New(rand.NewPCG(15, 16))
+	const size = 4
+	children := make(map[string]bool)
+	for bits := 0; bits < 1<<(size*size); bits++ {
+		parent := NewBoard(size, size)
+		for i := range parent.cells {
+			parent.cells[i] = uint8(bits >> i & 1)
+		}
+		children[boardKey(Step(parent, Conway))] = true
+	}
+
+	for i := 0; i < 50; i++ {
+		// a random target is usually a Garden of Eden, so half are children
+		target := randomBoard(rng, size+2, size+2, 0.3)
+		target.SetOrigin(-1, -1)
+		if i%2 == 0 {
+			target = Step(randomBoard(rng, size, size, 0.4), Conway)
+		}
+		target = trimPlane(target)
+		parent, err := Predecessor(context.Background(), target, Conway, WithRegion(0, 0, size, size))
+		if children[boardKey(target)] {
+			if err != nil {
+				t.Errorf("expected a predecessor of:\n%s\ngot %v", target, err)
+			} else if got := Step(parent, Conway); !target.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", target, got)
+			}
+		} else if !errors.Is(err, ErrGardenOfEden) {
+			t.Errorf("expected %v for:\n%s\ngot %v", ErrGardenOfEden, target, err)
+		}
+	}
+}
+
+// boardKey returns a string identifying the position and cells of a board.
+func boardKey(b *Board) string {
+	x, y := b.Origin()
+	return fmt.Sprintf("%d,%d\n%s", x, y, b)
+}
+
+func TestPredecessorGardenOfEden(t *testing.T) {
+	block, _ := NewBoardFromRows([]string{"OO", "OO"})
+	_, err := Predecessor(context.Background(), block, Conway, WithRegion(0, 0, 1, 1))
+	if !errors.Is(err, ErrGardenOfEden) {
+		t.Errorf("expected %v, got %v", ErrGardenOfEden, err)
+	}
+
+	// a lone cell beside an empty region cannot be born
+	cell, _ := NewBoardFromRows([]string{"O"})
+	cell.SetOrigin(10, 10)
+	_, err = Predecessor(context.Background(), cell, Conway, WithRegion(0, 0, 3, 3))
+	if !errors.Is(err, ErrGardenOfEden) {
+		t.Errorf("expected %v, got %v", ErrGardenOfEden, err)
+	}
+}
+
+func TestPredecessorUnsupported(t *testing.T) {
+	for _, rs := range []string{"B3/S23:T10,10", "B2/S/C3", "R2,C0,M1,S3..5,B3..4,NM"} {
+		rule, _ := ParseRule(rs)
+		if _, err := Predecessor(context.Background(), NewBoard(1, 1), rule); !errors.Is(err, ErrUnsupportedRule) {
+			t.Errorf("%s: expected %v, got %v", rs, ErrUnsupportedRule, err)
+		}
+	}
+}
+
+func TestPredecessorTooLarge(t *testing.T) {
+	b := NewBoard(1, 3)
+	cases := []struct {
+		name string
+		opt  PredecessorOption
+	}{
+		{name: "margin", opt: WithMargin(1 << 61)},
+		{name: "negative margin", opt: WithMargin(-1 << 61)},
+		{name: "overflowing region", opt: WithRegion(0, 0, 1<<32, 1<<32)},
+		{name: "wide region", opt: WithRegion(0, 0, 1<<21, 1)},
+		{name: "large region", opt: WithRegion(0, 0, 1<<11, 1<<11)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Predecessor(context.Background(), b, Conway, tc.opt); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("expected %v, got %v", ErrTooLarge, err)
+			}
+		})
+	}
+}
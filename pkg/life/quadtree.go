@@ -0,0 +1,242 @@
+package life
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/rydelll/conway/pkg/logging"
+)
+
+const (
+	// minTreeLevel is the smallest level of the root of a quadtree, whose
+	// leaves are 8x8 squares in macrocell files.
+	minTreeLevel = 3
+	// maxTreeLevel is the largest level of the root of a quadtree, whose
+	// coordinates must fit in 64 bits.
+	maxTreeLevel = 62
+)
+
+// Quadtree is a two-state pattern on an unbounded plane stored as a HashLife
+// quadtree, where identical regions share a node. Memory is proportional to
+// the number of distinct regions rather than the area or population, so
+// patterns far too large for a [Board], such as metapixels, can be held and
+// advanced with [HashLife.AdvanceTree]. The root of the tree is centered on
+// the origin, as in macrocell files. A quadtree is immutable.
+type Quadtree struct {
+	root *node
+}
+
+// treeBuilder hash-conses the nodes of quadtrees built outside of a
+// [HashLife].
+type treeBuilder struct {
+	nodes   map[quad]*node
+	on, off *node
+	empty   []*node
+}
+
+// newTreeBuilder creates a builder without any nodes.
+func newTreeBuilder() *treeBuilder {
+	tb := &treeBuilder{
+		nodes: make(map[quad]*node),
+		on:    &node{population: 1},
+		off:   &node{},
+	}
+	tb.empty = []*node{tb.off}
+	return tb
+}
+
+// leaf returns the level 0 node for a live or dead cell.
+func (tb *treeBuilder) leaf(alive bool) *node {
+	if alive {
+		return tb.on
+	}
+	return tb.off
+}
+
+// join returns the node with the given quadrants, creating it if it does not
+// already exist.
+func (tb *treeBuilder) join(nw, ne, sw, se *node) *node {
+	key := quad{nw, ne, sw, se}
+	if n, ok := tb.nodes[key]; ok {
+		return n
+	}
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level:      nw.level + 1,
+		population: saturatingAdd(saturatingAdd(nw.population, ne.population), saturatingAdd(sw.population, se.population)),
+	}
+	tb.nodes[key] = n
+	return n
+}
+
+// emptyNode returns the node of the given level with no live cells.
+func (tb *treeBuilder) emptyNode(level int) *node {
+	for len(tb.empty) <= level {
+		e := tb.empty[len(tb.empty)-1]
+		tb.empty = append(tb.empty, tb.join(e, e, e, e))
+	}
+	return tb.empty[level]
+}
+
+// NewQuadtree creates a [Quadtree] from the cells of a board, placed at the
+// origin of the board. Cells in any state other than dead are alive.
+func NewQuadtree(b *Board) *Quadtree {
+	tb := newTreeBuilder()
+	x0, y0 := int64(b.x), int64(b.y)
+	x1, y1 := x0+int64(b.width), y0+int64(b.height)
+	level := minTreeLevel
+	for -(int64(1)<<(level-1)) > min(x0, y0) || int64(1)<<(level-1) < max(x1, y1) {
+		level++
+	}
+
+	var build func(x, y int64, level int) *node
+	build = func(x, y int64, level int) *node {
+		size := int64(1) << level
+		if x >= x1 || y >= y1 || x+size <= x0 || y+size <= y0 {
+			return tb.emptyNode(level)
+		}
+		if level == 0 {
+			return tb.leaf(b.cells[int(y-y0)*b.width+int(x-x0)] != 0)
+		}
+		half := size / 2
+		return tb.join(
+			build(x, y, level-1),
+			build(x+half, y, level-1),
+			build(x, y+half, level-1),
+			build(x+half, y+half, level-1),
+		)
+	}
+	origin := -(int64(1) << (level - 1))
+	return &Quadtree{root: build(origin, origin, level)}
+}
+
+// Level returns the level of the root of the tree, which is a square of
+// 2^level cells centered on the origin.
+func (q *Quadtree) Level() int {
+	return q.root.level
+}
+
+// Population returns the number of live cells, saturating at the maximum
+// uint64.
+func (q *Quadtree) Population() uint64 {
+	return q.root.population
+}
+
+// Bounds returns the smallest inclusive rectangle containing every live
+// cell. It is not ok when the pattern is empty.
+func (q *Quadtree) Bounds() (minX, minY, maxX, maxY int64, ok bool) {
+	r, ok := nodeBounds(q.root, make(map[*node]rect))
+	origin := q.origin()
+	return origin + r.minX, origin + r.minY, origin + r.maxX, origin + r.maxY, ok
+}
+
+// Board converts the pattern into a board trimmed to its live cells, which
+// must fit within a maxSize by maxSize board. An empty pattern is an empty
+// board at the origin.
+func (q *Quadtree) Board(maxSize int) (*Board, error) {
+	return nodeBoard(q.root, q.origin(), q.origin(), maxSize)
+}
+
+// MarshalBinary implements the [encoding.BinaryMarshaler] interface. The
+// encoding is a macrocell file.
+func (q *Quadtree) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteMacrocell(&buf, &PatternFile{Tree: q}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface.
+func (q *Quadtree) UnmarshalBinary(data []byte) error {
+	p, err := ReadMacrocell(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	q.root = p.Tree.root
+	return nil
+}
+
+// origin returns the coordinates of the top left cell of the root.
+func (q *Quadtree) origin() int64 {
+	return -(int64(1) << (q.root.level - 1))
+}
+
+// AdvanceTree computes the nth generation after the quadtree without
+// converting it into a board, so the pattern may be far larger than any
+// board. The result is shrunk to the smallest root centered on the origin
+// that holds its live cells. AdvanceTree stops early if the context is
+// cancelled, and the cache statistics are logged with the logger from the
+// context.
+func (h *HashLife) AdvanceTree(ctx context.Context, q *Quadtree, n int64) (*Quadtree, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("life: negative generations %d", n)
+	}
+	h.ctx = ctx
+	defer func() { h.ctx = context.Background() }()
+
+	root, err := h.fromTree(q)
+	if err != nil {
+		return nil, err
+	}
+	origin := q.origin()
+	root, _, _, err = h.advance(root, origin, origin, uint64(n))
+	logger := logging.FromContext(ctx)
+	logger.Info("hashlife advance", slog.Int64("generations", n), slog.Any("stats", h.Stats()))
+	if err != nil {
+		return nil, err
+	}
+	// advancing keeps the root centered on the origin but leaves empty
+	// space around the pattern
+	err = h.guard(func() {
+		for root.level > minTreeLevel && h.centered(root) {
+			root = h.join(root.nw.se, root.ne.sw, root.sw.ne, root.se.nw)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root.level > maxTreeLevel {
+		return nil, fmt.Errorf("%w: pattern exceeds level %d", ErrTooLarge, maxTreeLevel)
+	}
+	return &Quadtree{root: root}, nil
+}
+
+// fromTree returns the node of the cache with the same cells as the root of
+// the quadtree, adding the nodes of the tree to the cache.
+func (h *HashLife) fromTree(q *Quadtree) (root *node, err error) {
+	err = h.guard(func() {
+		seen := make(map[*node]*node)
+		var intern func(n *node) *node
+		intern = func(n *node) *node {
+			if n.level == 0 {
+				return h.leaf(n.population != 0)
+			}
+			if m, ok := seen[n]; ok {
+				return m
+			}
+			m := h.join(intern(n.nw), intern(n.ne), intern(n.sw), intern(n.se))
+			seen[n] = m
+			return m
+		}
+		root = intern(q.root)
+	})
+	return root, err
+}
+
+// guard runs fn, returning the error of any computation it aborts.
+func (h *HashLife) guard(fn func()) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			abort, ok := p.(hashLifeAbort)
+			if !ok {
+				panic(p)
+			}
+			err = abort.err
+		}
+	}()
+	fn()
+	return nil
+}
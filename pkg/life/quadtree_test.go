@@ -0,0 +1,108 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewQuadtree(t *testing.T) {
+	cases := []struct {
+		name  string
+		rows  []string
+		x, y  int
+		level int
+	}{
+		{name: "origin", rows: []string{".O.", "..O", "OOO"}, level: 3},
+		{name: "negative", rows: []string{"OO", "OO"}, x: -4, y: -4, level: 3},
+		{name: "far", rows: []string{"O..O"}, x: 1000, y: -30, level: 11},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.rows)
+			b.SetOrigin(tc.x, tc.y)
+			q := NewQuadtree(b)
+			if q.Level() != tc.level {
+				t.Errorf("expected level %d, got %d", tc.level, q.Level())
+			}
+			if q.Population() != uint64(b.Population()) {
+				t.Errorf("expected population %d, got %d", b.Population(), q.Population())
+			}
+			got, err := q.Board(1024)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := b.Trim(); !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+			minX, minY, maxX, maxY, ok := q.Bounds()
+			wx, wy := got.Origin()
+			if diff := cmp.Diff([]int64{int64(wx), int64(wy), int64(wx + got.Width() - 1), int64(wy + got.Height() - 1)}, []int64{minX, minY, maxX, maxY}); !ok || diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	empty := NewQuadtree(NewBoard(0, 0))
+	if _, _, _, _, ok := empty.Bounds(); ok || empty.Population() != 0 {
+		t.Errorf("expected an empty tree, got population %d", empty.Population())
+	}
+}
+
+func TestAdvanceTree(t *testing.T) {
+	soup := NewBoard(16, 16)
+	rng := rand.New(rand.NewPCG(7, 8))
+	for i := 0; i < 100; i++ {
+		soup.Set(rng.IntN(16), rng.IntN(16), true)
+	}
+	soup.SetOrigin(-20, 3)
+
+	for _, n := range []int64{0, 1, 37, 300} {
+		h, _ := NewHashLife(Conway)
+		q, err := h.AdvanceTree(context.Background(), NewQuadtree(soup), n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := q.Board(1024)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := StepN(soup, Conway, int(n)).Trim(); !want.Equal(got) {
+			t.Errorf("%d generations: expected:\n%s\ngot:\n%s", n, want, got)
+		}
+	}
+}
+
+func TestAdvanceTreeGlider(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	q, err := HashLifeEngine().AdvanceTree(context.Background(), NewQuadtree(glider), Conway, 1<<40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	minX, minY, _, _, _ := q.Bounds()
+	if diff := cmp.Diff([]int64{1 << 38, 1 << 38}, []int64{minX, minY}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	// the root is shrunk to the smallest level holding the glider
+	if q.Level() != 40 {
+		t.Errorf("expected level 40, got %d", q.Level())
+	}
+}
+
+func TestAdvanceTreeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	soup := NewBoard(64, 64)
+	rng := rand.New(rand.NewPCG(9, 10))
+	for i := 0; i < 2000; i++ {
+		soup.Set(rng.IntN(64), rng.IntN(64), true)
+	}
+	h, _ := NewHashLife(Conway)
+	if _, err := h.AdvanceTree(ctx, NewQuadtree(soup), 1<<20); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,320 @@
+package life
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// rleLineWidth is the maximum length of a line of cells written to an RLE
+// file.
+const rleLineWidth = 70
+
+// ReadRLE reads a pattern in the extended RLE format used by Golly and the
+// LifeWiki. Comment lines give the name (#N), author (#O), and description
+// (#C or #c) of the pattern, and the position of its top left cell (#P or
+// #R, or a "#CXRLE Pos=x,y Gen=n" line). They are followed by a header such
+// as "x = 3, y = 3, rule = B3/S23" and the cells as runs of states, where b
+// or '.' is dead, o or A is alive, B through X are the states 2 through 24,
+// and higher states are prefixed by one of the letters p through y. Rows end
+// with '$' and the pattern ends with '!'.
+//
+// It fails with [ErrTooLarge] when the pattern is wider or taller than
+// maxSize.
+func ReadRLE(r io.Reader, maxSize int) (*PatternFile, error) {
+	p := &PatternFile{}
+	var x, y int
+	scanner := bufio.NewScanner(r)
+	header := false
+	for !header && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			var err error
+			if x, y, err = p.readRLEComment(line, x, y); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+			}
+		default:
+			width, height, err := p.readRLEHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+			}
+			if width > maxSize || height > maxSize {
+				return nil, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrTooLarge, width, height, maxSize, maxSize)
+			}
+			p.Board = NewBoard(width, height)
+			p.Board.x, p.Board.y = x, y
+			header = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("%w: expected a header such as x = 3, y = 3", ErrInvalidPattern)
+	}
+
+	var body strings.Builder
+	for scanner.Scan() {
+		body.WriteString(scanner.Text())
+		body.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := readRLECells(body.String(), p.Board); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	return p, nil
+}
+
+// readRLEComment reads a comment line into the pattern, returning the
+// position of the top left cell given by the line or the position given so
+// far.
+func (p *PatternFile) readRLEComment(line string, x, y int) (int, int, error) {
+	tag, text := line[:min(len(line), 2)], strings.TrimSpace(line[min(len(line), 2):])
+	switch tag {
+	case "#N":
+		p.Name = text
+	case "#O":
+		p.Author = text
+	case "#r":
+		p.Rule = text
+	case "#P", "#R":
+		if _, err := fmt.Sscan(text, &x, &y); err != nil {
+			return 0, 0, fmt.Errorf("expected %s x y, got %q", tag, line)
+		}
+	case "#C", "#c":
+		if xrle, ok := strings.CutPrefix(text, "XRLE"); ok {
+			return p.readXRLE(xrle, x, y)
+		}
+		p.Comments = append(p.Comments, text)
+	}
+	return x, y, nil
+}
+
+// readXRLE reads the settings of a "#CXRLE" line.
+func (p *PatternFile) readXRLE(s string, x, y int) (int, int, error) {
+	for _, field := range strings.Fields(s) {
+		key, value, _ := strings.Cut(field, "=")
+		var err error
+		switch key {
+		case "Pos":
+			px, py, _ := strings.Cut(value, ",")
+			if x, err = strconv.Atoi(px); err == nil {
+				y, err = strconv.Atoi(py)
+			}
+		case "Gen":
+			p.Generation, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid XRLE %s %q", key, value)
+		}
+	}
+	return x, y, nil
+}
+
+// readRLEHeader reads a header line such as "x = 3, y = 3, rule = B3/S23"
+// into the pattern, returning the width and height. The rule runs to the
+// end of the line, since rulestrings may contain commas.
+func (p *PatternFile) readRLEHeader(line string) (width, height int, err error) {
+	rest := line
+	seen := make(map[string]bool)
+	for rest != "" {
+		var field string
+		field, rest, _ = strings.Cut(rest, ",")
+		key, value, ok := cutSetting(field, "=")
+		if !ok {
+			return 0, 0, fmt.Errorf("expected key = value in header %q", line)
+		}
+		seen[key] = true
+		switch key {
+		case "x":
+			width, err = strconv.Atoi(value)
+		case "y":
+			height, err = strconv.Atoi(value)
+		case "rule":
+			if rest != "" {
+				value = strings.TrimSpace(value + "," + rest)
+			}
+			p.Rule, rest = value, ""
+		}
+		if err != nil || width < 0 || height < 0 {
+			return 0, 0, fmt.Errorf("invalid size in header %q", line)
+		}
+	}
+	if !seen["x"] || !seen["y"] {
+		return 0, 0, fmt.Errorf("expected x and y in header %q", line)
+	}
+	return width, height, nil
+}
+
+// readRLECells reads the runs of cells of an RLE file onto the board, up to
+// the terminating '!'.
+func readRLECells(s string, b *Board) error {
+	x, y := 0, 0
+	count := 0
+	prefix := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if prefix != 0 {
+				return fmt.Errorf("expected a state after %q", prefix)
+			}
+			count = count*10 + int(c-'0')
+			if count > b.width*b.height+b.height {
+				return fmt.Errorf("run of %d cells exceeds the %dx%d header", count, b.width, b.height)
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '!':
+			return nil
+		case c >= 'p' && c <= 'y' && prefix == 0:
+			prefix = c
+			continue
+		}
+
+		n := max(count, 1)
+		count = 0
+		if c == '$' {
+			if prefix != 0 {
+				return fmt.Errorf("expected a state after %q", prefix)
+			}
+			x, y = 0, y+n
+			continue
+		}
+		state, err := rleState(prefix, c)
+		if err != nil {
+			return err
+		}
+		prefix = 0
+		if x+n > b.width || y >= b.height {
+			return fmt.Errorf("cells beyond the %dx%d header", b.width, b.height)
+		}
+		for ; n > 0; n-- {
+			b.cells[y*b.width+x] = state
+			x++
+		}
+	}
+	return errors.New("expected '!' at the end of the cells")
+}
+
+// rleState returns the state written as the letter c after an optional
+// prefix letter.
+func rleState(prefix, c byte) (uint8, error) {
+	state := 0
+	switch {
+	case prefix == 0 && (c == 'b' || c == '.'):
+		return 0, nil
+	case prefix == 0 && c == 'o':
+		return 1, nil
+	case c >= 'A' && c <= 'X':
+		state = int(c-'A') + 1
+		if prefix != 0 {
+			state += 24 * int(prefix-'p'+1)
+		}
+	default:
+		if prefix != 0 {
+			return 0, fmt.Errorf("unexpected state %q", string([]byte{prefix, c}))
+		}
+		return 0, fmt.Errorf("unexpected state %q", c)
+	}
+	if state >= MaxStates {
+		return 0, fmt.Errorf("state %d exceeds the maximum of %d", state, MaxStates-1)
+	}
+	return uint8(state), nil
+}
+
+// WriteRLE writes a pattern in the extended RLE format read by [ReadRLE].
+// Boards with only dead and live cells are written with b and o, and other
+// boards with '.' and the letters from A. The position and generation of
+// the board are written to a "#CXRLE" line when either is not zero, and
+// lines of cells are wrapped at 70 columns.
+func WriteRLE(w io.Writer, p *PatternFile) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range []struct {
+		tag  string
+		text string
+	}{{"#N", p.Name}, {"#O", p.Author}} {
+		if c.text != "" {
+			fmt.Fprintf(bw, "%s %s\n", c.tag, c.text)
+		}
+	}
+	for _, comment := range p.Comments {
+		fmt.Fprintf(bw, "#C %s\n", comment)
+	}
+	b := p.Board
+	if b.x != 0 || b.y != 0 || p.Generation != 0 {
+		fmt.Fprintf(bw, "#CXRLE Pos=%d,%d Gen=%d\n", b.x, b.y, p.Generation)
+	}
+	fmt.Fprintf(bw, "x = %d, y = %d", b.width, b.height)
+	if p.Rule != "" {
+		fmt.Fprintf(bw, ", rule = %s", p.Rule)
+	}
+	bw.WriteByte('\n')
+
+	multistate := b.MaxState() > 1
+	line := 0
+	write := func(n int, tag string) {
+		token := tag
+		if n > 1 {
+			token = strconv.Itoa(n) + tag
+		}
+		if line+len(token) > rleLineWidth {
+			bw.WriteByte('\n')
+			line = 0
+		}
+		bw.WriteString(token)
+		line += len(token)
+	}
+	// rows end with '$' before the next row with live cells, so trailing
+	// blank rows are left out
+	last := 0
+	for y := 0; y < b.height; y++ {
+		row := b.cells[y*b.width : (y+1)*b.width]
+		end := len(row)
+		for end > 0 && row[end-1] == 0 {
+			end--
+		}
+		if end == 0 {
+			continue
+		}
+		if y > last {
+			write(y-last, "$")
+		}
+		last = y
+		for x := 0; x < end; {
+			run := 1
+			for x+run < end && row[x+run] == row[x] {
+				run++
+			}
+			write(run, rleTag(row[x], multistate))
+			x += run
+		}
+	}
+	write(1, "!")
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// rleTag returns the letters of a state in an RLE file.
+func rleTag(state uint8, multistate bool) string {
+	switch {
+	case !multistate && state == 0:
+		return "b"
+	case !multistate:
+		return "o"
+	case state == 0:
+		return "."
+	case state <= 24:
+		return string(rune('A' + state - 1))
+	default:
+		return string([]rune{rune('p' + (state-25)/24), rune('A' + (state-25)%24)})
+	}
+}
@@ -0,0 +1,162 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadRLE(t *testing.T) {
+	const glider = `#N Glider
+#O Richard K. Guy
+#C The smallest spaceship.
+#C Found in 1969.
+#CXRLE Pos=-1,2 Gen=4
+x = 3, y = 3, rule = B3/S23
+bob$2bo$3o!
+`
+	p, err := ReadRLE(strings.NewReader(glider), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	board, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	board.SetOrigin(-1, 2)
+	want := &PatternFile{
+		Name:       "Glider",
+		Author:     "Richard K. Guy",
+		Comments:   []string{"The smallest spaceship.", "Found in 1969."},
+		Rule:       "B3/S23",
+		Generation: 4,
+		Board:      board,
+	}
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReadRLECells(t *testing.T) {
+	cases := []struct {
+		name string
+		rle  string
+		rule string
+		want string
+	}{
+		{name: "blank rows", rle: "x = 2, y = 4\no2$o!", want: "O.\n..\nO.\n.."},
+		{name: "multistate", rle: "x = 4, y = 1, rule = WireWorld\n.ABC!", rule: "WireWorld", want: ".OBC"},
+		{name: "prefixed state", rle: "x = 2, y = 1\n.pA!", want: ".Y"},
+		{name: "comma rule", rle: "x = 1, y = 1, rule = R2,C0,M1,S3..5,B3..4,NM\no!", rule: "R2,C0,M1,S3..5,B3..4,NM", want: "O"},
+		{name: "wrapped", rle: "x = 3, y = 2\n2o\nb$\n3o\n!", want: "OO.\nOOO"},
+		{name: "ignore trailing", rle: "x = 1, y = 1\no!\nnot cells", want: "O"},
+		{name: "position", rle: "#P 3 -4\nx = 1, y = 1\no!", want: "O"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ReadRLE(strings.NewReader(tc.rle), 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Rule != tc.rule {
+				t.Errorf("expected rule %q, got %q", tc.rule, p.Rule)
+			}
+			if diff := cmp.Diff(tc.want, p.Board.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadRLEInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		rle  string
+		err  error
+	}{
+		{name: "empty", rle: "", err: ErrInvalidPattern},
+		{name: "no header", rle: "#C comment\nbo$ob!", err: ErrInvalidPattern},
+		{name: "missing y", rle: "x = 1\no!", err: ErrInvalidPattern},
+		{name: "negative size", rle: "x = -1, y = 1\no!", err: ErrInvalidPattern},
+		{name: "beyond width", rle: "x = 1, y = 1\n2o!", err: ErrInvalidPattern},
+		{name: "beyond height", rle: "x = 1, y = 1\no$o!", err: ErrInvalidPattern},
+		{name: "unterminated", rle: "x = 1, y = 1\no", err: ErrInvalidPattern},
+		{name: "unknown state", rle: "x = 1, y = 1\nz!", err: ErrInvalidPattern},
+		{name: "state too high", rle: "x = 1, y = 1\npB!", err: ErrInvalidPattern},
+		{name: "dangling prefix", rle: "x = 1, y = 1\np$!", err: ErrInvalidPattern},
+		{name: "bad position", rle: "#P a b\nx = 1, y = 1\no!", err: ErrInvalidPattern},
+		{name: "too large", rle: "x = 101, y = 1\no!", err: ErrTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ReadRLE(strings.NewReader(tc.rle), 100); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	glider, _ := NewBoardFromRows([]string{"...", ".O.", "..O", "OOO", "..."})
+	wire, _ := NewBoardFromRows([]string{"AB.C", "", "CCC"})
+	wire.SetOrigin(2, -3)
+	cases := []struct {
+		name    string
+		pattern *PatternFile
+		want    string
+	}{
+		{
+			name:    "glider",
+			pattern: &PatternFile{Name: "Glider", Comments: []string{"A spaceship."}, Rule: "B3/S23", Board: glider},
+			want:    "#N Glider\n#C A spaceship.\nx = 3, y = 5, rule = B3/S23\n$bo$2bo$3o!\n",
+		},
+		{
+			name:    "multistate",
+			pattern: &PatternFile{Rule: "WireWorld", Generation: 7, Board: wire},
+			want:    "#CXRLE Pos=2,-3 Gen=7\nx = 4, y = 3, rule = WireWorld\nAB.C2$3C!\n",
+		},
+		{
+			name:    "empty",
+			pattern: &PatternFile{Board: NewBoard(2, 2)},
+			want:    "x = 2, y = 2\n!\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteRLE(&sb, tc.pattern); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, sb.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteRLEWrap(t *testing.T) {
+	b := NewBoard(200, 3)
+	for i := range b.cells {
+		b.cells[i] = uint8(i % 2)
+	}
+	b.cells[2*200+199] = 25
+	var sb strings.Builder
+	if err := WriteRLE(&sb, &PatternFile{Board: b}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		if len(line) > rleLineWidth {
+			t.Errorf("line of %d columns exceeds %d: %s", len(line), rleLineWidth, line)
+		}
+	}
+
+	p, err := ReadRLE(strings.NewReader(sb.String()), 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b.Equal(p.Board) {
+		t.Errorf("expected:\n%s\ngot:\n%s", b, p.Board)
+	}
+}
@@ -0,0 +1,502 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidRule when a rulestring cannot be parsed.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Conway is the rule for Conway's Game of Life.
+var Conway = mustParseRule("B3/S23")
+
+// MaxStates is the maximum number of cell states of a Generations rule.
+const MaxStates = 26
+
+// centerBit is the bit of a neighbourhood index that holds the center cell.
+const centerBit = 1 << 4
+
+// hensel holds the letters of the isotropic non-totalistic neighbourhood
+// configurations for each neighbour count in canonical order, along with
+// the neighbourhood index of a representative configuration for each letter.
+//
+// Neighbourhood indexes use a bit per cell of the 3x3 neighbourhood in row
+// major order, where bit 0 is the north west cell and bit 4 is the center.
+// Counts above four are the complement of the count below four.
+var hensel = [5]struct {
+	letters string
+	masks   []int
+}{
+	0: {letters: "", masks: []int{0}},
+	1: {letters: "ce", masks: []int{1, 2}},
+	2: {letters: "cekain", masks: []int{5, 10, 33, 3, 40, 68}},
+	3: {letters: "cekainyqjr", masks: []int{69, 42, 98, 11, 7, 13, 97, 70, 14, 41}},
+	4: {letters: "cekainyqjrtwz", masks: []int{325, 170, 99, 15, 45, 71, 101, 102, 106, 43, 105, 78, 108}},
+}
+
+// henselOrbits holds, for every neighbour count and letter, every
+// neighbourhood index equivalent to the representative configuration under
+// rotation and reflection.
+var henselOrbits = func() [9]map[byte][]int {
+	var orbits [9]map[byte][]int
+	for n := 0; n <= 8; n++ {
+		orbits[n] = make(map[byte][]int)
+		h := hensel[min(n, 8-n)]
+		for i, mask := range h.masks {
+			if n > 4 {
+				mask ^= 0x1ff &^ centerBit
+			}
+			letter := byte(0)
+			if h.letters != "" {
+				letter = h.letters[i]
+			}
+			orbits[n][letter] = symmetries(mask)
+		}
+	}
+	return orbits
+}()
+
+// Rule is a cellular automaton rule on the Moore neighbourhood. It supports
+// both outer totalistic rules, where only the number of live neighbours
+// matters, and isotropic non-totalistic rules, where the arrangement of
+// neighbours matters up to rotation and reflection.
+//
+// Generations rules have more than two states. A live cell that does not
+// survive passes through each dying state in turn before it is dead, and
+// only live cells count as neighbours.
+//
+// Hexagonal and von Neumann rules are outer totalistic rules that only
+// count some of the cells of the Moore neighbourhood.
+//
+// Larger than Life rules count neighbours in a Moore, von Neumann, or
+// circular neighbourhood of a larger range, and cells are born or survive
+// when the count is within a range.
+//
+// Custom rules are parsed from Golly rule files by [ParseRuleFile]. Those
+// with more than two states compute the next state of a cell from the
+// states of its neighbours rather than which of them are alive.
+type Rule struct {
+	name         string
+	table        [512]bool
+	states       int
+	neighborhood Neighborhood
+	ltl          ltlRule
+	custom       customRule
+	topology     Topology
+}
+
+// ParseRule parses a rulestring. Three notations are supported:
+//
+//   - B/S notation, such as "B3/S23" or "B36/S23". The slash is optional.
+//   - Golly S/B notation, such as "23/3".
+//   - Hensel isotropic notation, such as "B2-a/S12" or "B2ce3/S23-a".
+//
+// A Generations rule adds the number of states as a final section, such as
+// "B2/S/C3" or "345/2/4" in Golly S/B/C notation. It must be between 2 and
+// [MaxStates]. A final "H" evaluates the rule on the [Hexagonal]
+// neighbourhood and a final "V" on the [VonNeumann] neighbourhood, such as
+// "B2/S34H" or "B2/S013/C3V", where only digits up to the number of
+// neighbours are allowed. Larger than Life rules use Golly notation, such as
+// "R5,C0,M1,S34..58,B34..45,NM" where N is M for Moore, N for von Neumann, or
+// C for a circular neighbourhood. Any of these may be followed by a bounded
+// grid suffix, such as ":T100,80", which is parsed by [ParseTopology]. Rules
+// that give birth to cells with no neighbours require a bounded grid. Parsing
+// is case insensitive.
+func ParseRule(s string) (Rule, error) {
+	str, grid, bounded := strings.Cut(strings.TrimSpace(s), ":")
+	str = strings.ToLower(str)
+	var topo Topology
+	if bounded {
+		var err error
+		if topo, err = parseTopology(grid); err != nil {
+			return Rule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+		}
+	}
+	if strings.Contains(str, ",") {
+		return parseLtLRule(s, str, topo)
+	}
+	str, hood := cutNeighborhood(str)
+	str, states, err := cutStates(str)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+	}
+
+	var birth, survival string
+	if strings.ContainsAny(str, "bs") {
+		var err error
+		birth, survival, err = splitBS(str)
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+		}
+	} else {
+		var ok bool
+		survival, birth, ok = strings.Cut(str, "/")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w %q: expected S/B notation", ErrInvalidRule, s)
+		}
+	}
+
+	r := Rule{states: states, neighborhood: hood, topology: topo}
+	set := r.setTransitions
+	if hood != Moore {
+		set = r.setCounts
+	}
+	if err := set(birth, 0); err != nil {
+		return Rule{}, fmt.Errorf("%w %q: birth: %v", ErrInvalidRule, s, err)
+	}
+	if err := set(survival, centerBit); err != nil {
+		return Rule{}, fmt.Errorf("%w %q: survival: %v", ErrInvalidRule, s, err)
+	}
+	if r.table[0] && !r.topology.Bounded() {
+		return Rule{}, fmt.Errorf("%w %q: B0 requires a bounded grid", ErrInvalidRule, s)
+	}
+	r.name = "B" + r.transitions(0) + "/S" + r.transitions(centerBit)
+	if r.states > 2 {
+		r.name += "/C" + strconv.Itoa(r.states)
+	}
+	if letter, ok := neighborhoodSuffixes[hood]; ok {
+		r.name += string(letter)
+	}
+	r.name += r.topology.String()
+	return r, nil
+}
+
+// parseLtLRule parses the lowercase Larger than Life rulestring str, which
+// was given as s, on the given topology.
+func parseLtLRule(s, str string, topo Topology) (Rule, error) {
+	l, states, err := parseLtL(str)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+	}
+	if l.bMin == 0 && !topo.Bounded() {
+		return Rule{}, fmt.Errorf("%w %q: B0 requires a bounded grid", ErrInvalidRule, s)
+	}
+	return Rule{
+		name:     l.String(states) + topo.String(),
+		states:   states,
+		ltl:      l,
+		topology: topo,
+	}, nil
+}
+
+// neighborhoodSuffixes maps each range 1 neighbourhood other than Moore to
+// its suffix letter in a rulestring.
+var neighborhoodSuffixes = map[Neighborhood]byte{
+	Hexagonal:  'H',
+	VonNeumann: 'V',
+}
+
+// cutNeighborhood removes the neighbourhood suffix from a lowercase
+// rulestring without a bounded grid suffix. A rulestring without a suffix is
+// evaluated on the Moore neighbourhood. Neither letter is used by Hensel
+// notation, so the suffix cannot be confused with a configuration.
+func cutNeighborhood(s string) (string, Neighborhood) {
+	for hood, letter := range neighborhoodSuffixes {
+		if suffix := strings.ToLower(string(letter)); strings.HasSuffix(s, suffix) {
+			return strings.TrimSuffix(s, suffix), hood
+		}
+	}
+	return s, Moore
+}
+
+// cutStates removes the number of states section from a lowercase
+// rulestring without a bounded grid suffix. It is either a final section
+// starting with a 'c', or the third of three sections in S/B/C notation. A
+// rulestring without the section has two states.
+func cutStates(s string) (string, int, error) {
+	sections := strings.Split(s, "/")
+	last := sections[len(sections)-1]
+	var count string
+	switch {
+	case len(sections) > 1 && strings.HasPrefix(last, "c") && isDigits(last[1:]):
+		count = last[1:]
+	case len(sections) == 3 && isDigits(sections[0]) && isDigits(sections[1]) && isDigits(last):
+		count = last
+	default:
+		return s, 2, nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 2 || n > MaxStates {
+		return "", 0, fmt.Errorf("number of states %q must be between 2 and %d", count, MaxStates)
+	}
+	return strings.Join(sections[:len(sections)-1], "/"), n, nil
+}
+
+// isDigits reports whether the string only contains decimal digits. An empty
+// string is digits so that empty sections like the survival of "/2/3" parse.
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// String returns the canonical B/S form of the rule, including the bounded
+// grid suffix if there is one.
+func (r Rule) String() string {
+	return r.name
+}
+
+// States returns the number of cell states, which is two except for
+// Generations rules and custom rules.
+func (r Rule) States() int {
+	return max(r.states, 2)
+}
+
+// Range returns the distance in cells to the furthest neighbour, which is
+// one except for Larger than Life rules.
+func (r Rule) Range() int {
+	return max(r.ltl.radius, 1)
+}
+
+// Neighborhood returns the shape of the neighbourhood of each cell.
+func (r Rule) Neighborhood() Neighborhood {
+	if r.ltl.radius > 0 {
+		return r.ltl.neighborhood
+	}
+	return r.neighborhood
+}
+
+// Topology returns the grid the rule is evaluated on.
+func (r Rule) Topology() Topology {
+	return r.topology
+}
+
+// Totalistic reports whether the rule only depends on the number of live
+// neighbours in the Moore neighbourhood and not their arrangement. Rules on a
+// hexagonal or von Neumann neighbourhood are not.
+func (r Rule) Totalistic() bool {
+	if r.neighborhood != Moore || r.custom != nil {
+		return false
+	}
+	var want [2][9]bool
+	var seen [2][9]bool
+	for idx, alive := range r.table {
+		c, n := idx&centerBit>>4, bits.OnesCount(uint(idx&^centerBit))
+		if !seen[c][n] {
+			want[c][n], seen[c][n] = alive, true
+		} else if want[c][n] != alive {
+			return false
+		}
+	}
+	return true
+}
+
+// next reports whether a cell with the given neighbourhood index is alive
+// in the next generation.
+func (r Rule) next(idx int) bool {
+	return r.table[idx]
+}
+
+// nextState returns the next state of a cell with the given state and
+// neighbourhood index. The center bit of the index is set when the cell is
+// alive.
+func (r Rule) nextState(state uint8, idx int) uint8 {
+	switch {
+	case state <= 1 && r.table[idx]:
+		return 1
+	case state == 0:
+		return 0
+	default:
+		return r.decay(state)
+	}
+}
+
+// decay returns the next state of a live cell that does not survive or of a
+// dying cell. Cells decay through each dying state in turn until they die.
+func (r Rule) decay(state uint8) uint8 {
+	if int(state)+1 >= r.States() {
+		return 0
+	}
+	return state + 1
+}
+
+// setTransitions enables every neighbourhood described by a birth or
+// survival section of a rulestring, such as "2-a3", for cells with the given
+// center bit.
+func (r *Rule) setTransitions(section string, center int) error {
+	for i := 0; i < len(section); {
+		c := section[i]
+		if c < '0' || c > '8' {
+			return fmt.Errorf("unexpected character %q", c)
+		}
+		n := int(c - '0')
+		i++
+
+		negate := i < len(section) && section[i] == '-'
+		if negate {
+			i++
+		}
+		start := i
+		for i < len(section) && section[i] >= 'a' && section[i] <= 'z' {
+			i++
+		}
+		letters := section[start:i]
+		if negate && letters == "" {
+			return fmt.Errorf("expected letters after %d-", n)
+		}
+
+		selected := make(map[byte]bool)
+		for j := 0; j < len(letters); j++ {
+			if _, ok := henselOrbits[n][letters[j]]; !ok {
+				return fmt.Errorf("unexpected letter %q for %d neighbours", letters[j], n)
+			}
+			if selected[letters[j]] {
+				return fmt.Errorf("duplicate letter %q for %d neighbours", letters[j], n)
+			}
+			selected[letters[j]] = true
+		}
+		for letter, orbit := range henselOrbits[n] {
+			if letters != "" && selected[letter] == negate {
+				continue
+			}
+			for _, idx := range orbit {
+				r.table[idx|center] = true
+			}
+		}
+	}
+	return nil
+}
+
+// setCounts enables every neighbourhood with a number of live neighbours in
+// a birth or survival section, such as "34", for cells with the given center
+// bit. Only the cells of the rule's neighbourhood are counted, so the table
+// ignores the cells of the Moore neighbourhood outside of it.
+func (r *Rule) setCounts(section string, center int) error {
+	mask := r.neighborhood.mask()
+	size := bits.OnesCount(uint(mask))
+	for i := 0; i < len(section); i++ {
+		c := section[i]
+		if c < '0' || c > '9' {
+			return fmt.Errorf("unexpected character %q", c)
+		}
+		n := int(c - '0')
+		if n > size {
+			return fmt.Errorf("%d neighbours exceeds the %d cells of the neighbourhood", n, size)
+		}
+		for idx := 0; idx < len(r.table); idx++ {
+			if idx&centerBit == 0 && bits.OnesCount(uint(idx&mask)) == n {
+				r.table[idx|center] = true
+			}
+		}
+	}
+	return nil
+}
+
+// transitions returns the canonical birth or survival section of the rule
+// for cells with the given center bit. Counts with every configuration
+// enabled are written as a digit. Partially enabled counts are followed by
+// their letters, or by a minus and the missing letters when that is shorter.
+// Rules on other neighbourhoods than Moore are only written as digits.
+func (r Rule) transitions(center int) string {
+	var sb strings.Builder
+	if r.neighborhood != Moore {
+		mask := r.neighborhood.mask()
+		idx := 0
+		for n := 0; n <= bits.OnesCount(uint(mask)); n++ {
+			if r.table[idx|center] {
+				sb.WriteByte(byte('0' + n))
+			}
+			// add the lowest neighbour that is not yet alive
+			rest := mask &^ idx
+			idx |= rest & -rest
+		}
+		return sb.String()
+	}
+	for n := 0; n <= 8; n++ {
+		letters := hensel[min(n, 8-n)].letters
+		var on, off []byte
+		for j := 0; j < max(len(letters), 1); j++ {
+			var letter byte
+			if letters != "" {
+				letter = letters[j]
+			}
+			if r.table[henselOrbits[n][letter][0]|center] {
+				on = append(on, letter)
+			} else {
+				off = append(off, letter)
+			}
+		}
+
+		switch {
+		case len(on) == 0:
+		case len(off) == 0:
+			sb.WriteByte(byte('0' + n))
+		case len(on) <= len(off):
+			sb.WriteByte(byte('0' + n))
+			sb.Write(on)
+		default:
+			sb.WriteByte(byte('0' + n))
+			sb.WriteByte('-')
+			sb.Write(off)
+		}
+	}
+	return sb.String()
+}
+
+// splitBS splits a rulestring in B/S notation into its birth and survival
+// sections. The sections may appear in either order and the slash between
+// them is optional.
+func splitBS(s string) (birth, survival string, err error) {
+	var seenB, seenS bool
+	var section *string
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == 'b' && !seenB:
+			seenB, section = true, &birth
+		case c == 's' && !seenS:
+			seenS, section = true, &survival
+		case c == '/' && section != nil && i+1 < len(s) && (s[i+1] == 'b' || s[i+1] == 's'):
+		case section == nil:
+			return "", "", fmt.Errorf("unexpected character %q", c)
+		default:
+			*section += string(c)
+		}
+	}
+	if !seenB || !seenS {
+		return "", "", errors.New("expected both B and S")
+	}
+	return birth, survival, nil
+}
+
+// symmetries returns every neighbourhood index that is equivalent to the
+// given index under the eight rotations and reflections of the square.
+func symmetries(idx int) []int {
+	seen := make(map[int]bool)
+	var out []int
+	for t := 0; t < 8; t++ {
+		v := 0
+		for y := -1; y <= 1; y++ {
+			for x := -1; x <= 1; x++ {
+				if idx&(1<<((y+1)*3+x+1)) == 0 {
+					continue
+				}
+				tx, ty := x, y
+				if t&4 != 0 {
+					tx = -tx
+				}
+				for k := 0; k < t&3; k++ {
+					tx, ty = -ty, tx
+				}
+				v |= 1 << ((ty+1)*3 + tx + 1)
+			}
+		}
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// mustParseRule parses a rulestring and panics if it is invalid. It is only
+// intended for rules that are known to be valid at compile time.
+func mustParseRule(s string) Rule {
+	r, err := ParseRule(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
@@ -0,0 +1,198 @@
+package life
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxNeighbors3D is the number of neighbours of a cell in three dimensions.
+const maxNeighbors3D = 26
+
+// Rule3D is an outer totalistic rule for three dimensional Life, where each
+// cell has the 26 neighbours of the 3x3x3 cube around it. It is evaluated on
+// an unbounded space.
+type Rule3D struct {
+	name     string
+	birth    [maxNeighbors3D + 1]bool
+	survival [maxNeighbors3D + 1]bool
+}
+
+// ParseRule3D parses a three dimensional rulestring. Two notations are
+// supported:
+//
+//   - Bays notation, such as "4555" or "5766", which is the lowest and
+//     highest number of neighbours for a live cell to survive followed by
+//     the lowest and highest number for a dead cell to be born.
+//   - B/S notation with comma separated counts or ranges of counts, such as
+//     "B5/S4,5" or "B6-8/S5-7,10".
+//
+// Rules that give birth to cells with no neighbours are not supported.
+// Parsing is case insensitive.
+func ParseRule3D(s string) (Rule3D, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	var r Rule3D
+	if len(str) == 4 && isDigits(str) {
+		for i, counts := range []*[maxNeighbors3D + 1]bool{&r.survival, &r.birth} {
+			lo, hi := int(str[2*i]-'0'), int(str[2*i+1]-'0')
+			if lo > hi {
+				return Rule3D{}, fmt.Errorf("%w %q: range %d..%d is empty", ErrInvalidRule, s, lo, hi)
+			}
+			for n := lo; n <= hi; n++ {
+				counts[n] = true
+			}
+		}
+	} else {
+		birth, survival, err := splitBS(str)
+		if err != nil {
+			return Rule3D{}, fmt.Errorf("%w %q: %v", ErrInvalidRule, s, err)
+		}
+		if err := parseCounts3D(birth, &r.birth); err != nil {
+			return Rule3D{}, fmt.Errorf("%w %q: birth: %v", ErrInvalidRule, s, err)
+		}
+		if err := parseCounts3D(survival, &r.survival); err != nil {
+			return Rule3D{}, fmt.Errorf("%w %q: survival: %v", ErrInvalidRule, s, err)
+		}
+	}
+	if r.birth[0] {
+		return Rule3D{}, fmt.Errorf("%w %q: B0 is not supported", ErrInvalidRule, s)
+	}
+	r.name = r.canonical()
+	return r, nil
+}
+
+// parseCounts3D enables the neighbour counts of a comma separated list of
+// counts or ranges of counts, such as "5-7,10". An empty list enables none.
+func parseCounts3D(s string, counts *[maxNeighbors3D + 1]bool) error {
+	if s == "" {
+		return nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(item, "-")
+		if !isRange {
+			hi = lo
+		}
+		minN, err1 := strconv.Atoi(lo)
+		maxN, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || !isDigits(lo) || !isDigits(hi) {
+			return fmt.Errorf("invalid count %q", item)
+		}
+		if minN > maxN || maxN > maxNeighbors3D {
+			return fmt.Errorf("count %q must be between 0 and %d", item, maxNeighbors3D)
+		}
+		for n := minN; n <= maxN; n++ {
+			counts[n] = true
+		}
+	}
+	return nil
+}
+
+// canonical returns the rule in Bays notation if it can be written that way,
+// and in B/S notation otherwise.
+func (r Rule3D) canonical() string {
+	sLo, sHi, sOK := span3D(r.survival)
+	bLo, bHi, bOK := span3D(r.birth)
+	if sOK && bOK && sHi <= 9 && bHi <= 9 {
+		return fmt.Sprintf("%d%d%d%d", sLo, sHi, bLo, bHi)
+	}
+	return "B" + counts3D(r.birth) + "/S" + counts3D(r.survival)
+}
+
+// span3D returns the lowest and highest enabled count. It is only ok when
+// the enabled counts are a single non-empty range.
+func span3D(counts [maxNeighbors3D + 1]bool) (lo, hi int, ok bool) {
+	lo, hi = -1, -1
+	for n, on := range counts {
+		switch {
+		case on && lo < 0:
+			lo, hi = n, n
+		case on && hi == n-1:
+			hi = n
+		case on:
+			return 0, 0, false
+		}
+	}
+	return lo, hi, lo >= 0
+}
+
+// counts3D writes the enabled counts as a comma separated list, where runs
+// of three or more counts are written as a range.
+func counts3D(counts [maxNeighbors3D + 1]bool) string {
+	var items []string
+	for n := 0; n <= maxNeighbors3D; n++ {
+		if !counts[n] {
+			continue
+		}
+		end := n
+		for end < maxNeighbors3D && counts[end+1] {
+			end++
+		}
+		switch {
+		case end-n >= 2:
+			items = append(items, fmt.Sprintf("%d-%d", n, end))
+		case end > n:
+			items = append(items, strconv.Itoa(n), strconv.Itoa(end))
+		default:
+			items = append(items, strconv.Itoa(n))
+		}
+		n = end
+	}
+	return strings.Join(items, ",")
+}
+
+// String returns the canonical form of the rule.
+func (r Rule3D) String() string {
+	return r.name
+}
+
+// Step3D computes the next generation of the volume in an unbounded space
+// and returns it as a new volume trimmed to its live cells. The volume is
+// not modified.
+func Step3D(v *Volume, r Rule3D) *Volume {
+	// every neighbour count is the sum of the 3x3x3 box around a cell, which
+	// is separable into a sum of three cells along each axis in turn
+	cur := v.crop(-1, -1, -1, v.width+2, v.height+2, v.depth+2)
+	sums := make([]uint8, len(cur.cells))
+	tmp := make([]uint8, len(cur.cells))
+	strides := []int{1, cur.width, cur.width * cur.height}
+	sizes := []int{cur.width, cur.height, cur.depth}
+	copy(sums, cur.cells)
+	for axis, stride := range strides {
+		sums, tmp = tmp, sums
+		for i := range sums {
+			pos := i / stride % sizes[axis]
+			sum := tmp[i]
+			if pos > 0 {
+				sum += tmp[i-stride]
+			}
+			if pos < sizes[axis]-1 {
+				sum += tmp[i+stride]
+			}
+			sums[i] = sum
+		}
+	}
+
+	next := NewVolume(cur.width, cur.height, cur.depth)
+	next.x, next.y, next.z = cur.x, cur.y, cur.z
+	for i, c := range cur.cells {
+		if (c == 0 && r.birth[sums[i]]) || (c != 0 && r.survival[sums[i]-1]) {
+			next.cells[i] = 1
+		}
+	}
+	return next.Trim()
+}
+
+// Step3DN computes the nth generation after the volume by calling [Step3D]
+// repeatedly. It stops between generations when the context is cancelled and
+// returns the context error.
+func Step3DN(ctx context.Context, v *Volume, r Rule3D, n int) (*Volume, error) {
+	next := v.Clone()
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next = Step3D(next, r)
+	}
+	return next, nil
+}
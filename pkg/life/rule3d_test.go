@@ -0,0 +1,142 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRule3D(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{input: "4555", want: "4555"},
+		{input: "5766", want: "5766"},
+		{input: "B5/S4,5", want: "4555"},
+		{input: "s5-7/b6", want: "5766"},
+		{input: "B6-8/S5-7,10", want: "B6-8/S5-7,10"},
+		{input: "B13,14,17-19/S13-26", want: "B13,14,17-19/S13-26"},
+		{input: "B4/S", want: "B4/S"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseRule3D(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, r.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRule3DInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"455",
+		"5455",
+		"4505",
+		"B3/S23/C3",
+		"B27/S5",
+		"B5-3/S5",
+		"B5,/S5",
+		"B+5/S5",
+		"B0/S5",
+		"S5",
+	}
+
+	for _, input := range cases {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseRule3D(input); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestStep3D(t *testing.T) {
+	// a 2x2x2 cube has seven neighbours per cell and at most four around it
+	cube, _ := NewVolumeFromLayers([][]string{{"OO", "OO"}, {"OO", "OO"}})
+	r, _ := ParseRule3D("5766")
+	got := Step3D(cube, r)
+	if !cube.Equal(got) {
+		t.Errorf("expected still life %v, got %v", cube.Layers(), got.Layers())
+	}
+
+	r, _ = ParseRule3D("4555")
+	if got := Step3D(cube, r); got.Population() != 0 {
+		t.Errorf("expected cube to die, got population %d", got.Population())
+	}
+}
+
+func TestStep3DN(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	v := NewVolume(8, 7, 6)
+	for z := 0; z < v.Depth(); z++ {
+		for y := 0; y < v.Height(); y++ {
+			for x := 0; x < v.Width(); x++ {
+				v.Set(x, y, z, rng.IntN(3) == 0)
+			}
+		}
+	}
+	v.SetOrigin(2, -1, 4)
+
+	for _, rule := range []string{"4555", "5766", "B4/S3-6", "B6-8/S5-7,10"} {
+		t.Run(rule, func(t *testing.T) {
+			r, err := ParseRule3D(rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := Step3DN(context.Background(), v, r, 5)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := v
+			for i := 0; i < 5; i++ {
+				want = step3DReference(want, r)
+			}
+			if !want.Equal(got) {
+				t.Errorf("expected %v, got %v", want.Layers(), got.Layers())
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Step3DN(ctx, v, Rule3D{}, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+// step3DReference steps a volume by counting the neighbours of every cell
+// one by one.
+func step3DReference(v *Volume, r Rule3D) *Volume {
+	x0, y0, z0 := v.Origin()
+	next := NewVolume(v.Width()+2, v.Height()+2, v.Depth()+2)
+	next.SetOrigin(x0-1, y0-1, z0-1)
+	for z := 0; z < next.Depth(); z++ {
+		for y := 0; y < next.Height(); y++ {
+			for x := 0; x < next.Width(); x++ {
+				n := 0
+				for dz := -1; dz <= 1; dz++ {
+					for dy := -1; dy <= 1; dy++ {
+						for dx := -1; dx <= 1; dx++ {
+							if (dx != 0 || dy != 0 || dz != 0) && v.Alive(x+dx-1, y+dy-1, z+dz-1) {
+								n++
+							}
+						}
+					}
+				}
+				alive := v.Alive(x-1, y-1, z-1)
+				next.Set(x, y, z, (!alive && r.birth[n]) || (alive && r.survival[n]))
+			}
+		}
+	}
+	return next.Trim()
+}
@@ -0,0 +1,228 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHenselOrbits(t *testing.T) {
+	// every neighbourhood must belong to exactly one letter of its count
+	for n := 0; n <= 8; n++ {
+		seen := make(map[int]byte)
+		for letter, orbit := range henselOrbits[n] {
+			for _, idx := range orbit {
+				if bits.OnesCount(uint(idx)) != n || idx&centerBit != 0 {
+					t.Errorf("%d%c: index %d has wrong neighbours", n, letter, idx)
+				}
+				if other, ok := seen[idx]; ok {
+					t.Errorf("%d: index %d in both %q and %q", n, idx, other, letter)
+				}
+				seen[idx] = letter
+			}
+		}
+		want := 1
+		for k := 0; k < n; k++ {
+			want = want * (8 - k) / (k + 1)
+		}
+		if len(seen) != want {
+			t.Errorf("%d: expected %d neighbourhoods, got %d", n, want, len(seen))
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	cases := []struct {
+		input      string
+		want       string
+		totalistic bool
+	}{
+		{input: "B3/S23", want: "B3/S23", totalistic: true},
+		{input: "b3s23", want: "B3/S23", totalistic: true},
+		{input: "S23/B3", want: "B3/S23", totalistic: true},
+		{input: "23/3", want: "B3/S23", totalistic: true},
+		{input: "B36/S23", want: "B36/S23", totalistic: true},
+		{input: "B2/S", want: "B2/S", totalistic: true},
+		{input: "/2", want: "B2/S", totalistic: true},
+		{input: "B2-a/S12", want: "B2-a/S12", totalistic: false},
+		{input: "B2cekain/S", want: "B2/S", totalistic: true},
+		{input: "B2ce3/S23-a", want: "B2ce3/S23-a", totalistic: false},
+		{input: "B2-eakin/S", want: "B2c/S", totalistic: false},
+		{input: "B2ckin/S", want: "B2-ea/S", totalistic: false},
+		{input: "B3/S2-i34q", want: "B3/S2-i34q", totalistic: false},
+		{input: "B0123478/S01234678:T10,10", want: "B0123478/S01234678:T10,10", totalistic: true},
+		{input: "B3/S23:p20,10", want: "B3/S23:P20,10", totalistic: true},
+		{input: "b3/s23:K20*,10", want: "B3/S23:K20*,10", totalistic: true},
+		{input: "23/3:C5,6", want: "B3/S23:C5,6", totalistic: true},
+		{input: "B2/S/C3", want: "B2/S/C3", totalistic: true},
+		{input: "/2/3", want: "B2/S/C3", totalistic: true},
+		{input: "345/2/4", want: "B2/S345/C4", totalistic: true},
+		{input: "B3/S23/C2", want: "B3/S23", totalistic: true},
+		{input: "b2-a/s12/c5:t10,10", want: "B2-a/S12/C5:T10,10", totalistic: false},
+		{input: "B2/S34H", want: "B2/S34H", totalistic: false},
+		{input: "34/2h", want: "B2/S34H", totalistic: false},
+		{input: "B246/S0123456H", want: "B246/S0123456H", totalistic: false},
+		{input: "B1/S013V", want: "B1/S013V", totalistic: false},
+		{input: "b2/s34/c3h:t10,10", want: "B2/S34/C3H:T10,10", totalistic: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			r, err := ParseRule(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, r.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if got := r.Totalistic(); got != tc.totalistic {
+				t.Errorf("expected totalistic %v, got %v", tc.totalistic, got)
+			}
+		})
+	}
+}
+
+func TestParseRuleInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"B3",
+		"B9/S23",
+		"B3/S23/B3",
+		"B3x/S23",
+		"B2-/S23",
+		"B0c/S23",
+		"B2aa/S23",
+		"B1k/S23",
+		"23",
+		"B3/S23/",
+		"B2/S/C1",
+		"B2/S/C27",
+		"B2/S/C",
+		"345/2/x",
+		"B0/S23",
+		"B7/S34H",
+		"B2/S5V",
+		"B2a/S34H",
+		"B3/S23HV",
+		"B3/S23:",
+		"B3/S23:X10,10",
+		"B3/S23:T10",
+		"B3/S23:T0,10",
+		"B3/S23:T10*,10",
+		"B3/S23:K10,10",
+		"B3/S23:K10*,10*",
+	}
+
+	for _, input := range cases {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseRule(input); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
+
+func TestRuleHighLife(t *testing.T) {
+	// a dead cell with six neighbours is only born in HighLife
+	b, _ := NewBoardFromRows([]string{"OOO", "O.O", "O.."})
+	if !Step(b, boundedRule(t, "B36/S23", b)).Alive(1, 1) {
+		t.Error("expected HighLife birth on six neighbours")
+	}
+	if Step(b, boundedRule(t, "B3/S23", b)).Alive(1, 1) {
+		t.Error("expected no Life birth on six neighbours")
+	}
+}
+
+func TestRuleIsotropic(t *testing.T) {
+	// B2-a/S12 births on two diagonal neighbours but not on two adjacent
+	r, _ := ParseRule("B2-a/S12:P3,3")
+	cases := []struct {
+		name  string
+		input []string
+		want  bool
+	}{
+		{name: "2n", input: []string{"O..", "...", "..O"}, want: true},
+		{name: "2a", input: []string{"OO.", "...", "..."}, want: false},
+		{name: "2a rotated", input: []string{"...", "..O", "..O"}, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.input)
+			if got := Step(b, r).Alive(1, 1); got != tc.want {
+				t.Errorf("expected center alive %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRuleNeighborhood(t *testing.T) {
+	// a dead cell with a single neighbour is born when the neighbour is
+	// within the neighbourhood of the rule
+	cases := []struct {
+		rule  string
+		input []string
+		want  bool
+	}{
+		{rule: "B1/SH", input: []string{"O..", "...", "..."}, want: true},
+		{rule: "B1/SH", input: []string{"..O", "...", "..."}, want: false},
+		{rule: "B1/SH", input: []string{"...", "...", "O.."}, want: false},
+		{rule: "B1/SH", input: []string{"...", "...", "..O"}, want: true},
+		{rule: "B1/SV", input: []string{".O.", "...", "..."}, want: true},
+		{rule: "B1/SV", input: []string{"O..", "...", "..."}, want: false},
+		{rule: "B1/S", input: []string{"..O", "...", "..."}, want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%s %s", tc.rule, strings.Join(tc.input, "/")), func(t *testing.T) {
+			b, _ := NewBoardFromRows(tc.input)
+			if got := Step(b, boundedRule(t, tc.rule, b)).Alive(1, 1); got != tc.want {
+				t.Errorf("expected center alive %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	hexagonal, _ := ParseRule("B2/S34H")
+	if diff := cmp.Diff(Hexagonal, hexagonal.Neighborhood()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestRuleGenerations(t *testing.T) {
+	brain, _ := ParseRule("B2/S/C3")
+	if diff := cmp.Diff(3, brain.States()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// live cells always start dying, dying cells decay, and dead cells with
+	// exactly two live neighbours are born, ignoring dying neighbours
+	b, _ := NewBoardFromRows([]string{"....", ".OO.", ".BB.", "...."})
+	got := Step(b, boundedRule(t, "B2/S/C3", b))
+	want := []string{".OO.", ".BB.", "....", "...."}
+	if diff := cmp.Diff(want, got.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	starWars, _ := ParseRule("345/2/4")
+	cases := []struct {
+		state uint8
+		idx   int
+		want  uint8
+	}{
+		{state: 0, idx: 0b11, want: 1},
+		{state: 0, idx: 0b111, want: 0},
+		{state: 1, idx: centerBit | 0b111, want: 1},
+		{state: 1, idx: centerBit | 0b1, want: 2},
+		{state: 2, idx: 0b11, want: 3},
+		{state: 3, idx: 0b11, want: 0},
+	}
+	for _, tc := range cases {
+		if got := starWars.nextState(tc.state, tc.idx); got != tc.want {
+			t.Errorf("nextState(%d, %b) = %d, want %d", tc.state, tc.idx, got, tc.want)
+		}
+	}
+}
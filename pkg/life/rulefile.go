@@ -0,0 +1,175 @@
+package life
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// customRule computes the next state of a cell from the states of the cells
+// of its 3x3 neighbourhood in row major order, where index 4 is the cell
+// itself.
+type customRule interface {
+	next(cells *[9]uint8) uint8
+}
+
+// RuleFile is a custom rule parsed from a Golly .rule file by
+// [ParseRuleFile]. It is turned into a [Rule] on a topology with
+// [RuleFile.Rule].
+type RuleFile struct {
+	name         string
+	states       int
+	neighborhood Neighborhood
+	custom       customRule
+}
+
+// ParseRuleFile parses a Golly .rule file. The file starts with a @RULE line
+// naming the rule, followed by either a @TABLE or a @TREE section. Other
+// sections such as @COLORS and @ICONS are ignored.
+//
+// A @TABLE section lists transitions on the Moore, von Neumann, hexagonal,
+// or one dimensional neighbourhood, which are expanded by variables and by
+// the symmetries none, rotate2, rotate3, rotate4, rotate6, rotate8,
+// reflect, reflect_horizontal, rotate4reflect, rotate6reflect,
+// rotate8reflect, or permute. A @TREE section is a decision tree over the
+// Moore or von Neumann neighbourhood.
+//
+// Rules may have up to [MaxStates] states, so that their boards can be
+// written as text.
+func ParseRuleFile(r io.Reader) (*RuleFile, error) {
+	sections, err := readSections(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	rule, ok := sections["rule"]
+	if !ok || len(rule.args) != 1 {
+		return nil, fmt.Errorf("%w: expected @RULE followed by a name", ErrInvalidRule)
+	}
+	f := &RuleFile{name: rule.args[0]}
+	if err := validRuleName(f.name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	table, hasTable := sections["table"]
+	tree, hasTree := sections["tree"]
+	switch {
+	case hasTable && hasTree:
+		err = errors.New("expected only one of @TABLE and @TREE")
+	case hasTable:
+		err = f.parseTable(table.lines)
+	case hasTree:
+		err = f.parseTree(tree.lines)
+	default:
+		err = errors.New("expected @TABLE or @TREE")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrInvalidRule, f.name, err)
+	}
+	return f, nil
+}
+
+// Name returns the name of the rule.
+func (f *RuleFile) Name() string {
+	return f.name
+}
+
+// States returns the number of cell states.
+func (f *RuleFile) States() int {
+	return f.states
+}
+
+// Neighborhood returns the shape of the neighbourhood of each cell.
+func (f *RuleFile) Neighborhood() Neighborhood {
+	return f.neighborhood
+}
+
+// Rule returns the rule evaluated on the given topology. Rules that give
+// birth to cells with no neighbours require a bounded grid. Two-state rules
+// are converted into a table of neighbourhoods, so that they can be stepped
+// by every engine that supports their neighbourhood.
+func (f *RuleFile) Rule(topo Topology) (Rule, error) {
+	var empty [9]uint8
+	if f.custom.next(&empty) != 0 && !topo.Bounded() {
+		return Rule{}, fmt.Errorf("%w %s: births with no neighbours require a bounded grid", ErrInvalidRule, f.name)
+	}
+
+	r := Rule{
+		name:         f.name + topo.String(),
+		states:       f.states,
+		neighborhood: f.neighborhood,
+		topology:     topo,
+	}
+	if f.states > 2 {
+		r.custom = f.custom
+		return r, nil
+	}
+	for idx := range r.table {
+		var cells [9]uint8
+		for i := range cells {
+			cells[i] = uint8(idx >> i & 1)
+		}
+		r.table[idx] = f.custom.next(&cells) == 1
+	}
+	return r, nil
+}
+
+// validRuleName checks that a rule name is a nonempty Golly file name of
+// letters, digits, hyphens, and underscores that cannot be confused with a
+// rulestring.
+func validRuleName(name string) error {
+	if name == "" || strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") != "" {
+		return fmt.Errorf("rule name %q must only contain letters, digits, hyphens, and underscores", name)
+	}
+	if _, err := ParseRule(name); err == nil {
+		return fmt.Errorf("rule name %q is a rulestring", name)
+	}
+	return nil
+}
+
+// section is a section of a rule file, starting with a line such as
+// "@TABLE". The arguments follow the section name on the same line.
+type section struct {
+	args  []string
+	lines []string
+}
+
+// readSections splits a rule file into its sections by their lowercase
+// names. Comments starting with '#' and blank lines are removed.
+func readSections(r io.Reader) (map[string]section, error) {
+	sections := make(map[string]section)
+	var name string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			fields := strings.Fields(line)
+			name = strings.ToLower(fields[0][1:])
+			if _, ok := sections[name]; ok {
+				return nil, fmt.Errorf("duplicate section @%s", strings.ToUpper(name))
+			}
+			sections[name] = section{args: fields[1:]}
+			continue
+		}
+		if name == "" {
+			return nil, errors.New("expected @RULE before any other line")
+		}
+		s := sections[name]
+		s.lines = append(s.lines, line)
+		sections[name] = s
+	}
+	return sections, scanner.Err()
+}
+
+// cutSetting parses a setting line of a rule file, such as "n_states:4" or
+// "num_states=4", with the given separator.
+func cutSetting(line, sep string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(line, sep)
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), ok
+}
@@ -0,0 +1,222 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// wireWorld is Brian Silverman's WireWorld, where state 1 is an electron
+// head, 2 is an electron tail, and 3 is a conductor.
+const wireWorld = `@RULE WireWorld
+
+# electrons travel along wires of conductor
+@TABLE
+n_states:4
+neighborhood:Moore
+symmetries:permute
+var a={0,1,2,3}
+var b={0,1,2,3}
+var c={0,1,2,3}
+var d={0,1,2,3}
+var e={0,1,2,3}
+var f={0,1,2,3}
+var g={0,1,2,3}
+var h={0,1,2,3}
+var i={0,2,3}
+var j={0,2,3}
+var k={0,2,3}
+var l={0,2,3}
+var m={0,2,3}
+var n={0,2,3}
+var o={0,2,3}
+1,a,b,c,d,e,f,g,h,2
+2,a,b,c,d,e,f,g,h,3
+3,1,i,j,k,l,m,n,o,1
+3,1,1,j,k,l,m,n,o,1
+
+@COLORS
+1 255 255 255
+`
+
+// shiftDown is a two-state von Neumann rule tree where each cell takes the
+// state of its north neighbour, so patterns move south.
+const shiftDown = `@RULE ShiftDown
+@TREE
+num_states=2
+num_neighbors=4
+num_nodes=9
+1 0 0
+1 1 1
+2 0 0
+2 1 1
+3 2 2
+3 3 3
+4 4 4
+4 5 5
+5 6 7
+`
+
+// cycle3 is a three-state rule tree where every cell counts up its state.
+const cycle3 = `@RULE Cycle3
+@TREE
+num_states=3
+num_neighbors=8
+num_nodes=9
+1 1 2 0
+2 0 0 0
+3 1 1 1
+4 2 2 2
+5 3 3 3
+6 4 4 4
+7 5 5 5
+8 6 6 6
+9 7 7 7
+`
+
+func TestParseRuleFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		topo  string
+		board []string
+		n     int
+		want  []string
+	}{
+		{
+			name:  "table",
+			src:   wireWorld,
+			board: []string{"BOCCC"},
+			n:     2,
+			want:  []string{"CCBOC"},
+		},
+		{
+			name:  "table split",
+			src:   wireWorld,
+			board: []string{"C..", ".OC", "C.."},
+			n:     1,
+			want:  []string{"O..", ".BO", "O.."},
+		},
+		{
+			name:  "symmetries",
+			src:   "@RULE Plus\n@TABLE\nn_states:2\nneighborhood:vonNeumann\nsymmetries:rotate4\n010001\n",
+			board: []string{"O"},
+			n:     1,
+			want:  []string{".O.", "OOO", ".O."},
+		},
+		{
+			name:  "tree",
+			src:   shiftDown,
+			board: []string{"OO", "O."},
+			n:     3,
+			want:  []string{"OO", "O."},
+		},
+		{
+			name:  "multistate tree",
+			src:   cycle3,
+			topo:  "T2,1",
+			board: []string{"OB"},
+			n:     2,
+			want:  []string{".O"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseRuleFile(strings.NewReader(tc.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var topo Topology
+			if tc.topo != "" {
+				topo, _ = ParseTopology(tc.topo)
+			}
+			rule, err := f.Rule(topo)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, _ := NewBoardFromRows(tc.board)
+			got := StepN(b, rule, tc.n)
+			if diff := cmp.Diff(tc.want, got.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRuleFileRule(t *testing.T) {
+	f, err := ParseRuleFile(strings.NewReader(wireWorld))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Name() != "WireWorld" || f.States() != 4 || f.Neighborhood() != Moore {
+		t.Errorf("expected WireWorld with 4 states, got %s with %d", f.Name(), f.States())
+	}
+	topo, _ := ParseTopology("T10,10")
+	rule, _ := f.Rule(topo)
+	if diff := cmp.Diff("WireWorld:T10,10", rule.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if rule.States() != 4 || rule.Totalistic() {
+		t.Errorf("expected a 4 state rule that is not totalistic")
+	}
+
+	// two-state rules are converted into neighbourhood tables
+	f, _ = ParseRuleFile(strings.NewReader(shiftDown))
+	rule, _ = f.Rule(Topology{})
+	if _, err := StepPacked(NewBoard(1, 1), rule); !errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+	}
+	glider, _ := NewBoardFromRows([]string{"O"})
+	s, err := NewSparse(glider).Step(rule)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Alive(0, 1) || s.Population() != 1 {
+		t.Errorf("expected the cell to move south")
+	}
+
+	f, _ = ParseRuleFile(strings.NewReader(cycle3))
+	if _, err := f.Rule(Topology{}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+	}
+}
+
+func TestParseRuleFileInvalid(t *testing.T) {
+	table := "@RULE Test\n@TABLE\nn_states:3\n"
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "no rule", src: "@TABLE\nn_states:2\n"},
+		{name: "line before rule", src: "n_states:2\n@RULE Test\n"},
+		{name: "name", src: "@RULE Test.rule\n@TABLE\nn_states:2\n"},
+		{name: "rulestring name", src: "@RULE B3S23\n@TABLE\nn_states:2\n"},
+		{name: "no section", src: "@RULE Test\n"},
+		{name: "both sections", src: "@RULE Test\n@TABLE\nn_states:2\n@TREE\nnum_states=2\n"},
+		{name: "states", src: "@RULE Test\n@TABLE\nn_states:27\n"},
+		{name: "neighbourhood", src: table + "neighborhood:triangular\n"},
+		{name: "symmetries", src: table + "symmetries:rotate6\n"},
+		{name: "setting", src: table + "colours:3\n"},
+		{name: "unknown state", src: table + "0,1,1,1,1,1,1,1,1,3\n"},
+		{name: "unknown variable", src: table + "0,x,1,1,1,1,1,1,1,2\n"},
+		{name: "count", src: table + "0,1,1,2\n"},
+		{name: "unbound output", src: table + "var a={0,1}\nvar b={1,2}\n0,a,1,1,1,1,1,1,1,b\n"},
+		{name: "var", src: table + "var a=0,1\n"},
+		{name: "tree neighbours", src: "@RULE Test\n@TREE\nnum_states=2\nnum_neighbors=6\nnum_nodes=1\n1 0 0\n"},
+		{name: "tree nodes", src: "@RULE Test\n@TREE\nnum_states=2\nnum_neighbors=4\nnum_nodes=2\n1 0 0\n"},
+		{name: "tree state", src: strings.Replace(shiftDown, "1 1 1", "1 1 2", 1)},
+		{name: "tree child", src: strings.Replace(shiftDown, "2 1 1", "2 1 4", 1)},
+		{name: "tree root", src: strings.Replace(shiftDown, "5 6 7", "4 4 5", 1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseRuleFile(strings.NewReader(tc.src)); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("expected %v, got %v", ErrInvalidRule, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxTableTransitions is the maximum number of transitions of a rule table
+// once its variables and symmetries are expanded.
+const maxTableTransitions = 1 << 20
+
+// tableNeighborhood describes a neighbourhood of a rule table. The cells are
+// the row major indexes of the neighbours in the order they are listed in a
+// transition, which is clockwise from north.
+type tableNeighborhood struct {
+	neighborhood Neighborhood
+	cells        []int
+	// symmetries maps each supported symmetry to the permutations of the
+	// neighbours that generate it
+	symmetries map[string][][]int
+}
+
+// rotate returns the permutation of n neighbours that rotates them
+// clockwise by the given number of steps.
+func rotate(n, steps int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = (i + steps) % n
+	}
+	return p
+}
+
+// reflect returns the permutation of n neighbours that reflects them
+// through the first neighbour.
+func reflect(n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = (n - i) % n
+	}
+	return p
+}
+
+// tableNeighborhoods maps the neighbourhoods of a rule table to their
+// definitions. Hexagonal neighbourhoods are emulated like [Hexagonal].
+var tableNeighborhoods = map[string]tableNeighborhood{
+	"moore": {
+		neighborhood: Moore,
+		cells:        []int{1, 2, 5, 8, 7, 6, 3, 0},
+		symmetries: map[string][][]int{
+			"rotate4":            {rotate(8, 2)},
+			"rotate8":            {rotate(8, 1)},
+			"reflect_horizontal": {reflect(8)},
+			"rotate4reflect":     {rotate(8, 2), reflect(8)},
+			"rotate8reflect":     {rotate(8, 1), reflect(8)},
+		},
+	},
+	"vonneumann": {
+		neighborhood: VonNeumann,
+		cells:        []int{1, 5, 7, 3},
+		symmetries: map[string][][]int{
+			"rotate4":            {rotate(4, 1)},
+			"reflect_horizontal": {reflect(4)},
+			"rotate4reflect":     {rotate(4, 1), reflect(4)},
+		},
+	},
+	"hexagonal": {
+		neighborhood: Hexagonal,
+		cells:        []int{1, 5, 8, 7, 3, 0},
+		symmetries: map[string][][]int{
+			"rotate2":        {rotate(6, 3)},
+			"rotate3":        {rotate(6, 2)},
+			"rotate6":        {rotate(6, 1)},
+			"rotate6reflect": {rotate(6, 1), reflect(6)},
+		},
+	},
+	"onedimensional": {
+		neighborhood: Moore,
+		cells:        []int{3, 5},
+		symmetries: map[string][][]int{
+			"reflect": {reflect(2)},
+		},
+	},
+}
+
+// ruleTable is a compiled rule table. Each transition is a row of a bit
+// set per cell and state, so the first transition that matches a
+// neighbourhood is the lowest bit set in every row of the states of its
+// cells.
+type ruleTable struct {
+	// cells holds the row major index of the center followed by the
+	// neighbours
+	cells   []int
+	words   int
+	masks   [][MaxStates][]uint64
+	outputs []uint8
+}
+
+// next returns the output of the first transition matching the
+// neighbourhood, or the state of the cell itself when none match.
+func (t *ruleTable) next(cells *[9]uint8) uint8 {
+	for w := 0; w < t.words; w++ {
+		match := ^uint64(0)
+		for i, c := range t.cells {
+			match &= t.masks[i][cells[c]][w]
+			if match == 0 {
+				break
+			}
+		}
+		if match != 0 {
+			return t.outputs[w*64+bits.TrailingZeros64(match)]
+		}
+	}
+	return cells[4]
+}
+
+// tableTransition is a transition of a rule table with a set of states for
+// the center and each neighbour, and the output state.
+type tableTransition struct {
+	inputs []uint32
+	output uint8
+}
+
+// parseTable parses the lines of a @TABLE section.
+func (f *RuleFile) parseTable(lines []string) error {
+	hood := tableNeighborhoods["moore"]
+	symmetry := "none"
+	vars := make(map[string]uint32)
+	var transitions []tableTransition
+	for _, line := range lines {
+		if key, value, ok := cutSetting(line, ":"); ok {
+			switch key {
+			case "n_states":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 2 || n > MaxStates {
+					return fmt.Errorf("number of states %q must be between 2 and %d", value, MaxStates)
+				}
+				f.states = n
+			case "neighborhood":
+				if hood, ok = tableNeighborhoods[strings.ToLower(value)]; !ok {
+					return fmt.Errorf("unsupported neighbourhood %q", value)
+				}
+			case "symmetries":
+				symmetry = strings.ToLower(value)
+			default:
+				return fmt.Errorf("unexpected setting %q", key)
+			}
+			continue
+		}
+		if f.states == 0 {
+			return errors.New("expected n_states before variables and transitions")
+		}
+
+		if rest, ok := strings.CutPrefix(line, "var "); ok {
+			name, value, ok := strings.Cut(rest, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return fmt.Errorf("expected var name={states}, got %q", line)
+			}
+			set, err := f.parseVar(strings.TrimSpace(value), vars)
+			if err != nil {
+				return fmt.Errorf("var %s: %v", name, err)
+			}
+			vars[name] = set
+			continue
+		}
+
+		expanded, err := f.parseTransition(line, len(hood.cells), vars)
+		if err != nil {
+			return fmt.Errorf("transition %q: %v", line, err)
+		}
+		transitions = append(transitions, expanded...)
+		if len(transitions) > maxTableTransitions {
+			return fmt.Errorf("more than %d transitions", maxTableTransitions)
+		}
+	}
+	if f.states == 0 {
+		return errors.New("expected n_states")
+	}
+
+	var generators [][]int
+	if symmetry == "permute" {
+		for i := 1; i < len(hood.cells); i++ {
+			swap := rotate(len(hood.cells), 0)
+			swap[0], swap[i] = i, 0
+			generators = append(generators, swap)
+		}
+	} else if symmetry != "none" {
+		var ok bool
+		if generators, ok = hood.symmetries[symmetry]; !ok {
+			return fmt.Errorf("unsupported symmetries %q", symmetry)
+		}
+	}
+	transitions, err := expandSymmetries(transitions, generators)
+	if err != nil {
+		return err
+	}
+
+	f.neighborhood = hood.neighborhood
+	f.custom = compileTable(hood, f.states, transitions)
+	return nil
+}
+
+// parseVar parses the set of states of a variable, such as "{0,1,a}", where
+// earlier variables stand for their states.
+func (f *RuleFile) parseVar(s string, vars map[string]uint32) (uint32, error) {
+	inner, prefixed := strings.CutPrefix(s, "{")
+	inner, suffixed := strings.CutSuffix(inner, "}")
+	if !prefixed || !suffixed {
+		return 0, fmt.Errorf("expected {states}, got %q", s)
+	}
+	var set uint32
+	for _, elem := range strings.Split(inner, ",") {
+		elem = strings.TrimSpace(elem)
+		if v, ok := vars[elem]; ok {
+			set |= v
+			continue
+		}
+		state, err := f.parseState(elem)
+		if err != nil {
+			return 0, err
+		}
+		set |= 1 << state
+	}
+	return set, nil
+}
+
+// parseState parses a single state of the rule.
+func (f *RuleFile) parseState(s string) (uint8, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n >= f.states {
+		return 0, fmt.Errorf("unknown state or variable %q", s)
+	}
+	return uint8(n), nil
+}
+
+// parseTransition parses a transition line listing the center, the given
+// number of neighbours, and the output. The line is comma separated, or a
+// string of digits when every element is a single digit. Variables that
+// appear more than once are bound, taking the same state everywhere, so the
+// transition is expanded into one transition per state of each bound
+// variable.
+func (f *RuleFile) parseTransition(line string, neighbors int, vars map[string]uint32) ([]tableTransition, error) {
+	var elems []string
+	if strings.Contains(line, ",") {
+		elems = strings.Split(line, ",")
+	} else {
+		elems = strings.Split(line, "")
+	}
+	if len(elems) != neighbors+2 {
+		return nil, fmt.Errorf("expected %d states, got %d", neighbors+2, len(elems))
+	}
+
+	// count the inputs each variable appears in to find the bound ones
+	counts := make(map[string]int)
+	for i := range elems {
+		elems[i] = strings.TrimSpace(elems[i])
+		if _, ok := vars[elems[i]]; ok && i <= neighbors {
+			counts[elems[i]]++
+		}
+	}
+	output := elems[neighbors+1]
+	if _, ok := vars[output]; ok && counts[output] == 0 {
+		return nil, fmt.Errorf("output variable %s must appear in the inputs", output)
+	}
+	var bound []string
+	for name, n := range counts {
+		if n > 1 || name == output {
+			bound = append(bound, name)
+		}
+	}
+	slices.Sort(bound)
+
+	// expand each combination of states of the bound variables
+	values := make(map[string]uint8)
+	var expanded []tableTransition
+	var expand func(k int) error
+	expand = func(k int) error {
+		if k < len(bound) {
+			set := vars[bound[k]]
+			for set != 0 {
+				values[bound[k]] = uint8(bits.TrailingZeros32(set))
+				set &= set - 1
+				if err := expand(k + 1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if len(expanded) >= maxTableTransitions {
+			return fmt.Errorf("more than %d transitions", maxTableTransitions)
+		}
+
+		t := tableTransition{inputs: make([]uint32, neighbors+1)}
+		for i, elem := range elems[:neighbors+1] {
+			if v, ok := values[elem]; ok {
+				t.inputs[i] = 1 << v
+			} else if set, ok := vars[elem]; ok {
+				t.inputs[i] = set
+			} else {
+				state, err := f.parseState(elem)
+				if err != nil {
+					return err
+				}
+				t.inputs[i] = 1 << state
+			}
+		}
+		if v, ok := values[output]; ok {
+			t.output = v
+		} else {
+			state, err := f.parseState(output)
+			if err != nil {
+				return err
+			}
+			t.output = state
+		}
+		expanded = append(expanded, t)
+		return nil
+	}
+	if err := expand(0); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// expandSymmetries adds the images of each transition under the group of
+// permutations of the neighbours generated by the generators, keeping the
+// order of the transitions and dropping duplicates.
+func expandSymmetries(transitions []tableTransition, generators [][]int) ([]tableTransition, error) {
+	if len(generators) == 0 {
+		return transitions, nil
+	}
+	var expanded []tableTransition
+	seen := make(map[string]bool)
+	for _, t := range transitions {
+		// the orbit of the transition under the generators, found breadth
+		// first
+		orbit := []tableTransition{t}
+		for k := 0; k < len(orbit); k++ {
+			key := transitionKey(orbit[k])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			expanded = append(expanded, orbit[k])
+			if len(expanded) > maxTableTransitions {
+				return nil, fmt.Errorf("more than %d transitions", maxTableTransitions)
+			}
+			for _, p := range generators {
+				image := tableTransition{inputs: make([]uint32, len(t.inputs)), output: t.output}
+				image.inputs[0] = orbit[k].inputs[0]
+				for i, j := range p {
+					image.inputs[1+j] = orbit[k].inputs[1+i]
+				}
+				if !seen[transitionKey(image)] {
+					orbit = append(orbit, image)
+				}
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// transitionKey returns a string identifying a transition.
+func transitionKey(t tableTransition) string {
+	var sb strings.Builder
+	for _, set := range t.inputs {
+		fmt.Fprintf(&sb, "%x,", set)
+	}
+	fmt.Fprintf(&sb, "%d", t.output)
+	return sb.String()
+}
+
+// compileTable compiles expanded transitions into a [ruleTable].
+func compileTable(hood tableNeighborhood, states int, transitions []tableTransition) *ruleTable {
+	t := &ruleTable{
+		cells:   append([]int{4}, hood.cells...),
+		words:   (len(transitions) + 63) / 64,
+		outputs: make([]uint8, len(transitions)),
+	}
+	t.masks = make([][MaxStates][]uint64, len(t.cells))
+	for i := range t.masks {
+		// states the rule does not have match no transitions
+		for s := range t.masks[i] {
+			t.masks[i][s] = make([]uint64, t.words)
+		}
+	}
+	for k, tr := range transitions {
+		t.outputs[k] = tr.output
+		for i, set := range tr.inputs {
+			for s := 0; s < states; s++ {
+				if set&(1<<s) != 0 {
+					t.masks[i][s][k/64] |= 1 << (k % 64)
+				}
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,122 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// treeCells maps the number of neighbours of a rule tree to the row major
+// indexes of the cells it reads from the root of the tree to its leaves.
+var treeCells = map[int][]int{
+	// north west, north east, south west, south east, north, west, east,
+	// south, and the center
+	8: {0, 2, 6, 8, 1, 3, 5, 7, 4},
+	// north, west, east, south, and the center
+	4: {1, 3, 5, 7, 4},
+}
+
+// ruleTree is a Golly rule tree, a decision tree with a level for each cell
+// of the neighbourhood. Each node is a slice of nodes holding the offset of
+// the child node for each state of a cell, and the nodes of the lowest level
+// hold the next state instead.
+type ruleTree struct {
+	cells []int
+	nodes []int
+	root  int
+}
+
+// next walks the tree from its root by the state of each cell.
+func (t *ruleTree) next(cells *[9]uint8) uint8 {
+	n := t.root
+	for _, c := range t.cells {
+		n = t.nodes[n+int(cells[c])]
+	}
+	return uint8(n)
+}
+
+// parseTree parses the lines of a @TREE section. The settings num_states,
+// num_neighbors, and num_nodes are followed by a line per node of a level
+// and either the next states of a node of level 1 or the indexes of earlier
+// nodes of the level below. The last node is the root.
+func (f *RuleFile) parseTree(lines []string) error {
+	var neighbors, count int
+	var nodeLines []string
+	for _, line := range lines {
+		key, value, ok := cutSetting(line, "=")
+		if !ok {
+			nodeLines = append(nodeLines, line)
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("setting %s %q must be a number", key, value)
+		}
+		switch key {
+		case "num_states":
+			if n < 2 || n > MaxStates {
+				return fmt.Errorf("number of states %d must be between 2 and %d", n, MaxStates)
+			}
+			f.states = n
+		case "num_neighbors":
+			neighbors = n
+		case "num_nodes":
+			count = n
+		default:
+			return fmt.Errorf("unexpected setting %q", key)
+		}
+	}
+	cells, ok := treeCells[neighbors]
+	if !ok {
+		return fmt.Errorf("number of neighbours %d must be 4 or 8", neighbors)
+	}
+	if f.states == 0 {
+		return errors.New("expected num_states")
+	}
+	if count < 1 || count != len(nodeLines) {
+		return fmt.Errorf("expected %d nodes, got %d", count, len(nodeLines))
+	}
+
+	t := &ruleTree{cells: cells}
+	// offsets and levels of the nodes by their index in the file
+	offsets := make([]int, count)
+	levels := make([]int, count)
+	for i, line := range nodeLines {
+		fields := strings.Fields(line)
+		if len(fields) != f.states+1 {
+			return fmt.Errorf("node %d: expected a level and %d values", i, f.states)
+		}
+		level, err := strconv.Atoi(fields[0])
+		if err != nil || level < 1 || level > len(cells) {
+			return fmt.Errorf("node %d: level %q must be between 1 and %d", i, fields[0], len(cells))
+		}
+		offsets[i], levels[i] = len(t.nodes), level
+		for _, field := range fields[1:] {
+			v, err := strconv.Atoi(field)
+			switch {
+			case err != nil:
+				return fmt.Errorf("node %d: value %q must be a number", i, field)
+			case level == 1 && (v < 0 || v >= f.states):
+				return fmt.Errorf("node %d: state %d must be less than %d", i, v, f.states)
+			case level > 1 && (v < 0 || v >= i || levels[v] != level-1):
+				return fmt.Errorf("node %d: child %d must be an earlier node of level %d", i, v, level-1)
+			}
+			if level > 1 {
+				v = offsets[v]
+			}
+			t.nodes = append(t.nodes, v)
+		}
+	}
+	if levels[count-1] != len(cells) {
+		return fmt.Errorf("root node must be of level %d", len(cells))
+	}
+	t.root = offsets[count-1]
+
+	f.neighborhood = Moore
+	if neighbors == 4 {
+		f.neighborhood = VonNeumann
+	}
+	f.custom = t
+	return nil
+}
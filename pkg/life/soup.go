@@ -0,0 +1,224 @@
+package life
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ErrInvalidSoup when a soup cannot be generated from the given options.
+var ErrInvalidSoup = errors.New("invalid soup")
+
+const (
+	// defaultSoupSize is the default width and height of a soup.
+	defaultSoupSize = 16
+	// defaultDensity is the default probability of a soup cell being alive.
+	defaultDensity = 0.5
+)
+
+// transform maps a cell of a width by height soup to its mirror image.
+type transform func(x, y, width, height int) (int, int)
+
+// The transforms that generate the symmetries of a soup. Each is its own
+// inverse except the quarter turn.
+var (
+	flipX     transform = func(x, y, w, h int) (int, int) { return w - 1 - x, y }
+	flipY     transform = func(x, y, w, h int) (int, int) { return x, h - 1 - y }
+	rotate180 transform = func(x, y, w, h int) (int, int) { return w - 1 - x, h - 1 - y }
+	rotate90  transform = func(x, y, w, h int) (int, int) { return w - 1 - y, x }
+	transpose transform = func(x, y, w, h int) (int, int) { return y, x }
+	antiDiag  transform = func(x, y, w, h int) (int, int) { return w - 1 - y, w - 1 - x }
+)
+
+// parity constrains a dimension of a soup to be odd or even.
+type parity int
+
+const (
+	anyParity parity = iota
+	odd
+	even
+)
+
+// round returns the smallest size of at least n cells with the parity.
+func (p parity) round(n int) int {
+	if (p == odd && n%2 == 0) || (p == even && n%2 == 1) {
+		return n + 1
+	}
+	return n
+}
+
+// symmetry describes an apgsearch symmetry as the transforms that generate
+// it. A soup is rounded up to an odd or even width and height so that the
+// center of the symmetry falls on a cell, the middle of an edge, or a
+// corner as its name requires.
+type symmetry struct {
+	generators    []transform
+	width, height parity
+	// square symmetries have quarter turns or diagonal mirrors
+	square bool
+}
+
+// soupSymmetries maps the name of each apgsearch symmetry to its definition.
+// The number after the underscore is the number of cells at the center of
+// the symmetry: 1 for a cell, 2 for an edge, and 4 for a corner.
+var soupSymmetries = map[string]symmetry{
+	"C1":    {},
+	"C2_1":  {generators: []transform{rotate180}, width: odd, height: odd},
+	"C2_2":  {generators: []transform{rotate180}, width: even, height: odd},
+	"C2_4":  {generators: []transform{rotate180}, width: even, height: even},
+	"C4_1":  {generators: []transform{rotate90}, width: odd, height: odd, square: true},
+	"C4_4":  {generators: []transform{rotate90}, width: even, height: even, square: true},
+	"D2_+1": {generators: []transform{flipY}, height: odd},
+	"D2_+2": {generators: []transform{flipY}, height: even},
+	"D2_x":  {generators: []transform{transpose}, square: true},
+	"D4_+1": {generators: []transform{flipX, flipY}, width: odd, height: odd},
+	"D4_+2": {generators: []transform{flipX, flipY}, width: even, height: odd},
+	"D4_+4": {generators: []transform{flipX, flipY}, width: even, height: even},
+	"D4_x1": {generators: []transform{transpose, antiDiag}, width: odd, height: odd, square: true},
+	"D4_x4": {generators: []transform{transpose, antiDiag}, width: even, height: even, square: true},
+	"D8_1":  {generators: []transform{rotate90, transpose}, width: odd, height: odd, square: true},
+	"D8_4":  {generators: []transform{rotate90, transpose}, width: even, height: even, square: true},
+}
+
+// soupConfig holds the settings of a soup.
+type soupConfig struct {
+	width, height int
+	density       float64
+	symmetry      string
+}
+
+// SoupOption configures a soup by overriding a default setting.
+type SoupOption func(*soupConfig)
+
+// WithSoupSize sets the width and height of a soup in cells. The default is
+// 16 by 16.
+func WithSoupSize(width, height int) SoupOption {
+	return func(c *soupConfig) {
+		c.width, c.height = width, height
+	}
+}
+
+// WithDensity sets the probability of each cell of a soup being alive,
+// between 0 and 1. The default is 0.5.
+func WithDensity(density float64) SoupOption {
+	return func(c *soupConfig) {
+		c.density = density
+	}
+}
+
+// WithSymmetry sets the apgsearch symmetry of a soup, such as "C1", "C2_4",
+// or "D8_1". The default is "C1", which has no symmetry.
+func WithSymmetry(name string) SoupOption {
+	return func(c *soupConfig) {
+		c.symmetry = name
+	}
+}
+
+// NewSoup generates a random soup from a seed string. The same seed and
+// options always generate the same soup.
+//
+// Random numbers are read from a stream of SHA-256 digests of the seed
+// followed by a 64-bit big-endian counter from zero. Cells are visited row
+// by row, and each cell that is not the mirror image of an earlier cell
+// reads the next 32-bit big-endian number from the stream. It is alive when
+// the number is less than the density times 2^32, and the mirror images of
+// the cell copy its state.
+//
+// Symmetries that require odd or even dimensions round the width and height
+// up by one cell, and symmetries with quarter turns or diagonal mirrors
+// require a square soup.
+func NewSoup(seed string, opts ...SoupOption) (*Board, error) {
+	c := soupConfig{
+		width:    defaultSoupSize,
+		height:   defaultSoupSize,
+		density:  defaultDensity,
+		symmetry: "C1",
+	}
+
+	// apply optional configuration
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	sym, ok := soupSymmetries[c.symmetry]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown symmetry %q", ErrInvalidSoup, c.symmetry)
+	}
+	if c.width < 1 || c.height < 1 {
+		return nil, fmt.Errorf("%w: size %dx%d must be positive", ErrInvalidSoup, c.width, c.height)
+	}
+	if !(c.density >= 0 && c.density <= 1) {
+		return nil, fmt.Errorf("%w: density %v must be between 0 and 1", ErrInvalidSoup, c.density)
+	}
+	if sym.square && c.width != c.height {
+		return nil, fmt.Errorf("%w: symmetry %s requires a square soup", ErrInvalidSoup, c.symmetry)
+	}
+	c.width, c.height = sym.width.round(c.width), sym.height.round(c.height)
+
+	b := NewBoard(c.width, c.height)
+	stream := newSoupStream(seed)
+	threshold := uint64(math.Round(c.density * (1 << 32)))
+	for i := range b.cells {
+		x, y := i%b.width, i/b.width
+		if j := b.orbitMin(x, y, sym.generators); j < i {
+			b.cells[i] = b.cells[j]
+			continue
+		}
+		if uint64(stream.next()) < threshold {
+			b.cells[i] = 1
+		}
+	}
+	return b, nil
+}
+
+// orbitMin returns the lowest index of a cell that the transforms map the
+// cell at x, y to, including the cell itself.
+func (b *Board) orbitMin(x, y int, generators []transform) int {
+	// an orbit has at most eight cells, so it is cheaper to search a slice
+	// than a map
+	orbit := []int{y*b.width + x}
+	lowest := orbit[0]
+	for k := 0; k < len(orbit); k++ {
+		for _, t := range generators {
+			tx, ty := t(orbit[k]%b.width, orbit[k]/b.width, b.width, b.height)
+			j := ty*b.width + tx
+			if !slices.Contains(orbit, j) {
+				orbit = append(orbit, j)
+				lowest = min(lowest, j)
+			}
+		}
+	}
+	return lowest
+}
+
+// soupStream reads 32-bit numbers from SHA-256 digests of a seed and a
+// counter.
+type soupStream struct {
+	seed    []byte
+	counter uint64
+	block   [sha256.Size]byte
+	offset  int
+}
+
+// newSoupStream creates a stream of random numbers for the seed.
+func newSoupStream(seed string) *soupStream {
+	return &soupStream{seed: []byte(seed), offset: sha256.Size}
+}
+
+// next returns the next number of the stream.
+func (s *soupStream) next() uint32 {
+	if s.offset == sha256.Size {
+		h := sha256.New()
+		h.Write(s.seed)
+		h.Write(binary.BigEndian.AppendUint64(nil, s.counter))
+		h.Sum(s.block[:0])
+		s.counter++
+		s.offset = 0
+	}
+	n := binary.BigEndian.Uint32(s.block[s.offset:])
+	s.offset += 4
+	return n
+}
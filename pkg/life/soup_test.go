@@ -0,0 +1,116 @@
+package life
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSoup(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []SoupOption
+		want []string
+	}{
+		{
+			// pinned so that a seed generates the same soup in every release
+			name: "C1",
+			opts: []SoupOption{WithSoupSize(5, 5)},
+			want: []string{"OOOO.", "OO.OO", ".O.OO", ".OO.O", "OOO.."},
+		},
+		{
+			name: "C2_1",
+			opts: []SoupOption{WithSoupSize(5, 5), WithSymmetry("C2_1")},
+			want: []string{"OOOO.", "OO.OO", ".O.O.", "OO.OO", ".OOOO"},
+		},
+		{
+			name: "D8_1",
+			opts: []SoupOption{WithSoupSize(5, 5), WithSymmetry("D8_1")},
+			want: []string{"OOOOO", "OO.OO", "O.O.O", "OO.OO", "OOOOO"},
+		},
+		{
+			name: "empty",
+			opts: []SoupOption{WithSoupSize(3, 2), WithDensity(0)},
+			want: []string{"...", "..."},
+		},
+		{
+			name: "full",
+			opts: []SoupOption{WithSoupSize(3, 2), WithDensity(1)},
+			want: []string{"OOO", "OOO"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewSoup("abc", tc.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, b.Rows()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewSoupSymmetry(t *testing.T) {
+	for name, sym := range soupSymmetries {
+		t.Run(name, func(t *testing.T) {
+			b, err := NewSoup("symmetry", WithSoupSize(16, 16), WithSymmetry(name))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Width() != sym.width.round(16) || b.Height() != sym.height.round(16) {
+				t.Errorf("expected size rounded for %s, got %dx%d", name, b.Width(), b.Height())
+			}
+			for _, tr := range sym.generators {
+				for y := 0; y < b.Height(); y++ {
+					for x := 0; x < b.Width(); x++ {
+						tx, ty := tr(x, y, b.Width(), b.Height())
+						if b.Alive(x, y) != b.Alive(tx, ty) {
+							t.Fatalf("expected cell %d,%d to mirror cell %d,%d", x, y, tx, ty)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewSoupSeed(t *testing.T) {
+	a, _ := NewSoup("seed", WithSoupSize(64, 64))
+	b, _ := NewSoup("seed", WithSoupSize(64, 64))
+	c, _ := NewSoup("seed2", WithSoupSize(64, 64))
+	if !a.Equal(b) {
+		t.Error("expected the same seed to generate the same soup")
+	}
+	if a.Equal(c) {
+		t.Error("expected different seeds to generate different soups")
+	}
+
+	d, _ := NewSoup("seed", WithSoupSize(200, 200), WithDensity(0.25))
+	if pop := d.Population(); pop < 9000 || pop > 11000 {
+		t.Errorf("expected about 10000 live cells, got %d", pop)
+	}
+}
+
+func TestNewSoupInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []SoupOption
+	}{
+		{name: "symmetry", opts: []SoupOption{WithSymmetry("C3")}},
+		{name: "size", opts: []SoupOption{WithSoupSize(0, 16)}},
+		{name: "density", opts: []SoupOption{WithDensity(1.5)}},
+		{name: "square", opts: []SoupOption{WithSoupSize(16, 8), WithSymmetry("D8_4")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewSoup("abc", tc.opts...); !errors.Is(err, ErrInvalidSoup) {
+				t.Errorf("expected %v, got %v", ErrInvalidSoup, err)
+			}
+		})
+	}
+}
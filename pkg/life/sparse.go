@@ -0,0 +1,170 @@
+package life
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+)
+
+// Point is the position of a cell on an unbounded plane.
+type Point struct {
+	X, Y int64
+}
+
+// Sparse is a pattern on an unbounded plane that stores only its live cells.
+// Memory and time are proportional to the population rather than the area,
+// so spaceships can travel indefinitely without a fixed grid size.
+//
+// The zero value is an empty pattern.
+type Sparse struct {
+	cells map[Point]struct{}
+}
+
+// NewSparse creates a [Sparse] pattern from the live cells of a board,
+// placed at the origin of the board.
+func NewSparse(b *Board) *Sparse {
+	s := &Sparse{cells: make(map[Point]struct{}, b.Population())}
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if b.cells[y*b.width+x] != 0 {
+				s.cells[Point{int64(b.x + x), int64(b.y + y)}] = struct{}{}
+			}
+		}
+	}
+	return s
+}
+
+// Alive reports whether the cell at x, y is alive.
+func (s *Sparse) Alive(x, y int64) bool {
+	_, ok := s.cells[Point{x, y}]
+	return ok
+}
+
+// Set the cell at x, y to alive or dead.
+func (s *Sparse) Set(x, y int64, alive bool) {
+	if !alive {
+		delete(s.cells, Point{x, y})
+		return
+	}
+	if s.cells == nil {
+		s.cells = make(map[Point]struct{})
+	}
+	s.cells[Point{x, y}] = struct{}{}
+}
+
+// Population returns the number of live cells.
+func (s *Sparse) Population() int {
+	return len(s.cells)
+}
+
+// Clone returns a copy of the pattern.
+func (s *Sparse) Clone() *Sparse {
+	return &Sparse{cells: maps.Clone(s.cells)}
+}
+
+// Equal reports whether both patterns have the same live cells.
+func (s *Sparse) Equal(o *Sparse) bool {
+	if len(s.cells) != len(o.cells) {
+		return false
+	}
+	for p := range s.cells {
+		if _, ok := o.cells[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the smallest inclusive rectangle containing every live
+// cell. It is not ok when the pattern is empty.
+func (s *Sparse) Bounds() (minX, minY, maxX, maxY int64, ok bool) {
+	minX, minY, maxX, maxY = math.MaxInt64, math.MaxInt64, math.MinInt64, math.MinInt64
+	for p := range s.cells {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return minX, minY, maxX, maxY, len(s.cells) > 0
+}
+
+// Board converts the pattern into a board trimmed to its live cells, which
+// must fit within a maxSize by maxSize board. An empty pattern is an empty
+// board at the origin.
+func (s *Sparse) Board(maxSize int) (*Board, error) {
+	minX, minY, maxX, maxY, ok := s.Bounds()
+	if !ok {
+		return NewBoard(0, 0), nil
+	}
+	width, height := uint64(maxX-minX)+1, uint64(maxY-minY)+1
+	if width > uint64(maxSize) || height > uint64(maxSize) {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %dx%d", ErrTooLarge, width, height, maxSize, maxSize)
+	}
+
+	b := NewBoard(int(width), int(height))
+	b.x, b.y = int(minX), int(minY)
+	for p := range s.cells {
+		b.cells[int(p.Y-minY)*b.width+int(p.X-minX)] = 1
+	}
+	return b, nil
+}
+
+// Step computes the next generation of the pattern using the given two-state
+// range 1 rule, which must be on an unbounded [Plane]. Only live cells and
+// their neighbours are visited. The pattern is not modified.
+func (s *Sparse) Step(r Rule) (*Sparse, error) {
+	if err := checkSparse(r); err != nil {
+		return nil, err
+	}
+
+	// each live cell sets its bit in the neighbourhood index of every cell
+	// around it
+	neighborhoods := make(map[Point]int, len(s.cells)*4)
+	for p := range s.cells {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dx := int64(-1); dx <= 1; dx++ {
+				q := Point{p.X - dx, p.Y - dy}
+				neighborhoods[q] |= 1 << ((dy+1)*3 + dx + 1)
+			}
+		}
+	}
+
+	next := &Sparse{cells: make(map[Point]struct{}, len(s.cells))}
+	for p, idx := range neighborhoods {
+		if r.next(idx) {
+			next.cells[p] = struct{}{}
+		}
+	}
+	return next, nil
+}
+
+// checkSparse returns an error wrapping [ErrUnsupportedRule] when sparse
+// patterns cannot be stepped with the rule.
+func checkSparse(r Rule) error {
+	if r.topology.Bounded() {
+		return fmt.Errorf("%w: sparse stepping requires an unbounded plane", ErrUnsupportedRule)
+	}
+	if r.States() > 2 || r.Range() > 1 {
+		return fmt.Errorf("%w: sparse stepping requires a two-state range 1 rule", ErrUnsupportedRule)
+	}
+	return nil
+}
+
+// StepN computes the nth generation after the pattern like [Sparse.Step]. It
+// stops between generations when the context is cancelled and returns the
+// context error.
+func (s *Sparse) StepN(ctx context.Context, r Rule, n int) (*Sparse, error) {
+	next := &Sparse{cells: make(map[Point]struct{}, len(s.cells))}
+	for p := range s.cells {
+		next.cells[p] = struct{}{}
+	}
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var err error
+		if next, err = next.Step(r); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
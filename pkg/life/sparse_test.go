@@ -0,0 +1,93 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSparseStepN(t *testing.T) {
+	rng := rand.New(rand.NewPCG(13, 14))
+	cases := []struct {
+		name string
+		rule string
+	}{
+		{name: "conway", rule: "B3/S23"},
+		{name: "highlife", rule: "B36/S23"},
+		{name: "hensel", rule: "B2-a/S12"},
+		{name: "hexagonal", rule: "B2/S34H"},
+		{name: "von neumann", rule: "B1/S013V"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := randomBoard(rng, 20, 20, 0.3)
+			b.SetOrigin(-7, 4)
+			s, err := NewSparse(b).StepN(context.Background(), rule, 30)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := s.Board(1024)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := StepN(b, rule, 30); !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestSparseFarAway(t *testing.T) {
+	// a glider near the edge of the plane moves without a grid
+	var s Sparse
+	glider, _ := NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			s.Set(math.MaxInt64-100+int64(x), math.MinInt64+int64(y), glider.Alive(x, y))
+		}
+	}
+	next, err := s.StepN(context.Background(), Conway, 8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	minX, minY, maxX, maxY, ok := next.Bounds()
+	if !ok {
+		t.Fatal("expected live cells")
+	}
+	want := []int64{math.MaxInt64 - 98, math.MinInt64 + 2, math.MaxInt64 - 96, math.MinInt64 + 4}
+	if diff := cmp.Diff(want, []int64{minX, minY, maxX, maxY}); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if next.Population() != 5 || !next.Alive(math.MaxInt64-96, math.MinInt64+3) {
+		t.Errorf("expected glider, got population %d", next.Population())
+	}
+}
+
+func TestSparseErrors(t *testing.T) {
+	bounded, _ := ParseRule("B3/S23:T10,10")
+	if _, err := new(Sparse).Step(bounded); !errors.Is(err, ErrUnsupportedRule) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedRule, err)
+	}
+
+	var s Sparse
+	s.Set(0, 0, true)
+	s.Set(100, 0, true)
+	if _, err := s.Board(10); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.StepN(ctx, Conway, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,148 @@
+package life
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// tileRows is the number of rows in each tile stepped by a worker.
+const tileRows = 32
+
+// tile is a band of rows [from, to) to be stepped by a worker.
+type tile struct {
+	from, to int
+	step     func(from, to int)
+}
+
+// tilePool is a pool of goroutines that step tiles of a board in parallel.
+type tilePool struct {
+	ctx   context.Context
+	tiles chan tile
+	wg    sync.WaitGroup
+}
+
+// newTilePool starts a pool with the given number of workers. Workers skip
+// any remaining tiles once the context is cancelled. The pool must be closed
+// to stop the workers.
+func newTilePool(ctx context.Context, workers int) *tilePool {
+	p := &tilePool{
+		ctx:   ctx,
+		tiles: make(chan tile, workers),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for t := range p.tiles {
+				if ctx.Err() == nil {
+					t.step(t.from, t.to)
+				}
+				p.wg.Done()
+			}
+		}()
+	}
+	return p
+}
+
+// run splits the rows into tiles, steps them with the workers, and waits
+// for every tile to finish. It returns the context error if the context was
+// cancelled, in which case some tiles may not have been stepped.
+func (p *tilePool) run(rows int, step func(from, to int)) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	for from := 0; from < rows; from += tileRows {
+		p.wg.Add(1)
+		p.tiles <- tile{from: from, to: min(from+tileRows, rows), step: step}
+	}
+	p.wg.Wait()
+	return p.ctx.Err()
+}
+
+// close stops the workers.
+func (p *tilePool) close() {
+	close(p.tiles)
+}
+
+// StepTiled computes the next generation in place like [Packed.Step], but
+// splits it into tiles of rows that are stepped in parallel by a pool of
+// workers like [StepTiled]. A non-positive number of workers uses one per
+// CPU. It stops between tiles when the context is cancelled and returns the
+// context error, in which case the cells are left unchanged.
+func (p *Packed) StepTiled(ctx context.Context, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	pool := newTilePool(ctx, workers)
+	defer pool.close()
+	return p.stepTiled(pool)
+}
+
+// stepTiled computes the next generation in place with the pool of workers.
+func (p *Packed) stepTiled(pool *tilePool) error {
+	return p.advance(func(g, next *packedGrid, pr packedRule) error {
+		// packed rows are offset by the ghost row above the grid
+		return pool.run(g.height, func(from, to int) {
+			g.stepRows(next, pr, from+1, to+1)
+		})
+	})
+}
+
+// StepTiled computes the nth generation after the board like [StepN], but
+// splits each generation into tiles of rows that are stepped in parallel by a
+// pool of workers. Two-state totalistic rules are stepped with the bit-packed
+// engine of [StepPackedN]. A non-positive number of workers uses one per CPU.
+//
+// StepTiled stops between tiles when the context is cancelled and returns
+// the context error.
+func StepTiled(ctx context.Context, b *Board, r Rule, n, workers int) (*Board, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	pool := newTilePool(ctx, workers)
+	defer pool.close()
+
+	if packable(r) {
+		p, err := NewPacked(b, r)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			if err := p.stepTiled(pool); err != nil {
+				return nil, err
+			}
+		}
+		return p.Board(), nil
+	}
+
+	next := b.Clone()
+	for i := 0; i < n; i++ {
+		var err error
+		if next, err = next.stepTiled(pool, r); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// stepTiled computes the next generation of the board like [Step] with the
+// pool of workers.
+func (b *Board) stepTiled(pool *tilePool, r Rule) (*Board, error) {
+	topo := r.topology
+	if !topo.Bounded() {
+		rad := r.Range()
+		b = b.crop(-rad, -rad, b.width+2*rad, b.height+2*rad)
+	}
+	next := NewBoard(b.width, b.height)
+	next.x, next.y = b.x, b.y
+	stepRows := b.rowStepper(r)
+	err := pool.run(b.height, func(from, to int) {
+		stepRows(next, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !topo.Bounded() {
+		next = trimPlane(next)
+	}
+	return next, nil
+}
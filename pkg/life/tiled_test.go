@@ -0,0 +1,66 @@
+package life
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestStepTiled(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 10))
+	cases := []struct {
+		name    string
+		rule    string
+		workers int
+	}{
+		{name: "plane", rule: "B3/S23", workers: 4},
+		{name: "torus", rule: "B3/S23:T100,70", workers: 3},
+		{name: "klein", rule: "B36/S23:K100*,70", workers: 0},
+		{name: "hensel plane", rule: "B2-a/S12", workers: 2},
+		{name: "hensel cross", rule: "B2-a3/S12:C100,70", workers: 1},
+		{name: "generations", rule: "B2/S345/C4", workers: 4},
+		{name: "hexagonal torus", rule: "B2/S34H:T100,70", workers: 3},
+		{name: "larger than life", rule: "R3,C0,M1,S8..20,B7..12,NC", workers: 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRule(tc.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := randomBoard(rng, 100, 70, 0.3)
+			got, err := StepTiled(context.Background(), b, rule, 8, tc.workers)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := StepN(b, rule, 8); !want.Equal(got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestStepTiledCancel(t *testing.T) {
+	rule, _ := ParseRule("B3/S23:T512,512")
+	b := randomBoard(rand.New(rand.NewPCG(11, 12)), 512, 512, 0.5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := StepTiled(ctx, b, rule, 1, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// far too many generations to finish before the deadline
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := StepTiled(ctx, b, rule, 1_000_000, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected cancellation to stop promptly, took %v", elapsed)
+	}
+}
@@ -0,0 +1,190 @@
+package life
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TopologyKind describes how the edges of a grid are joined.
+type TopologyKind int
+
+const (
+	// Plane is an unbounded plane. Boards grow to fit their live cells.
+	Plane TopologyKind = iota
+	// Bounded is a bounded plane where cells beyond the edges are dead.
+	Bounded
+	// Torus joins the top edge to the bottom and the left edge to the right.
+	Torus
+	// Klein joins the edges as a torus, except one pair of edges is joined
+	// with a twist.
+	Klein
+	// Cross joins both pairs of edges with a twist, forming a cross-surface.
+	Cross
+)
+
+// topologyLetters maps each bounded topology to its Golly specifier letter.
+var topologyLetters = map[TopologyKind]byte{
+	Bounded: 'P',
+	Torus:   'T',
+	Klein:   'K',
+	Cross:   'C',
+}
+
+// Topology describes the grid a rule is evaluated on. It is written as a
+// Golly bounded grid suffix of a rulestring, such as ":T100,80". A rulestring
+// without a suffix is evaluated on an unbounded [Plane].
+type Topology struct {
+	Kind   TopologyKind
+	Width  int
+	Height int
+	// TwistX joins the top and bottom edges of a Klein bottle with a twist,
+	// so a cell leaving the top at column x enters the bottom at the mirrored
+	// column. It is written as an asterisk after the width.
+	TwistX bool
+	// TwistY joins the left and right edges of a Klein bottle with a twist.
+	// It is written as an asterisk after the height.
+	TwistY bool
+}
+
+// ParseTopology parses a Golly bounded grid specifier, without the leading
+// colon, such as "T100,80" or "K100*,80". The supported grids are a bounded
+// plane (P), torus (T), Klein bottle (K), and cross-surface (C). Both
+// dimensions must be positive and a Klein bottle must twist exactly one pair
+// of edges.
+func ParseTopology(s string) (Topology, error) {
+	t, err := parseTopology(s)
+	if err != nil {
+		return Topology{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return t, nil
+}
+
+// parseTopology implements [ParseTopology] without wrapping errors.
+func parseTopology(s string) (Topology, error) {
+	if s == "" {
+		return Topology{}, errors.New("empty bounded grid")
+	}
+
+	var t Topology
+	switch strings.ToUpper(s[:1]) {
+	case "P":
+		t.Kind = Bounded
+	case "T":
+		t.Kind = Torus
+	case "K":
+		t.Kind = Klein
+	case "C":
+		t.Kind = Cross
+	default:
+		return Topology{}, fmt.Errorf("unsupported bounded grid %q", s[:1])
+	}
+
+	width, height, ok := strings.Cut(s[1:], ",")
+	if !ok {
+		return Topology{}, errors.New("expected bounded grid width,height")
+	}
+	var err error
+	if t.Width, t.TwistX, err = parseDimension(width); err != nil {
+		return Topology{}, err
+	}
+	if t.Height, t.TwistY, err = parseDimension(height); err != nil {
+		return Topology{}, err
+	}
+
+	twists := t.TwistX || t.TwistY
+	if t.Kind == Klein && t.TwistX == t.TwistY {
+		return Topology{}, errors.New("Klein bottle must twist exactly one pair of edges")
+	}
+	if t.Kind != Klein && twists {
+		return Topology{}, errors.New("only a Klein bottle can twist edges")
+	}
+	return t, nil
+}
+
+// Bounded reports whether the topology has a fixed size.
+func (t Topology) Bounded() bool {
+	return t.Kind != Plane
+}
+
+// String returns the Golly bounded grid suffix of the topology, including
+// the leading colon. An unbounded plane has no suffix.
+func (t Topology) String() string {
+	if t.Kind == Plane {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte(':')
+	sb.WriteByte(topologyLetters[t.Kind])
+	sb.WriteString(strconv.Itoa(t.Width))
+	if t.TwistX {
+		sb.WriteByte('*')
+	}
+	sb.WriteByte(',')
+	sb.WriteString(strconv.Itoa(t.Height))
+	if t.TwistY {
+		sb.WriteByte('*')
+	}
+	return sb.String()
+}
+
+// wrap maps the coordinates of a cell on a width by height board onto the
+// board according to the topology. A cell may lie several boards away, as
+// neighbourhoods can be wider than the board, so every crossing of a twisted
+// pair of edges reflects it again. It is not ok when the cell is beyond the
+// edge of a bounded plane.
+func (t Topology) wrap(x, y, width, height int) (int, int, bool) {
+	if x >= 0 && y >= 0 && x < width && y < height {
+		return x, y, true
+	}
+
+	switch t.Kind {
+	case Torus:
+		return mod(x, width), mod(y, height), true
+	case Klein, Cross:
+		// crossing the top or bottom edge reflects x on a twisted pair, and
+		// crossing the left or right edge reflects y
+		twistX, twistY := t.TwistX || t.Kind == Cross, t.TwistY || t.Kind == Cross
+		wrapsX, wrapsY := floorDiv(x, width), floorDiv(y, height)
+		x, y = mod(x, width), mod(y, height)
+		if twistX && wrapsY%2 != 0 {
+			x = width - 1 - x
+		}
+		if twistY && wrapsX%2 != 0 {
+			y = height - 1 - y
+		}
+		return x, y, true
+	default:
+		return x, y, false
+	}
+}
+
+// parseDimension parses a bounded grid dimension, which may be followed by
+// an asterisk to mark a twisted pair of edges.
+func parseDimension(s string) (int, bool, error) {
+	s, twist := strings.CutSuffix(s, "*")
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, false, fmt.Errorf("bounded grid dimension %q must be a positive integer", s)
+	}
+	return n, twist, nil
+}
+
+// floorDiv returns a divided by b rounded down.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
+
+// mod returns the non-negative remainder of a divided by b.
+func mod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package life
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTopology(t *testing.T) {
+	cases := []struct {
+		input string
+		want  Topology
+		err   bool
+	}{
+		{input: "P100,80", want: Topology{Kind: Bounded, Width: 100, Height: 80}},
+		{input: "t100,80", want: Topology{Kind: Torus, Width: 100, Height: 80}},
+		{input: "K100*,80", want: Topology{Kind: Klein, Width: 100, Height: 80, TwistX: true}},
+		{input: "K100,80*", want: Topology{Kind: Klein, Width: 100, Height: 80, TwistY: true}},
+		{input: "C100,80", want: Topology{Kind: Cross, Width: 100, Height: 80}},
+		{input: "", err: true},
+		{input: "S100,80", err: true},
+		{input: "T100", err: true},
+		{input: "T-1,80", err: true},
+		{input: "Tx,80", err: true},
+		{input: "K100,80", err: true},
+		{input: "C100*,80", err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseTopology(tc.input)
+			if tc.err {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Fatalf("expected %v, got %v", ErrInvalidRule, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTopologyString(t *testing.T) {
+	cases := []struct {
+		input Topology
+		want  string
+	}{
+		{input: Topology{}, want: ""},
+		{input: Topology{Kind: Bounded, Width: 3, Height: 4}, want: ":P3,4"},
+		{input: Topology{Kind: Torus, Width: 3, Height: 4}, want: ":T3,4"},
+		{input: Topology{Kind: Klein, Width: 3, Height: 4, TwistX: true}, want: ":K3*,4"},
+		{input: Topology{Kind: Klein, Width: 3, Height: 4, TwistY: true}, want: ":K3,4*"},
+		{input: Topology{Kind: Cross, Width: 3, Height: 4}, want: ":C3,4"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.want, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.input.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTopologyWrap(t *testing.T) {
+	cases := []struct {
+		name string
+		topo Topology
+		x, y int
+		want []int
+		ok   bool
+	}{
+		{name: "inside", topo: Topology{Kind: Bounded}, x: 1, y: 2, want: []int{1, 2}, ok: true},
+		{name: "bounded", topo: Topology{Kind: Bounded}, x: -1, y: 2, want: []int{-1, 2}, ok: false},
+		{name: "torus", topo: Topology{Kind: Torus}, x: -1, y: 4, want: []int{3, 0}, ok: true},
+		{name: "klein x", topo: Topology{Kind: Klein, TwistX: true}, x: 1, y: -1, want: []int{2, 3}, ok: true},
+		{name: "klein x side", topo: Topology{Kind: Klein, TwistX: true}, x: -1, y: 1, want: []int{3, 1}, ok: true},
+		{name: "klein y", topo: Topology{Kind: Klein, TwistY: true}, x: 4, y: 0, want: []int{0, 3}, ok: true},
+		{name: "cross", topo: Topology{Kind: Cross}, x: 4, y: 0, want: []int{0, 3}, ok: true},
+		{name: "cross top", topo: Topology{Kind: Cross}, x: 0, y: -1, want: []int{3, 3}, ok: true},
+		{name: "cross corner", topo: Topology{Kind: Cross}, x: -1, y: -1, want: []int{0, 0}, ok: true},
+		// neighbourhoods wider than the grid reach cells several grids away,
+		// and each crossing of a twisted edge reflects them again
+		{name: "torus far", topo: Topology{Kind: Torus}, x: -9, y: 13, want: []int{3, 1}, ok: true},
+		{name: "klein twice", topo: Topology{Kind: Klein, TwistX: true}, x: 1, y: -5, want: []int{1, 3}, ok: true},
+		{name: "klein three times", topo: Topology{Kind: Klein, TwistX: true}, x: 1, y: -9, want: []int{2, 3}, ok: true},
+		{name: "klein y twice", topo: Topology{Kind: Klein, TwistY: true}, x: 9, y: 0, want: []int{1, 0}, ok: true},
+		{name: "cross twice", topo: Topology{Kind: Cross}, x: 9, y: 0, want: []int{1, 0}, ok: true},
+		{name: "cross far corner", topo: Topology{Kind: Cross}, x: -5, y: 10, want: []int{3, 2}, ok: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y, ok := tc.topo.wrap(tc.x, tc.y, 4, 4)
+			if ok != tc.ok {
+				t.Fatalf("expected ok %v, got %v", tc.ok, ok)
+			}
+			if diff := cmp.Diff(tc.want, []int{x, y}); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package life
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Volume represents a finite box of cells in three dimensions. Cells outside
+// of the box are always considered dead. Each cell is either dead or alive.
+//
+// The volume is made of layers stacked along the z axis, and each layer is a
+// grid of rows like a [Board]. The origin places the top left cell of the
+// first layer in space, and changes as the volume grows or shrinks when it is
+// stepped.
+type Volume struct {
+	width  int
+	height int
+	depth  int
+	x      int
+	y      int
+	z      int
+	cells  []uint8
+}
+
+// NewVolume creates a volume of the given dimensions with every cell dead.
+// It panics if any dimension is negative.
+func NewVolume(width, height, depth int) *Volume {
+	if width < 0 || height < 0 || depth < 0 {
+		panic(fmt.Sprintf("life: negative volume size %dx%dx%d", width, height, depth))
+	}
+	return &Volume{
+		width:  width,
+		height: height,
+		depth:  depth,
+		cells:  make([]uint8, width*height*depth),
+	}
+}
+
+// NewVolumeFromLayers creates a volume from layers of rows of text in the
+// format accepted by [NewBoardFromRows], where only dead and live cells are
+// allowed. Layers and rows smaller than the largest are padded with dead
+// cells.
+func NewVolumeFromLayers(layers [][]string) (*Volume, error) {
+	boards := make([]*Board, len(layers))
+	width, height := 0, 0
+	for z, rows := range layers {
+		b, err := NewBoardFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if b.MaxState() > 1 {
+			return nil, fmt.Errorf("%w: layer %d has state %d", ErrInvalidBoard, z, b.MaxState())
+		}
+		boards[z] = b
+		width, height = max(width, b.width), max(height, b.height)
+	}
+
+	v := NewVolume(width, height, len(layers))
+	for z, b := range boards {
+		for y := 0; y < b.height; y++ {
+			for x := 0; x < b.width; x++ {
+				v.cells[v.index(x, y, z)] = b.cells[y*b.width+x]
+			}
+		}
+	}
+	return v, nil
+}
+
+// Width of the volume in cells.
+func (v *Volume) Width() int {
+	return v.width
+}
+
+// Height of the volume in cells.
+func (v *Volume) Height() int {
+	return v.height
+}
+
+// Depth of the volume in cells, which is the number of layers.
+func (v *Volume) Depth() int {
+	return v.depth
+}
+
+// Origin returns the coordinates of the top left cell of the first layer of
+// the volume.
+func (v *Volume) Origin() (x, y, z int) {
+	return v.x, v.y, v.z
+}
+
+// SetOrigin sets the coordinates of the top left cell of the first layer of
+// the volume.
+func (v *Volume) SetOrigin(x, y, z int) {
+	v.x, v.y, v.z = x, y, z
+}
+
+// Alive reports whether the cell at x, y, z is alive. Cells outside of the
+// volume are always dead.
+func (v *Volume) Alive(x, y, z int) bool {
+	return v.contains(x, y, z) && v.cells[v.index(x, y, z)] != 0
+}
+
+// Set the cell at x, y, z to be alive or dead. Setting a cell outside of the
+// volume does nothing.
+func (v *Volume) Set(x, y, z int, alive bool) {
+	if !v.contains(x, y, z) {
+		return
+	}
+	var state uint8
+	if alive {
+		state = 1
+	}
+	v.cells[v.index(x, y, z)] = state
+}
+
+// Population is the number of live cells in the volume.
+func (v *Volume) Population() int {
+	n := 0
+	for _, c := range v.cells {
+		if c != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Clone returns a deep copy of the volume.
+func (v *Volume) Clone() *Volume {
+	c := NewVolume(v.width, v.height, v.depth)
+	c.x, c.y, c.z = v.x, v.y, v.z
+	copy(c.cells, v.cells)
+	return c
+}
+
+// Trim returns a copy of the volume cropped to the smallest box that
+// contains every live cell. The origin is adjusted so live cells keep their
+// coordinates. An empty volume is trimmed to zero size at the origin.
+func (v *Volume) Trim() *Volume {
+	minX, minY, minZ := v.width, v.height, v.depth
+	maxX, maxY, maxZ := -1, -1, -1
+	for z := 0; z < v.depth; z++ {
+		for y := 0; y < v.height; y++ {
+			for x := 0; x < v.width; x++ {
+				if v.cells[v.index(x, y, z)] != 0 {
+					minX, maxX = min(minX, x), max(maxX, x)
+					minY, maxY = min(minY, y), max(maxY, y)
+					minZ, maxZ = min(minZ, z), max(maxZ, z)
+				}
+			}
+		}
+	}
+	if maxX < 0 {
+		return NewVolume(0, 0, 0)
+	}
+	return v.crop(minX, minY, minZ, maxX-minX+1, maxY-minY+1, maxZ-minZ+1)
+}
+
+// Equal reports whether both volumes have the same origin, dimensions, and
+// cells.
+func (v *Volume) Equal(o *Volume) bool {
+	if v.width != o.width || v.height != o.height || v.depth != o.depth ||
+		v.x != o.x || v.y != o.y || v.z != o.z {
+		return false
+	}
+	for i := range v.cells {
+		if v.cells[i] != o.cells[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Layer returns a copy of the layer at index z of the volume as a board.
+// The board has the origin of the volume in the x and y axes. A layer
+// outside of the volume is an empty board of the same size.
+func (v *Volume) Layer(z int) *Board {
+	b := NewBoard(v.width, v.height)
+	b.x, b.y = v.x, v.y
+	if z >= 0 && z < v.depth {
+		copy(b.cells, v.cells[v.index(0, 0, z):v.index(0, 0, z+1)])
+	}
+	return b
+}
+
+// Layers returns every layer of the volume as rows of text in the same
+// format accepted by [NewVolumeFromLayers].
+func (v *Volume) Layers() [][]string {
+	layers := make([][]string, v.depth)
+	for z := range layers {
+		layers[z] = v.Layer(z).Rows()
+	}
+	return layers
+}
+
+// MarshalBinary implements the [encoding.BinaryMarshaler] interface. The
+// encoding is the width, height, and depth as 32-bit big endian integers,
+// the origin as 64-bit big endian integers, and then a byte per cell with
+// each layer in row major order.
+func (v *Volume) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 36, 36+len(v.cells))
+	binary.BigEndian.PutUint32(data[0:], uint32(v.width))
+	binary.BigEndian.PutUint32(data[4:], uint32(v.height))
+	binary.BigEndian.PutUint32(data[8:], uint32(v.depth))
+	binary.BigEndian.PutUint64(data[12:], uint64(v.x))
+	binary.BigEndian.PutUint64(data[20:], uint64(v.y))
+	binary.BigEndian.PutUint64(data[28:], uint64(v.z))
+	return append(data, v.cells...), nil
+}
+
+// UnmarshalBinary implements the [encoding.BinaryUnmarshaler] interface.
+func (v *Volume) UnmarshalBinary(data []byte) error {
+	if len(data) < 36 {
+		return fmt.Errorf("%w: short header", ErrInvalidBoard)
+	}
+	width := int(binary.BigEndian.Uint32(data[0:]))
+	height := int(binary.BigEndian.Uint32(data[4:]))
+	depth := int(binary.BigEndian.Uint32(data[8:]))
+	if uint64(width)*uint64(height)*uint64(depth) != uint64(len(data)-36) {
+		return fmt.Errorf("%w: expected %d cells", ErrInvalidBoard, uint64(width)*uint64(height)*uint64(depth))
+	}
+	for i, c := range data[36:] {
+		if c > 1 {
+			return fmt.Errorf("%w: cell %d has state %d", ErrInvalidBoard, i, c)
+		}
+	}
+	v.width, v.height, v.depth = width, height, depth
+	v.x = int(int64(binary.BigEndian.Uint64(data[12:])))
+	v.y = int(int64(binary.BigEndian.Uint64(data[20:])))
+	v.z = int(int64(binary.BigEndian.Uint64(data[28:])))
+	v.cells = append(make([]uint8, 0, len(data)-36), data[36:]...)
+	return nil
+}
+
+// crop returns a copy of the width by height by depth box of the volume with
+// its top left corner of the first layer at x, y, z. The box may extend
+// beyond the volume, in which case those cells are dead.
+func (v *Volume) crop(x, y, z, width, height, depth int) *Volume {
+	c := NewVolume(width, height, depth)
+	c.x, c.y, c.z = v.x+x, v.y+y, v.z+z
+	for cz := 0; cz < depth; cz++ {
+		for cy := 0; cy < height; cy++ {
+			for cx := 0; cx < width; cx++ {
+				if v.Alive(x+cx, y+cy, z+cz) {
+					c.cells[c.index(cx, cy, cz)] = 1
+				}
+			}
+		}
+	}
+	return c
+}
+
+// contains reports whether x, y, z is within the bounds of the volume.
+func (v *Volume) contains(x, y, z int) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < v.width && y < v.height && z < v.depth
+}
+
+// index returns the index of the cell at x, y, z.
+func (v *Volume) index(x, y, z int) int {
+	return (z*v.height+y)*v.width + x
+}
@@ -0,0 +1,103 @@
+package life
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewVolumeFromLayers(t *testing.T) {
+	v, err := NewVolumeFromLayers([][]string{{"O.", ".O"}, {"OOO"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Width() != 3 || v.Height() != 2 || v.Depth() != 2 {
+		t.Errorf("expected 3x2x2, got %dx%dx%d", v.Width(), v.Height(), v.Depth())
+	}
+	want := [][]string{{"O..", ".O."}, {"OOO", "..."}}
+	if diff := cmp.Diff(want, v.Layers()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(5, v.Population()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestNewVolumeFromLayersInvalid(t *testing.T) {
+	cases := map[string][][]string{
+		"character": {{"x"}},
+		"state":     {{"OB"}},
+	}
+
+	for name, layers := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewVolumeFromLayers(layers); !errors.Is(err, ErrInvalidBoard) {
+				t.Errorf("expected %v, got %v", ErrInvalidBoard, err)
+			}
+		})
+	}
+}
+
+func TestVolumeTrim(t *testing.T) {
+	v, _ := NewVolumeFromLayers([][]string{{"...", "..."}, {"...", ".O."}, {"...", "..O"}})
+	v.SetOrigin(10, 20, 30)
+	got := v.Trim()
+	if x, y, z := got.Origin(); x != 11 || y != 21 || z != 31 {
+		t.Errorf("expected origin 11, 21, 31, got %d, %d, %d", x, y, z)
+	}
+	want := [][]string{{"O."}, {".O"}}
+	if diff := cmp.Diff(want, got.Layers()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	empty := NewVolume(3, 3, 3).Trim()
+	if empty.Width() != 0 || empty.Height() != 0 || empty.Depth() != 0 {
+		t.Errorf("expected empty volume, got %dx%dx%d", empty.Width(), empty.Height(), empty.Depth())
+	}
+}
+
+func TestVolumeLayer(t *testing.T) {
+	v, _ := NewVolumeFromLayers([][]string{{"O."}, {".O"}})
+	v.SetOrigin(-1, 2, 5)
+	got := v.Layer(1)
+	if diff := cmp.Diff([]string{".O"}, got.Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if x, y := got.Origin(); x != -1 || y != 2 {
+		t.Errorf("expected origin -1, 2, got %d, %d", x, y)
+	}
+	if diff := cmp.Diff([]string{".."}, v.Layer(2).Rows()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestVolumeBinary(t *testing.T) {
+	v, _ := NewVolumeFromLayers([][]string{{"O.", ".O"}, {"OO", ".."}, {"..", "O."}})
+	v.SetOrigin(-3, 4, -5)
+	data, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := new(Volume)
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v.Equal(got) {
+		t.Errorf("expected %v, got %v", v.Layers(), got.Layers())
+	}
+
+	cases := map[string][]byte{
+		"short":  data[:10],
+		"cells":  data[:len(data)-1],
+		"states": append(append([]byte{}, data[:len(data)-1]...), 2),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := new(Volume).UnmarshalBinary(data); !errors.Is(err, ErrInvalidBoard) {
+				t.Errorf("expected %v, got %v", ErrInvalidBoard, err)
+			}
+		})
+	}
+}
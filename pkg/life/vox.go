@@ -0,0 +1,56 @@
+package life
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxVoxSize is the maximum width, height, or depth of a MagicaVoxel model.
+const maxVoxSize = 256
+
+// WriteVox writes the live cells of the volume to w as a MagicaVoxel .vox
+// model, which most voxel editors and 3D tools can import. Each live cell is
+// a voxel with the first color of the default palette at its position
+// relative to the top left cell of the first layer. Layers are stacked along
+// the z axis, which is up in MagicaVoxel. Every dimension of the volume must
+// be at most 256 cells.
+func (v *Volume) WriteVox(w io.Writer) error {
+	if v.width > maxVoxSize || v.height > maxVoxSize || v.depth > maxVoxSize {
+		return fmt.Errorf("%w: %dx%dx%d exceeds %dx%dx%d", ErrTooLarge,
+			v.width, v.height, v.depth, maxVoxSize, maxVoxSize, maxVoxSize)
+	}
+
+	size := binary.LittleEndian.AppendUint32(nil, uint32(max(v.width, 1)))
+	size = binary.LittleEndian.AppendUint32(size, uint32(max(v.height, 1)))
+	size = binary.LittleEndian.AppendUint32(size, uint32(max(v.depth, 1)))
+
+	population := v.Population()
+	xyzi := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+4*population), uint32(population))
+	for z := 0; z < v.depth; z++ {
+		for y := 0; y < v.height; y++ {
+			for x := 0; x < v.width; x++ {
+				if v.cells[v.index(x, y, z)] != 0 {
+					xyzi = append(xyzi, byte(x), byte(y), byte(z), 1)
+				}
+			}
+		}
+	}
+
+	children := appendVoxChunk(nil, "SIZE", size, nil)
+	children = appendVoxChunk(children, "XYZI", xyzi, nil)
+	data := binary.LittleEndian.AppendUint32([]byte("VOX "), 150)
+	data = appendVoxChunk(data, "MAIN", nil, children)
+	_, err := w.Write(data)
+	return err
+}
+
+// appendVoxChunk appends a .vox chunk with the given identifier, content,
+// and children to b.
+func appendVoxChunk(b []byte, id string, content, children []byte) []byte {
+	b = append(b, id...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(content)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(children)))
+	b = append(b, content...)
+	return append(b, children...)
+}
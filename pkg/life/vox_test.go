@@ -0,0 +1,31 @@
+package life
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteVox(t *testing.T) {
+	v, _ := NewVolumeFromLayers([][]string{{"O.", ".."}, {"..", ".O"}})
+	var buf bytes.Buffer
+	if err := v.WriteVox(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{
+		'V', 'O', 'X', ' ', 150, 0, 0, 0,
+		'M', 'A', 'I', 'N', 0, 0, 0, 0, 48, 0, 0, 0,
+		'S', 'I', 'Z', 'E', 12, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0, 2, 0, 0, 0,
+		'X', 'Y', 'Z', 'I', 12, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1,
+	}
+	if diff := cmp.Diff(want, buf.Bytes()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if err := NewVolume(257, 1, 1).WriteVox(&buf); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"io"
+
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// gifHeaderSize is the length of the signature and logical screen
+// descriptor that start a GIF image.
+const gifHeaderSize = 13
+
+const (
+	// MaxFrames is the largest number of frames of an animation.
+	MaxFrames = 1000
+	// MaxFramePixels is the largest number of pixels in a frame of an
+	// animation.
+	MaxFramePixels = 1 << 20
+	// MaxAnimationPixels is the largest number of pixels in all the frames
+	// of an animation.
+	MaxAnimationPixels = 1 << 26
+)
+
+// ErrTooLarge when an animation has too many frames or pixels.
+var ErrTooLarge = errors.New("animation too large")
+
+// GIF writes boards as the frames of an animated GIF image. Each frame is
+// encoded with [gif.EncodeAll] as it is given and written straight away,
+// so only one frame is held in memory however long the animation is. Every
+// frame must be drawn the same size, so boards that move or grow are drawn
+// with a viewport.
+type GIF struct {
+	w         io.Writer
+	r         *Renderer
+	delay     int
+	loopCount int
+	bounds    image.Rectangle
+	frames    int
+	buf       bytes.Buffer
+}
+
+// NewGIF creates an animated GIF image written to w and drawn with the
+// renderer. Each frame is shown for delay hundredths of a second, and the
+// loop count controls how often the animation repeats as in [gif.GIF]: 0
+// repeats forever, -1 shows each frame once, and n shows them n+1 times.
+func (r *Renderer) NewGIF(w io.Writer, delay, loopCount int) *GIF {
+	return &GIF{w: w, r: r, delay: delay, loopCount: loopCount}
+}
+
+// CheckAnimation fails with [ErrTooLarge] when an animation of the number
+// of frames, each of the bounds, would exceed the limits on frames and
+// pixels, so it can be rejected before any frame is written.
+func CheckAnimation(frames int, bounds image.Rectangle) error {
+	pixels := bounds.Dx() * bounds.Dy()
+	if frames > MaxFrames || pixels > MaxFramePixels || frames*pixels > MaxAnimationPixels {
+		return fmt.Errorf("%w: %d frames of %dx%d pixels must be at most %d frames of %d pixels each and %d in total",
+			ErrTooLarge, frames, bounds.Dx(), bounds.Dy(), MaxFrames, MaxFramePixels, MaxAnimationPixels)
+	}
+	return nil
+}
+
+// Frame draws the board as the next frame of the animation and writes it.
+// The first frame also writes the header and colors of the image. It fails
+// with [ErrTooLarge] before drawing a frame that would exceed the limits of
+// [CheckAnimation].
+func (g *GIF) Frame(b *life.Board) error {
+	if err := CheckAnimation(g.frames+1, g.r.Bounds(b)); err != nil {
+		return err
+	}
+	img := g.r.Image(b)
+	if g.frames == 0 {
+		g.bounds = img.Bounds()
+	} else if img.Bounds() != g.bounds {
+		return fmt.Errorf("frame of %dx%d pixels must match the first frame of %dx%d pixels",
+			img.Rect.Dx(), img.Rect.Dy(), g.bounds.Dx(), g.bounds.Dy())
+	}
+
+	// a single frame image is the header, the global color table, and the
+	// frame followed by the trailer, which is split out of it for each frame
+	g.buf.Reset()
+	err := gif.EncodeAll(&g.buf, &gif.GIF{
+		Image: []*image.Paletted{img},
+		Delay: []int{g.delay},
+		Config: image.Config{
+			ColorModel: img.Palette,
+			Width:      g.bounds.Dx(),
+			Height:     g.bounds.Dy(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	data := g.buf.Bytes()
+	start := gifHeaderSize + 3<<(data[10]&0x07+1)
+	frame := data[start : len(data)-1]
+
+	if g.frames == 0 {
+		if _, err := g.w.Write(data[:start]); err != nil {
+			return err
+		}
+		if err := g.writeLoop(); err != nil {
+			return err
+		}
+	}
+	g.frames++
+	_, err = g.w.Write(frame)
+	return err
+}
+
+// writeLoop writes the application extension that repeats the animation,
+// unless every frame is shown once.
+func (g *GIF) writeLoop() error {
+	if g.loopCount < 0 {
+		return nil
+	}
+	ext := []byte{0x21, 0xff, 0x0b}
+	ext = append(ext, "NETSCAPE2.0"...)
+	ext = append(ext, 0x03, 0x01, byte(g.loopCount), byte(g.loopCount>>8), 0x00)
+	_, err := g.w.Write(ext)
+	return err
+}
+
+// Close writes the trailer that ends the image. It does not close the
+// underlying writer. An animation must have at least one frame.
+func (g *GIF) Close() error {
+	if g.frames == 0 {
+		return errors.New("animation has no frames")
+	}
+	_, err := g.w.Write([]byte{0x3b})
+	return err
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+func TestGIF(t *testing.T) {
+	glider, _ := life.NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	boards := []*life.Board{glider}
+	for i := 0; i < 3; i++ {
+		boards = append(boards, life.Step(boards[i], life.Conway))
+	}
+
+	cases := []struct {
+		name      string
+		loopCount int
+	}{
+		{name: "forever", loopCount: 0},
+		{name: "once", loopCount: -1},
+		{name: "twice", loopCount: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New(WithCellSize(2), WithViewport(0, 0, 4, 4))
+			var buf bytes.Buffer
+			g := r.NewGIF(&buf, 5, tc.loopCount)
+			for _, b := range boards {
+				if err := g.Frame(b); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := g.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff([]int{5, 5, 5, 5}, got.Delay); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if got.LoopCount != tc.loopCount {
+				t.Errorf("expected loop count %d, got %d", tc.loopCount, got.LoopCount)
+			}
+			for i, b := range boards {
+				if diff := cmp.Diff(pixels(r.Image(b)), pixels(got.Image[i])); diff != "" {
+					t.Errorf("frame %d mismatch (-want, +got):\n%s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestGIFInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	g := New().NewGIF(&buf, 10, 0)
+	if err := g.Close(); err == nil {
+		t.Errorf("expected an error closing an animation without frames")
+	}
+
+	small, _ := life.NewBoardFromRows([]string{"O"})
+	large, _ := life.NewBoardFromRows([]string{"OO"})
+	if err := g.Frame(small); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Frame(large); err == nil {
+		t.Errorf("expected an error drawing frames of different sizes")
+	}
+}
+
+func TestGIFTooLarge(t *testing.T) {
+	cases := []struct {
+		name   string
+		frames int
+		bounds image.Rectangle
+	}{
+		{name: "frames", frames: MaxFrames + 1, bounds: image.Rect(0, 0, 1, 1)},
+		{name: "frame pixels", frames: 1, bounds: image.Rect(0, 0, 1025, 1024)},
+		{name: "total pixels", frames: 65, bounds: image.Rect(0, 0, 1024, 1024)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := CheckAnimation(tc.frames, tc.bounds); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("expected %v, got %v", ErrTooLarge, err)
+			}
+		})
+	}
+	if err := CheckAnimation(64, image.Rect(0, 0, 1024, 1024)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// frames are checked before they are drawn
+	var buf bytes.Buffer
+	g := New(WithCellSize(1025)).NewGIF(&buf, 10, 0)
+	cell, _ := life.NewBoardFromRows([]string{"O"})
+	if err := g.Frame(cell); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected %v, got %v", ErrTooLarge, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written, got %d bytes", buf.Len())
+	}
+}
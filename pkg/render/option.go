@@ -0,0 +1,63 @@
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/rydelll/conway/pkg/life"
+)
+
+// Option configures a renderer by overriding a default setting.
+type Option func(*Renderer)
+
+// WithCellSize sets the width and height of each cell in pixels. A zero or
+// negative size uses the default of 8 pixels.
+func WithCellSize(pixels int) Option {
+	return func(r *Renderer) {
+		r.cellSize = pixels
+	}
+}
+
+// WithNeighborhood sets the neighbourhood of the rule the board is stepped
+// with. Boards on the [life.Hexagonal] neighbourhood are drawn as a
+// hexagonal grid.
+func WithNeighborhood(n life.Neighborhood) Option {
+	return func(r *Renderer) {
+		r.hexagonal = n == life.Hexagonal
+	}
+}
+
+// WithColors sets the colors of dead and live cells. The default is white
+// for dead cells and black for live cells.
+func WithColors(dead, alive color.Color) Option {
+	return func(r *Renderer) {
+		r.palette[0], r.palette[1] = dead, alive
+	}
+}
+
+// WithAgeColors sets the colors of the dying cells of Generations rules,
+// which fade from the first color for cells that have just died to the last
+// color for the oldest. The default fades from dark to light gray.
+func WithAgeColors(first, last color.Color) Option {
+	return func(r *Renderer) {
+		fade(r.palette, first, last)
+	}
+}
+
+// WithGrid draws lines of the color a pixel wide between cells and around
+// the edge of the board. Cells smaller than 3 pixels are drawn without
+// lines. The default has no lines.
+func WithGrid(c color.Color) Option {
+	return func(r *Renderer) {
+		r.grid = c
+	}
+}
+
+// WithViewport draws the width by height rectangle of the plane with its top
+// left cell at x, y, wherever the board is. The default draws the whole
+// board.
+func WithViewport(x, y, width, height int) Option {
+	return func(r *Renderer) {
+		r.viewport = image.Rect(x, y, x+width, y+height)
+	}
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"image/color"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+func TestWithCellSize(t *testing.T) {
+	cases := []struct {
+		pixels int
+		want   int
+	}{
+		{pixels: 1, want: 1},
+		{pixels: 16, want: 16},
+		{pixels: 0, want: defaultCellSize},
+		{pixels: -1, want: defaultCellSize},
+	}
+
+	for _, tc := range cases {
+		t.Run(strconv.Itoa(tc.pixels), func(t *testing.T) {
+			r := New(WithCellSize(tc.pixels))
+			if diff := cmp.Diff(tc.want, r.cellSize); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithNeighborhood(t *testing.T) {
+	cases := []struct {
+		neighborhood life.Neighborhood
+		want         bool
+	}{
+		{neighborhood: life.Moore, want: false},
+		{neighborhood: life.VonNeumann, want: false},
+		{neighborhood: life.Hexagonal, want: true},
+	}
+
+	for _, tc := range cases {
+		r := New(WithNeighborhood(tc.neighborhood))
+		if diff := cmp.Diff(tc.want, r.hexagonal); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+	}
+}
+
+func TestWithColors(t *testing.T) {
+	r := New(WithColors(color.Black, color.White), WithAgeColors(color.White, color.Gray{Y: 0x80}))
+	want := []color.Color{color.Black, color.White, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}}
+	if diff := cmp.Diff(want, []color.Color(r.palette[:3])); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(color.Color(color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}), r.palette[life.MaxStates-1]); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWithGrid(t *testing.T) {
+	cases := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{name: "none", opts: nil, want: life.MaxStates},
+		{name: "grid", opts: []Option{WithGrid(color.Black)}, want: life.MaxStates + 1},
+		{name: "small cells", opts: []Option{WithGrid(color.Black), WithCellSize(2)}, want: life.MaxStates},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New(tc.opts...)
+			if diff := cmp.Diff(tc.want, len(r.palette)); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package render draws boards of cells as PNG, SVG, and animated GIF images.
+package render
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rydelll/conway/pkg/life"
+)
+
+const (
+	// defaultCellSize is the width and height of a cell in pixels.
+	defaultCellSize = 8
+	// MaxCellSize is the largest width and height of a cell in pixels that
+	// callers drawing untrusted requests should accept.
+	MaxCellSize = 64
+)
+
+// ErrInvalidColor when a color cannot be parsed.
+var ErrInvalidColor = errors.New("invalid color")
+
+// Renderer draws boards as images. Each cell is a square of pixels colored
+// by its state. Boards of rules on the [life.Hexagonal] neighbourhood are
+// drawn as a hexagonal grid, where each row is offset by half a cell to the
+// right of the row below it so that the six neighbours of a cell surround
+// it.
+type Renderer struct {
+	cellSize  int
+	hexagonal bool
+	palette   color.Palette
+	grid      color.Color
+	viewport  image.Rectangle
+}
+
+// New creates a renderer with optional configuration.
+func New(opts ...Option) *Renderer {
+	r := &Renderer{
+		cellSize: defaultCellSize,
+		palette:  defaultPalette(),
+	}
+
+	// apply optional configuration
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.cellSize <= 0 {
+		r.cellSize = defaultCellSize
+	}
+	if r.cellSize < 3 {
+		r.grid = nil
+	}
+	if r.grid != nil {
+		r.palette = append(r.palette, r.grid)
+	}
+	return r
+}
+
+// defaultPalette returns a color for every cell state. Dead cells are white
+// and live cells are black. Dying cells fade from dark to light gray.
+func defaultPalette() color.Palette {
+	p := make(color.Palette, life.MaxStates)
+	p[0] = color.White
+	p[1] = color.Black
+	fade(p, color.Gray{Y: 64}, color.Gray{Y: 224})
+	return p
+}
+
+// fade sets the colors of the dying states of the palette, from 2 to the
+// last state, to fade evenly from the first color to the last.
+func fade(p color.Palette, first, last color.Color) {
+	r0, g0, b0, a0 := first.RGBA()
+	r1, g1, b1, a1 := last.RGBA()
+	steps := uint32(life.MaxStates - 3)
+	mix := func(c0, c1, i uint32) uint8 {
+		return uint8((c0*(steps-i) + c1*i) / steps >> 8)
+	}
+	for state := 2; state < life.MaxStates; state++ {
+		i := uint32(state - 2)
+		p[state] = color.RGBA{R: mix(r0, r1, i), G: mix(g0, g1, i), B: mix(b0, b1, i), A: mix(a0, a1, i)}
+	}
+}
+
+// region returns the rectangle of the plane drawn for the board, which is
+// the viewport if there is one and the board otherwise. An empty board is
+// drawn as a single dead cell.
+func (r *Renderer) region(b *life.Board) image.Rectangle {
+	if !r.viewport.Empty() {
+		return r.viewport
+	}
+	x, y := b.Origin()
+	return image.Rect(x, y, x+max(b.Width(), 1), y+max(b.Height(), 1))
+}
+
+// Bounds returns the size in pixels of the image of the board.
+func (r *Renderer) Bounds(b *life.Board) image.Rectangle {
+	region := r.region(b)
+	width, height := region.Dx()*r.cellSize+r.offset(0, region.Dy()), region.Dy()*r.cellSize
+	if r.grid != nil {
+		width, height = width+1, height+1
+	}
+	return image.Rect(0, 0, width, height)
+}
+
+// offset returns the number of pixels row y of a board with the given
+// height is shifted to the right.
+func (r *Renderer) offset(y, height int) int {
+	if !r.hexagonal {
+		return 0
+	}
+	return (height - 1 - y) * r.cellSize / 2
+}
+
+// cell returns the rectangle of pixels of the cell at x, y of the region.
+// With grid lines the rectangle is inside the lines around the cell.
+func (r *Renderer) cell(region image.Rectangle, x, y int) image.Rectangle {
+	px, py := x*r.cellSize+r.offset(y, region.Dy()), y*r.cellSize
+	if r.grid != nil {
+		return image.Rect(px+1, py+1, px+r.cellSize, py+r.cellSize)
+	}
+	return image.Rect(px, py, px+r.cellSize, py+r.cellSize)
+}
+
+// row returns the rectangle of pixels of row y of the region, including the
+// grid lines around it.
+func (r *Renderer) row(region image.Rectangle, y int) image.Rectangle {
+	px, py := r.offset(y, region.Dy()), y*r.cellSize
+	return image.Rect(px, py, px+region.Dx()*r.cellSize+1, py+r.cellSize+1)
+}
+
+// cells calls fn with the rectangle of pixels and the state of every cell of
+// the region that is drawn over the background, in row major order. Dead
+// cells are only drawn when there are grid lines, and each row starts with
+// the rectangle of its lines in the grid color, given as state -1.
+func (r *Renderer) cells(b *life.Board, fn func(rect image.Rectangle, state int)) {
+	region := r.region(b)
+	ox, oy := b.Origin()
+	for y := 0; y < region.Dy(); y++ {
+		if r.grid != nil {
+			fn(r.row(region, y), -1)
+		}
+		for x := 0; x < region.Dx(); x++ {
+			state := b.State(region.Min.X+x-ox, region.Min.Y+y-oy)
+			if state != 0 || r.grid != nil {
+				fn(r.cell(region, x, y), int(state))
+			}
+		}
+	}
+}
+
+// Image draws the board as an image with a color for every cell state.
+func (r *Renderer) Image(b *life.Board) *image.Paletted {
+	img := image.NewPaletted(r.Bounds(b), r.palette)
+	grid := uint8(len(r.palette) - 1)
+	r.cells(b, func(rect image.Rectangle, state int) {
+		index := grid
+		if state >= 0 {
+			index = uint8(state)
+		}
+		for py := rect.Min.Y; py < rect.Max.Y; py++ {
+			for px := rect.Min.X; px < rect.Max.X; px++ {
+				img.SetColorIndex(px, py, index)
+			}
+		}
+	})
+	return img
+}
+
+// PNG writes the board to w as a PNG image.
+func (r *Renderer) PNG(w io.Writer, b *life.Board) error {
+	return png.Encode(w, r.Image(b))
+}
+
+// SVG writes the board to w as an SVG image with a rectangle for every cell
+// that is not dead, or for every cell and row of grid lines when there are
+// lines.
+func (r *Renderer) SVG(w io.Writer, b *life.Board) error {
+	bounds := r.Bounds(b)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		bounds.Dx(), bounds.Dy())
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", bounds.Dx(), bounds.Dy(), hex(r.palette[0]))
+	r.cells(b, func(rect image.Rectangle, state int) {
+		fill := r.grid
+		if state >= 0 {
+			fill = r.palette[state]
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), hex(fill))
+	})
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// ParseColor parses a color in hexadecimal CSS notation, such as "#ff0000"
+// or "#f00". The '#' may be omitted, as it must be escaped in URLs.
+func ParseColor(s string) (color.Color, error) {
+	digits := strings.TrimPrefix(s, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	rgb, err := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 6 || err != nil {
+		return nil, fmt.Errorf("%w: %q must be a hexadecimal color such as #ff0000", ErrInvalidColor, s)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// hex returns the color in hexadecimal CSS notation, such as "#ff0000".
+func hex(c color.Color) string {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	return fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B)
+}
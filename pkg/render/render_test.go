@@ -0,0 +1,209 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rydelll/conway/pkg/life"
+)
+
+func TestImage(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  []Option
+		input []string
+		want  []string
+	}{
+		{
+			name:  "square",
+			opts:  []Option{WithCellSize(2)},
+			input: []string{"O.", ".B"},
+			want:  []string{"11..", "11..", "..22", "..22"},
+		},
+		{
+			name:  "hexagonal",
+			opts:  []Option{WithCellSize(2), WithNeighborhood(life.Hexagonal)},
+			input: []string{"O.", ".O"},
+			want:  []string{".11..", ".11..", "..11.", "..11."},
+		},
+		{
+			name:  "hexagonal rows",
+			opts:  []Option{WithCellSize(2), WithNeighborhood(life.Hexagonal)},
+			input: []string{"O", "O", "O"},
+			want:  []string{"..11", "..11", ".11.", ".11.", "11..", "11.."},
+		},
+		{
+			name:  "von neumann",
+			opts:  []Option{WithCellSize(1), WithNeighborhood(life.VonNeumann)},
+			input: []string{"O.", ".O"},
+			want:  []string{"1.", ".1"},
+		},
+		{
+			name:  "empty",
+			opts:  []Option{WithCellSize(1)},
+			input: nil,
+			want:  []string{"."},
+		},
+		{
+			name:  "grid",
+			opts:  []Option{WithCellSize(3), WithGrid(color.Black)},
+			input: []string{"O.", ".B"},
+			want:  []string{"#######", "#11#..#", "#11#..#", "#######", "#..#22#", "#..#22#", "#######"},
+		},
+		{
+			name:  "hexagonal grid",
+			opts:  []Option{WithCellSize(4), WithGrid(color.Black), WithNeighborhood(life.Hexagonal)},
+			input: []string{"O", "O"},
+			want:  []string{"..#####", "..#111#", "..#111#", "..#111#", "#######", "#111#..", "#111#..", "#111#..", "#####.."},
+		},
+		{
+			name:  "small grid",
+			opts:  []Option{WithCellSize(2), WithGrid(color.Black)},
+			input: []string{"O."},
+			want:  []string{"11..", "11.."},
+		},
+		{
+			name:  "viewport",
+			opts:  []Option{WithCellSize(1), WithViewport(-1, 1, 4, 2)},
+			input: []string{"O.", ".O", "O."},
+			want:  []string{"..1.", ".1.."},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := life.NewBoardFromRows(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, pixels(New(tc.opts...).Image(b))); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	b, _ := life.NewBoardFromRows([]string{".O.", "..O", "OOO"})
+	r := New(WithCellSize(3))
+
+	var buf bytes.Buffer
+	if err := r.PNG(&buf, b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(image.Rect(0, 0, 9, 9), img.Bounds()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	want := r.Image(b)
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			wr, wg, wb, _ := want.At(x, y).RGBA()
+			gr, gg, gb, _ := img.At(x, y).RGBA()
+			if wr != gr || wg != gg || wb != gb {
+				t.Fatalf("pixel %d, %d mismatch", x, y)
+			}
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	b, _ := life.NewBoardFromRows([]string{"O.", "OB"})
+	cases := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "square",
+			opts: []Option{WithCellSize(4)},
+			want: `<svg xmlns="http://www.w3.org/2000/svg" width="8" height="8" viewBox="0 0 8 8">
+<rect width="8" height="8" fill="#ffffff"/>
+<rect x="0" y="0" width="4" height="4" fill="#000000"/>
+<rect x="0" y="4" width="4" height="4" fill="#000000"/>
+<rect x="4" y="4" width="4" height="4" fill="#404040"/>
+</svg>
+`,
+		},
+		{
+			name: "hexagonal",
+			opts: []Option{WithCellSize(4), WithNeighborhood(life.Hexagonal)},
+			want: `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="8" viewBox="0 0 10 8">
+<rect width="10" height="8" fill="#ffffff"/>
+<rect x="2" y="0" width="4" height="4" fill="#000000"/>
+<rect x="0" y="4" width="4" height="4" fill="#000000"/>
+<rect x="4" y="4" width="4" height="4" fill="#404040"/>
+</svg>
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := New(tc.opts...).SVG(&buf, b); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// pixels returns the color index of every pixel of the image as rows of
+// text, where a '.' is a dead cell, a '#' is a grid line, and a digit is the
+// state of the cell.
+func pixels(img *image.Paletted) []string {
+	var rows []string
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		var row []byte
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if i := img.ColorIndexAt(x, y); i == 0 {
+				row = append(row, '.')
+			} else if i == life.MaxStates {
+				row = append(row, '#')
+			} else {
+				row = append(row, '0'+i)
+			}
+		}
+		rows = append(rows, string(row))
+	}
+	return rows
+}
+
+func TestParseColor(t *testing.T) {
+	cases := []struct {
+		input string
+		want  color.Color
+		err   error
+	}{
+		{input: "#ff8000", want: color.RGBA{R: 0xff, G: 0x80, A: 0xff}},
+		{input: "FF8000", want: color.RGBA{R: 0xff, G: 0x80, A: 0xff}},
+		{input: "#f80", want: color.RGBA{R: 0xff, G: 0x88, A: 0xff}},
+		{input: "", err: ErrInvalidColor},
+		{input: "#ff80", err: ErrInvalidColor},
+		{input: "#gg8000", err: ErrInvalidColor},
+		{input: "red", err: ErrInvalidColor},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseColor(tc.input)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 )
@@ -50,11 +51,15 @@ func New(logger *slog.Logger, handler http.Handler, port int, opts ...Option) *S
 
 // ListenAndServe starts a server and blocks until the context is cancelled.
 // When the context is cancelled, the server is gracefully stopped with the
-// configured timeout.
+// configured timeout. Request contexts are derived from the context, so
+// in-flight requests can stop their work promptly once it is cancelled.
 //
 // Once it has been stopped it is NOT safe for reuse.
 func (s *Server) ListenAndServe(ctx context.Context) error {
 	shutdownErrorChan := make(chan error, 1)
+	s.server.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
 
 	go func() {
 		<-ctx.Done()